@@ -0,0 +1,123 @@
+package debounce
+
+import "time"
+
+const (
+	defaultAdaptFactor    = 2
+	defaultAdaptSmoothing = 0.3
+)
+
+// NewAdaptive returns a debounced function like New, but with a wait time
+// which adapts to how often it is called, so it stays responsive to slow
+// calls, while coalescing fast streams of calls.
+//
+// The wait time is derived from the moving average of the time between calls:
+//
+//	avg  = gap                                  for the first gap
+//	avg  = smoothing*gap + (1-smoothing)*avg    for later gaps
+//	wait = clamp(factor*avg, minWait, maxWait)
+//
+// where gap is the time since the previous call, capped at maxWait, so a long
+// pause does not skew the average for longer than necessary. The wait time is
+// recomputed on each call, and is minWait until the second call. The factor
+// and smoothing default to 2 and 0.3, and can be set with WithAdaptiveWait.
+//
+// Optional behavior can be configured by passing one or more AdaptiveOption
+// values, which include all Option values.
+//
+// Unlike the maximum wait time of NewWithMaxWait, maxWait only bounds the wait
+// time, not the total time an invocation can be delayed by a steady stream of
+// calls. Use WithMaxWait for that.
+//
+// The returned cancel function can be used to cancel any pending invocation of
+// f, but is not required to be called, so can be ignored if not needed.
+//
+// Both debounced and cancel functions are safe for concurrent use in
+// goroutines, and can both be called multiple times.
+func NewAdaptive(
+	minWait, maxWait time.Duration,
+	f func(),
+	opts ...AdaptiveOption,
+) (debounced func(), cancel func()) {
+	o := adaptiveOptions{
+		factor:    defaultAdaptFactor,
+		smoothing: defaultAdaptSmoothing,
+	}
+	for _, opt := range opts {
+		opt.applyAdaptive(&o)
+	}
+
+	d := NewDebouncer(minWait, f, o.opts...)
+	d.adaptive = true
+	d.adaptMax = maxWait
+	d.adaptFactor = o.factor
+	d.adaptSmoothing = o.smoothing
+
+	return d.Debounce, d.cancelFunc()
+}
+
+// AdaptiveOption configures optional behavior of a debounced function returned
+// by NewAdaptive. Any Option is an AdaptiveOption, and so is WithAdaptiveWait.
+type AdaptiveOption interface {
+	applyAdaptive(o *adaptiveOptions)
+}
+
+type adaptiveOptions struct {
+	opts      []Option
+	factor    float64
+	smoothing float64
+}
+
+type adaptiveOption func(o *adaptiveOptions)
+
+func (f adaptiveOption) applyAdaptive(o *adaptiveOptions) {
+	f(o)
+}
+
+func (f Option) applyAdaptive(o *adaptiveOptions) {
+	o.opts = append(o.opts, f)
+}
+
+// WithAdaptiveWait sets the parameters NewAdaptive uses to derive its wait
+// time from the time between calls. The wait time is factor times the moving
+// average of the time between calls, where each new time between calls
+// contributes smoothing, a value in (0, 1], to the average, and the previous
+// average contributes the rest. The defaults are a factor of 2 and a smoothing
+// of 0.3.
+func WithAdaptiveWait(factor, smoothing float64) AdaptiveOption {
+	return adaptiveOption(func(o *adaptiveOptions) {
+		o.factor = factor
+		o.smoothing = smoothing
+	})
+}
+
+// observeGap updates the moving average of the time between calls with gap.
+// Must be called while holding the lock.
+func (d *Debouncer) observeGap(gap time.Duration) {
+	if gap > d.adaptMax {
+		gap = d.adaptMax
+	}
+
+	if d.gapAvg == 0 {
+		d.gapAvg = float64(gap)
+
+		return
+	}
+
+	s := d.adaptSmoothing
+	d.gapAvg = s*float64(gap) + (1-s)*d.gapAvg
+}
+
+// adaptiveWait returns the wait time derived from the moving average of the
+// time between calls. Must be called while holding the lock.
+func (d *Debouncer) adaptiveWait() time.Duration {
+	wait := time.Duration(d.adaptFactor * d.gapAvg)
+	switch {
+	case wait < d.wait:
+		return d.wait
+	case wait > d.adaptMax:
+		return d.adaptMax
+	default:
+		return wait
+	}
+}
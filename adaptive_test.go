@@ -0,0 +1,89 @@
+package debounce
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAdaptive(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		opts  []AdaptiveOption
+		gap   time.Duration
+		calls int
+		// wantWait is the expected time from the last call to the last
+		// invocation.
+		wantWait time.Duration
+	}{
+		{
+			name:  "fast stream",
+			gap:   5 * time.Millisecond,
+			calls: 10,
+			// 2 * 5ms = 10ms, raised to minWait
+			wantWait: 20 * time.Millisecond,
+		},
+		{
+			name:  "slow stream",
+			gap:   30 * time.Millisecond,
+			calls: 4,
+			// 2 * 30ms = 60ms
+			wantWait: 60 * time.Millisecond,
+		},
+		{
+			name:  "very slow stream",
+			gap:   60 * time.Millisecond,
+			calls: 3,
+			// 2 * 60ms = 120ms, capped at maxWait
+			wantWait: 100 * time.Millisecond,
+		},
+		{
+			name:  "custom factor",
+			opts:  []AdaptiveOption{WithAdaptiveWait(1, 0.5)},
+			gap:   30 * time.Millisecond,
+			calls: 4,
+			// 1 * 30ms = 30ms
+			wantWait: 30 * time.Millisecond,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mux := sync.Mutex{}
+			var invoked []time.Time
+			d, _ := NewAdaptive(
+				20*time.Millisecond, 100*time.Millisecond,
+				func() {
+					mux.Lock()
+					defer mux.Unlock()
+					invoked = append(invoked, time.Now())
+				},
+				tt.opts...,
+			)
+
+			var last time.Time
+			for i := 0; i < tt.calls; i++ {
+				if i > 0 {
+					time.Sleep(tt.gap)
+				}
+				last = time.Now()
+				d()
+			}
+			time.Sleep(150 * time.Millisecond)
+
+			mux.Lock()
+			defer mux.Unlock()
+			require.NotEmpty(t, invoked)
+			got := invoked[len(invoked)-1].Sub(last)
+			assert.GreaterOrEqual(t, got, tt.wantWait)
+			assert.Less(t, got, tt.wantWait+10*time.Millisecond)
+		})
+	}
+}
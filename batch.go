@@ -0,0 +1,186 @@
+package debounce
+
+import (
+	"context"
+	"time"
+)
+
+// NewBatch returns a debounced function like NewTyped, but which collects the
+// values of all calls, and passes them to f in the order they were made. Each
+// invocation of f receives a new slice, which f is free to keep or modify.
+//
+// When the first calls of a burst are let through with WithBurstPassThrough,
+// each of their invocations is passed a batch holding only the value of the
+// call which triggered it.
+//
+// The returned cancel function can be used to cancel any pending invocation of
+// f, discarding the values collected for it, but is not required to be called,
+// so can be ignored if not needed.
+//
+// Both add and cancel functions are safe for concurrent use in goroutines, and
+// can both be called multiple times.
+func NewBatch[T any](
+	wait time.Duration,
+	f func(batch []T),
+	opts ...Option,
+) (add func(value T), cancel func()) {
+	d := newBatch(wait, f, opts)
+
+	return func(value T) { d.add([]T{value}) }, d.cancelFunc()
+}
+
+// NewBatchWithMaxWait returns a debounced function like NewBatch, but with a
+// maximum wait time of maxWait, which is the maximum time f is allowed to be
+// delayed before it is invoked with the values collected so far.
+func NewBatchWithMaxWait[T any](
+	wait, maxWait time.Duration,
+	f func(batch []T),
+	opts ...Option,
+) (add func(value T), cancel func()) {
+	d := newBatch(wait, f, opts).withMaxWait(maxWait)
+
+	return func(value T) { d.add([]T{value}) }, d.cancelFunc()
+}
+
+// BatchDebouncer collects the values passed to its Add method into batches,
+// like the debounced function returned by NewBatch, and gives access to the
+// pending batch, for example to report on it, or to flush it on demand.
+//
+// All methods are safe for concurrent use in goroutines. Each value added is
+// passed to the callback function exactly once, unless it is discarded by
+// Cancel or Close, whatever triggers the invocation.
+type BatchDebouncer[T any] struct {
+	d *Debouncer
+}
+
+// NewBatchDebouncer returns a new BatchDebouncer, which passes the values
+// added to it to f in batches like NewBatch.
+//
+// Optional behavior can be configured by passing one or more Option values.
+func NewBatchDebouncer[T any](
+	wait time.Duration,
+	f func(batch []T),
+	opts ...Option,
+) *BatchDebouncer[T] {
+	return &BatchDebouncer[T]{d: newBatch(wait, f, opts)}
+}
+
+// Add adds value to the pending batch.
+func (b *BatchDebouncer[T]) Add(value T) {
+	b.d.add([]T{value})
+}
+
+// Len returns the number of values in the pending batch.
+func (b *BatchDebouncer[T]) Len() int {
+	n := 0
+	b.d.peek(func(value interface{}) {
+		batch, _ := value.([]T)
+		n = len(batch)
+	})
+
+	return n
+}
+
+// Peek returns a copy of the values in the pending batch, in the order they
+// were added, or nil if there are none.
+func (b *BatchDebouncer[T]) Peek() []T {
+	var values []T
+	b.d.peek(func(value interface{}) {
+		batch, _ := value.([]T)
+		values = append(values, batch...)
+	})
+
+	return values
+}
+
+// Flush passes the pending batch to the callback function right away, like
+// Debouncer.Flush. It has no effect if no values are pending, in which case it
+// returns ErrNothingPending.
+func (b *BatchDebouncer[T]) Flush() error {
+	return b.d.Flush()
+}
+
+// Cancel discards the pending batch.
+func (b *BatchDebouncer[T]) Cancel() {
+	b.d.Cancel()
+}
+
+// Close stops the BatchDebouncer like Debouncer.Close, discarding the pending
+// batch unless WithCloseTimeout is used. Further calls to Add have no effect.
+func (b *BatchDebouncer[T]) Close() error {
+	return b.d.Close()
+}
+
+func newBatch[T any](
+	wait time.Duration,
+	f func(batch []T),
+	opts []Option,
+) *Debouncer {
+	// Check the hook before newDebouncer starts anything, like a worker.
+	onDrop := dropHook[T](newOptions(opts))
+	d := newDebouncer(wait, func(_ context.Context, info InvokeInfo) {
+		batch, _ := info.value.([]T)
+		f(batch)
+	}, opts)
+	d.combine = func(acc, next interface{}) interface{} {
+		return append(acc.([]T), next.([]T)...)
+	}
+	if n := d.opts.maxBatchSize; n > 0 {
+		d.full = func(value interface{}) bool {
+			return len(value.([]T)) >= n
+		}
+	}
+	if n := d.opts.maxPending; n > 0 {
+		d.limit = batchLimit[T](n, d.opts.dropPolicy)
+	}
+	d.onDrop = onDrop
+
+	return d
+}
+
+// dropHook returns the onDrop function of a Debouncer collecting batches of T,
+// which calls the hook set with WithOnDrop for each dropped value, or nil if no
+// hook is set. It panics if the hook does not take a T.
+func dropHook[T any](o *options) func(dropped interface{}) {
+	if o.onDrop == nil {
+		return nil
+	}
+
+	hook, ok := o.onDrop.(func(dropped T))
+	if !ok {
+		panic("debounce: WithOnDrop value type does not match")
+	}
+
+	return func(dropped interface{}) {
+		for _, v := range dropped.([]T) {
+			hook(v)
+		}
+	}
+}
+
+// batchLimit returns the limit function of a Debouncer collecting batches of
+// T, which keeps at most n values pending, dropping values as decided by
+// policy.
+func batchLimit[T any](n int, policy DropPolicy) func(
+	pending, value interface{},
+) (interface{}, interface{}, int, bool) {
+	return func(
+		pending, value interface{},
+	) (interface{}, interface{}, int, bool) {
+		batch, next := pending.([]T), value.([]T)
+		excess := len(batch) + len(next) - n
+		if excess <= 0 {
+			return pending, nil, 0, false
+		}
+
+		if policy == DropNewest {
+			return pending, next, len(next), true
+		}
+
+		if excess > len(batch) {
+			excess = len(batch)
+		}
+
+		return batch[excess:], batch[:excess], excess, false
+	}
+}
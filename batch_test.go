@@ -0,0 +1,410 @@
+package debounce
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		wait        time.Duration
+		maxWait     time.Duration
+		opts        []Option
+		calls       []typedOp
+		wantBatches map[time.Duration][][]int
+	}{
+		{
+			name: "one call one batch",
+			wait: 20 * time.Millisecond,
+			calls: []typedOp{
+				{delay: 10 * time.Millisecond, value: 1},
+			},
+			wantBatches: map[time.Duration][][]int{
+				25 * time.Millisecond:  nil,
+				35 * time.Millisecond:  {{1}},
+				150 * time.Millisecond: {{1}},
+			},
+		},
+		{
+			name: "many calls two batches",
+			wait: 20 * time.Millisecond,
+			calls: []typedOp{
+				{delay: 5 * time.Millisecond, value: 1},
+				{delay: 7 * time.Millisecond, value: 2},
+				{delay: 10 * time.Millisecond, value: 3}, // trigger 1
+				{delay: 35 * time.Millisecond, value: 4},
+				{delay: 40 * time.Millisecond, value: 5},
+				{delay: 50 * time.Millisecond, value: 6}, // trigger 2
+			},
+			wantBatches: map[time.Duration][][]int{
+				25 * time.Millisecond: nil,
+				// from call at 10ms (+20ms wait = 30ms)
+				35 * time.Millisecond: {{1, 2, 3}},
+				65 * time.Millisecond: {{1, 2, 3}},
+				// from call at 50ms (+20ms wait = 70ms)
+				75 * time.Millisecond:  {{1, 2, 3}, {4, 5, 6}},
+				150 * time.Millisecond: {{1, 2, 3}, {4, 5, 6}},
+			},
+		},
+		{
+			name: "many calls, one cancel, two batches",
+			wait: 20 * time.Millisecond,
+			calls: []typedOp{
+				{delay: 5 * time.Millisecond, value: 1},
+				{delay: 10 * time.Millisecond, value: 2}, // trigger 1
+				{delay: 35 * time.Millisecond, value: 3},
+				{delay: 40 * time.Millisecond, value: 4},
+				{delay: 50 * time.Millisecond, cancel: true},
+				{delay: 80 * time.Millisecond, value: 5},
+				{delay: 90 * time.Millisecond, value: 6},
+				{delay: 100 * time.Millisecond, value: 7}, // trigger 2
+			},
+			wantBatches: map[time.Duration][][]int{
+				// from call at 10ms (+20ms wait = 30ms)
+				35 * time.Millisecond:  {{1, 2}},
+				115 * time.Millisecond: {{1, 2}},
+				// call at 100ms (+20ms wait = 120ms)
+				125 * time.Millisecond: {{1, 2}, {5, 6, 7}},
+				150 * time.Millisecond: {{1, 2}, {5, 6, 7}},
+			},
+		},
+		{
+			name: "leading and trailing",
+			wait: 20 * time.Millisecond,
+			opts: []Option{WithBurstPassThrough(1)},
+			calls: []typedOp{
+				{delay: 10 * time.Millisecond, value: 1}, // leading
+				{delay: 15 * time.Millisecond, value: 2},
+				{delay: 20 * time.Millisecond, value: 3}, // trailing
+			},
+			wantBatches: map[time.Duration][][]int{
+				5 * time.Millisecond:  nil,
+				15 * time.Millisecond: {{1}},
+				35 * time.Millisecond: {{1}},
+				// from call at 20ms (+20ms wait = 40ms)
+				45 * time.Millisecond:  {{1}, {2, 3}},
+				150 * time.Millisecond: {{1}, {2, 3}},
+			},
+		},
+		{
+			name:    "until right before maxWait",
+			wait:    20 * time.Millisecond,
+			maxWait: 50 * time.Millisecond,
+			calls: []typedOp{
+				{delay: 0 * time.Millisecond, value: 1},
+				{delay: 10 * time.Millisecond, value: 2},
+				{delay: 20 * time.Millisecond, value: 3},
+				{delay: 30 * time.Millisecond, value: 4},
+				{delay: 40 * time.Millisecond, value: 5},
+				{delay: 60 * time.Millisecond, value: 6},
+			},
+			wantBatches: map[time.Duration][][]int{
+				45 * time.Millisecond: nil,
+				// tick over at 50ms via maxWait
+				55 * time.Millisecond: {{1, 2, 3, 4, 5}},
+				// from call at 60ms (+20ms wait = 80ms)
+				85 * time.Millisecond:  {{1, 2, 3, 4, 5}, {6}},
+				150 * time.Millisecond: {{1, 2, 3, 4, 5}, {6}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mux := sync.RWMutex{}
+
+			var got [][]int
+			f := func(batch []int) {
+				mux.Lock()
+				defer mux.Unlock()
+				got = append(got, batch)
+			}
+
+			var add func(int)
+			var c func()
+			if tt.maxWait > 0 {
+				add, c = NewBatchWithMaxWait(
+					tt.wait, tt.maxWait, f, tt.opts...,
+				)
+			} else {
+				add, c = NewBatch(tt.wait, f, tt.opts...)
+			}
+
+			wg := sync.WaitGroup{}
+			for _, op := range tt.calls {
+				wg.Add(1)
+				go func(op typedOp) {
+					defer wg.Done()
+					time.Sleep(op.delay)
+					if op.cancel {
+						c()
+					} else {
+						add(op.value)
+					}
+				}(op)
+			}
+
+			for delay, batches := range tt.wantBatches {
+				wg.Add(1)
+				go func(interval time.Duration, batches [][]int) {
+					defer wg.Done()
+					time.Sleep(interval)
+
+					mux.RLock()
+					defer mux.RUnlock()
+					assert.Equal(t, batches, got, "at %s", interval)
+				}(delay, batches)
+			}
+
+			wg.Wait()
+		})
+	}
+}
+
+func TestNewBatch_ownership(t *testing.T) {
+	t.Parallel()
+
+	batches := make(chan []int, 2)
+	add, _ := NewBatch(5*time.Millisecond, func(batch []int) {
+		batches <- batch
+	})
+
+	add(1)
+	add(2)
+	first := <-batches
+	first[0] = 100
+
+	add(3)
+	second := <-batches
+
+	assert.Equal(t, []int{100, 2}, first)
+	assert.Equal(t, []int{3}, second)
+}
+
+func TestNewBatch_maxBatchSize(t *testing.T) {
+	t.Parallel()
+
+	type flush struct {
+		size int
+		at   time.Duration
+	}
+
+	mux := sync.Mutex{}
+	var flushes []flush
+	start := time.Now()
+	add, _ := NewBatch(50*time.Millisecond, func(batch []int) {
+		mux.Lock()
+		defer mux.Unlock()
+		flushes = append(flushes, flush{len(batch), time.Since(start)})
+	}, WithMaxBatchSize(500))
+
+	wg := sync.WaitGroup{}
+	for w := 0; w < 5; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 250; i++ {
+				add(i)
+			}
+		}()
+	}
+	wg.Wait()
+	added := time.Since(start)
+
+	time.Sleep(100 * time.Millisecond)
+
+	mux.Lock()
+	defer mux.Unlock()
+	require.Len(t, flushes, 3)
+	assert.Equal(t, 500, flushes[0].size)
+	assert.Equal(t, 500, flushes[1].size)
+	assert.Equal(t, 250, flushes[2].size)
+
+	// Full batches are flushed right away, the rest after the wait time.
+	assert.Less(t, flushes[0].at, added+25*time.Millisecond)
+	assert.Less(t, flushes[1].at, added+25*time.Millisecond)
+	assert.GreaterOrEqual(t, flushes[2].at, added+50*time.Millisecond)
+}
+
+func TestNewBatch_maxPending(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		policy      DropPolicy
+		wantBatch   []int
+		wantDropped []int
+	}{
+		{
+			name:        "drop oldest",
+			policy:      DropOldest,
+			wantBatch:   []int{7, 8, 9},
+			wantDropped: []int{1, 2, 3, 4, 5, 6},
+		},
+		{
+			name:        "drop newest",
+			policy:      DropNewest,
+			wantBatch:   []int{1, 2, 3},
+			wantDropped: []int{4, 5, 6, 7, 8, 9},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := make(chan []int, 1)
+			var dropped []int
+			d := newBatch(20*time.Millisecond, func(batch []int) {
+				got <- batch
+			}, []Option{
+				WithMaxPending(3, tt.policy),
+				WithOnDrop(func(v int) { dropped = append(dropped, v) }),
+			})
+
+			start := time.Now()
+			for i := 1; i <= 9; i++ {
+				d.add([]int{i})
+			}
+
+			assert.Equal(t, tt.wantBatch, <-got)
+			assert.Equal(t, tt.wantDropped, dropped)
+			assert.Equal(t, Stats{Calls: 9, Invocations: 1, Dropped: 6},
+				d.Stats())
+			// Dropping values leaves the timer alone.
+			assert.Less(t, time.Since(start), 40*time.Millisecond)
+		})
+	}
+}
+
+func TestWithOnDrop_typeMismatch(t *testing.T) {
+	t.Parallel()
+
+	opts := []Option{
+		WithMaxPending(1, DropOldest),
+		WithOnDrop(func(string) {}),
+	}
+
+	assert.Panics(t, func() { NewBatch(time.Minute, func([]int) {}, opts...) })
+	assert.Panics(t, func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		Pipe(ctx, make(chan int), time.Minute, opts...)
+	})
+	assert.Panics(t, func() {
+		NewErrorCollector(time.Minute, func(error) {}, opts...)
+	})
+}
+
+func TestNewBatch_maxPendingTimers(t *testing.T) {
+	t.Parallel()
+
+	got := make(chan []int, 1)
+	add, _ := NewBatch(20*time.Millisecond, func(batch []int) {
+		got <- batch
+	}, WithMaxPending(1, DropNewest))
+
+	start := time.Now()
+	add(1)
+	time.Sleep(10 * time.Millisecond)
+	add(2)
+
+	// The dropped call at 10ms does not postpone the invocation.
+	assert.Equal(t, []int{1}, <-got)
+	assert.Less(t, time.Since(start), 28*time.Millisecond)
+}
+
+func TestBatchDebouncer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("peek and flush", func(t *testing.T) {
+		t.Parallel()
+
+		batches := make(chan []int, 4)
+		b := NewBatchDebouncer(time.Minute, func(batch []int) {
+			batches <- batch
+		})
+
+		assert.Equal(t, 0, b.Len())
+		assert.Nil(t, b.Peek())
+		assert.ErrorIs(t, b.Flush(), ErrNothingPending)
+
+		b.Add(1)
+		b.Add(2)
+		peeked := b.Peek()
+		assert.Equal(t, []int{1, 2}, peeked)
+		assert.Equal(t, 2, b.Len())
+
+		// Peek returns a copy.
+		peeked[0] = 10
+		b.Add(3)
+		assert.Equal(t, []int{1, 2, 3}, b.Peek())
+
+		require.NoError(t, b.Flush())
+		assert.Equal(t, []int{1, 2, 3}, <-batches)
+		assert.Equal(t, 0, b.Len())
+		assert.ErrorIs(t, b.Flush(), ErrNothingPending)
+
+		b.Add(4)
+		b.Cancel()
+		assert.Equal(t, 0, b.Len())
+		require.NoError(t, b.Close())
+		b.Add(5)
+		assert.Equal(t, 0, b.Len())
+		assert.ErrorIs(t, b.Flush(), ErrClosed)
+		assert.Len(t, batches, 0)
+	})
+
+	t.Run("concurrent adds and flushes", func(t *testing.T) {
+		t.Parallel()
+
+		mux := sync.Mutex{}
+		seen := map[int]int{}
+		b := NewBatchDebouncer(2*time.Millisecond, func(batch []int) {
+			mux.Lock()
+			defer mux.Unlock()
+			for _, v := range batch {
+				seen[v]++
+			}
+		}, WithWorker())
+
+		wg := sync.WaitGroup{}
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 250; j++ {
+					b.Add(i*250 + j)
+					switch j % 50 {
+					case 10:
+						_ = b.Flush()
+					case 20:
+						assert.LessOrEqual(t, len(b.Peek()), 1000)
+					case 30:
+						time.Sleep(3 * time.Millisecond)
+					}
+				}
+			}(i)
+		}
+		wg.Wait()
+		_ = b.Flush()
+		time.Sleep(20 * time.Millisecond)
+
+		mux.Lock()
+		defer mux.Unlock()
+		require.Len(t, seen, 1000)
+		for v, n := range seen {
+			assert.Equal(t, 1, n, "value %d", v)
+		}
+	})
+}
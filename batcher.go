@@ -0,0 +1,296 @@
+package debounce
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Batcher collects items into batches like NewBatch, and passes each batch to
+// a flush function which can fail, such as one writing to a remote sink.
+//
+// A batch is flushed once no items have been added for the wait time, once it
+// holds the number of items set with WithMaxBatchSize, or once the maximum
+// wait time set with WithMaxWait has passed. Flushes never run concurrently,
+// and batches are flushed in the order their items were added.
+//
+// A failed flush is retried as configured with WithBatcherRetry, before any
+// later batch is flushed. If the last attempt fails too, its error is passed
+// to the hook set with WithOnError, the batch is passed to the hook set with
+// WithOnDiscard, and the batch is discarded.
+//
+// All methods are safe for concurrent use in goroutines.
+type Batcher[T any] struct {
+	d      *Debouncer
+	flush  func(ctx context.Context, items []T) error
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// attempts is the maximum number of attempts to flush a batch, and backoff
+	// returns the delay before the next attempt.
+	attempts  int
+	backoff   BackoffFunc
+	onDiscard func(items []T)
+	// redeliverAfter is the timeout set with WithRedeliveryTimeout.
+	redeliverAfter time.Duration
+
+	mux    sync.Mutex
+	closed bool
+}
+
+// NewBatcher returns a new Batcher which passes batches of items to flush once
+// no items have been added for the wait time.
+//
+// The context passed to flush is canceled when Close gives up on waiting for
+// pending items to be flushed.
+//
+// Optional behavior can be configured by passing one or more BatcherOption
+// values, which include all Option values.
+func NewBatcher[T any](
+	wait time.Duration,
+	flush func(ctx context.Context, items []T) error,
+	opts ...BatcherOption,
+) *Batcher[T] {
+	o := newBatcherOptions(opts)
+	b := newBatcher(wait, flush, o)
+	b.attempts = o.attempts
+	b.backoff = o.backoff
+
+	return b
+}
+
+// AckFunc acknowledges a batch delivered by a Batcher created with
+// NewAckBatcher. Calling it with true acknowledges the batch, while calling it
+// with false rejects the batch, so it is redelivered. Only the first call has
+// any effect.
+type AckFunc func(ok bool)
+
+// NewAckBatcher returns a new Batcher like NewBatcher, but which delivers each
+// batch to deliver along with an AckFunc, for at-least-once delivery to sinks
+// which acknowledge batches asynchronously, like message brokers.
+//
+// A batch which is rejected, or not acknowledged within the timeout set with
+// WithRedeliveryTimeout, is redelivered right away, up to the number of times
+// set with WithMaxRedeliveries. The Batcher waits for each batch to be
+// acknowledged before delivering the next one, so a redelivered batch always
+// comes before any newer items, which keep being collected meanwhile, and are
+// never merged into the redelivered batch.
+//
+// Once a batch has been redelivered the maximum number of times, and is still
+// not acknowledged, ErrNacked or ErrTimeout is passed to the hook set with
+// WithOnError, the batch is passed to the hook set with WithOnDiscard, and the
+// Batcher moves on to the next batch.
+//
+// Optional behavior can be configured by passing one or more BatcherOption
+// values, which include all Option values.
+func NewAckBatcher[T any](
+	wait time.Duration,
+	deliver func(items []T, ack AckFunc),
+	opts ...BatcherOption,
+) *Batcher[T] {
+	o := newBatcherOptions(opts)
+	var b *Batcher[T]
+	b = newBatcher(wait, func(ctx context.Context, items []T) error {
+		return b.deliver(ctx, items, deliver)
+	}, o)
+	b.attempts = o.maxRedeliveries + 1
+	b.redeliverAfter = o.redeliverAfter
+	b.backoff = func(int) time.Duration { return 0 }
+
+	return b
+}
+
+func newBatcher[T any](
+	wait time.Duration,
+	flush func(ctx context.Context, items []T) error,
+	o *batcherOptions,
+) *Batcher[T] {
+	b := &Batcher[T]{flush: flush}
+	b.ctx, b.cancel = context.WithCancel(context.Background())
+	if o.onDiscard != nil {
+		var ok bool
+		if b.onDiscard, ok = o.onDiscard.(func(items []T)); !ok {
+			panic("debounce: WithOnDiscard item type does not match Batcher")
+		}
+	}
+	b.d = newBatch(wait, b.invoke, withWorker(o.opts))
+
+	return b
+}
+
+// BatcherOption configures optional behavior of a Batcher. Any Option is a
+// BatcherOption, and so are the options which only concern a Batcher, like
+// WithBatcherRetry.
+type BatcherOption interface {
+	applyBatcher(o *batcherOptions)
+}
+
+type batcherOptions struct {
+	opts            []Option
+	attempts        int
+	backoff         BackoffFunc
+	redeliverAfter  time.Duration
+	maxRedeliveries int
+	onDiscard       interface{}
+}
+
+func newBatcherOptions(opts []BatcherOption) *batcherOptions {
+	o := &batcherOptions{}
+	for _, opt := range opts {
+		opt.applyBatcher(o)
+	}
+
+	return o
+}
+
+type batcherOption func(o *batcherOptions)
+
+func (f batcherOption) applyBatcher(o *batcherOptions) {
+	f(o)
+}
+
+func (f Option) applyBatcher(o *batcherOptions) {
+	o.opts = append(o.opts, f)
+}
+
+// WithRedeliveryTimeout makes a Batcher created with NewAckBatcher redeliver a
+// batch which has not been acknowledged within d of being delivered. A timeout
+// of 0 or less waits for acknowledgement indefinitely, which is the default.
+func WithRedeliveryTimeout(d time.Duration) BatcherOption {
+	return batcherOption(func(o *batcherOptions) {
+		o.redeliverAfter = d
+	})
+}
+
+// WithMaxRedeliveries makes a Batcher created with NewAckBatcher redeliver a
+// batch which was rejected or not acknowledged in time up to n times, before
+// giving up on it. The default of 0 never redelivers batches.
+func WithMaxRedeliveries(n int) BatcherOption {
+	return batcherOption(func(o *batcherOptions) {
+		o.maxRedeliveries = n
+	})
+}
+
+// WithOnDiscard sets a hook which is called with the items of each batch a
+// Batcher gives up on, once all attempts to flush or deliver it have failed,
+// for example to spill them elsewhere. T must be the item type of the Batcher,
+// otherwise NewBatcher and NewAckBatcher panic.
+func WithOnDiscard[T any](hook func(items []T)) BatcherOption {
+	return batcherOption(func(o *batcherOptions) {
+		o.onDiscard = hook
+	})
+}
+
+// WithBatcherRetry makes a Batcher created with NewBatcher retry a failed
+// flush after the delay returned by backoff, until maxAttempts attempts have
+// failed. A maxAttempts of 1 or less disables retries, which is the default.
+func WithBatcherRetry(maxAttempts int, backoff BackoffFunc) BatcherOption {
+	return batcherOption(func(o *batcherOptions) {
+		o.attempts = maxAttempts
+		o.backoff = backoff
+	})
+}
+
+// Add adds item to the pending batch. Items added after Close has been called
+// are discarded.
+func (b *Batcher[T]) Add(item T) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	if !b.closed {
+		b.d.add([]T{item})
+	}
+}
+
+// Close flushes any pending items right away, and stops the Batcher once they
+// have been flushed, including any retries. If ctx is done before then, Close
+// cancels the context passed to a running flush, discards any remaining items,
+// and returns the context's error.
+//
+// Calling Close more than once has no further effect.
+func (b *Batcher[T]) Close(ctx context.Context) error {
+	b.mux.Lock()
+	closed := b.closed
+	b.closed = true
+	b.mux.Unlock()
+
+	if closed {
+		return nil
+	}
+
+	flushAndWait(ctx, b.d)
+	b.cancel()
+	closeAndWait(b.d)
+
+	return ctx.Err()
+}
+
+func (b *Batcher[T]) invoke(items []T) {
+	err := b.retry(items)
+	if err == nil {
+		return
+	}
+
+	if b.d.opts.onError != nil {
+		b.d.opts.onError(err)
+	}
+	if b.onDiscard != nil {
+		b.onDiscard(items)
+	}
+}
+
+// retry flushes items, retrying up to b.attempts attempts in total, and
+// returns the error of the last attempt.
+func (b *Batcher[T]) retry(items []T) error {
+	for attempt := 1; ; attempt++ {
+		err := b.flush(b.ctx, items)
+		if err == nil || attempt >= b.attempts || b.ctx.Err() != nil {
+			return err
+		}
+
+		t := time.NewTimer(b.backoff(attempt))
+		select {
+		case <-t.C:
+		case <-b.ctx.Done():
+			t.Stop()
+
+			return err
+		}
+	}
+}
+
+// deliver passes items to f along with an AckFunc, and waits for the batch to
+// be acknowledged. It returns ErrNacked if the batch is rejected, ErrTimeout if
+// it is not acknowledged within the timeout set with WithRedeliveryTimeout, or
+// the context's error if ctx is done first.
+func (b *Batcher[T]) deliver(
+	ctx context.Context,
+	items []T,
+	f func(items []T, ack AckFunc),
+) error {
+	acked := make(chan bool, 1)
+	once := sync.Once{}
+	f(items, func(ok bool) {
+		once.Do(func() { acked <- ok })
+	})
+
+	var timeout <-chan time.Time
+	if d := b.redeliverAfter; d > 0 {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		timeout = t.C
+	}
+
+	select {
+	case ok := <-acked:
+		if !ok {
+			return ErrNacked
+		}
+
+		return nil
+	case <-timeout:
+		return ErrTimeout
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,379 @@
+package debounce
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// batchRecorder records the batches passed to a Batcher's flush function,
+// failing the first failures attempts.
+type batchRecorder struct {
+	mux      sync.Mutex
+	batches  [][]int
+	attempts int
+	failures int
+	running  bool
+	overlap  bool
+}
+
+func (r *batchRecorder) flush(_ context.Context, items []int) error {
+	r.mux.Lock()
+	if r.running {
+		r.overlap = true
+	}
+	r.running = true
+	r.attempts++
+	fail := r.attempts <= r.failures
+	if !fail {
+		r.batches = append(r.batches, items)
+	}
+	r.mux.Unlock()
+
+	time.Sleep(time.Millisecond)
+
+	r.mux.Lock()
+	r.running = false
+	r.mux.Unlock()
+
+	if fail {
+		return errors.New("failed")
+	}
+
+	return nil
+}
+
+func (r *batchRecorder) get() ([][]int, int, bool) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	return r.batches, r.attempts, r.overlap
+}
+
+func TestBatcher(t *testing.T) {
+	t.Parallel()
+
+	t.Run("flushes once wait expires", func(t *testing.T) {
+		t.Parallel()
+
+		r := &batchRecorder{}
+		b := NewBatcher(10*time.Millisecond, r.flush)
+		b.Add(1)
+		b.Add(2)
+		b.Add(3)
+		time.Sleep(30 * time.Millisecond)
+
+		batches, _, _ := r.get()
+		assert.Equal(t, [][]int{{1, 2, 3}}, batches)
+		require.NoError(t, b.Close(context.Background()))
+	})
+
+	t.Run("flushes once full", func(t *testing.T) {
+		t.Parallel()
+
+		r := &batchRecorder{}
+		b := NewBatcher(time.Second, r.flush, WithMaxBatchSize(2))
+		for i := 1; i <= 5; i++ {
+			b.Add(i)
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		batches, _, overlap := r.get()
+		assert.Equal(t, [][]int{{1, 2}, {3, 4}}, batches)
+		assert.False(t, overlap)
+
+		require.NoError(t, b.Close(context.Background()))
+		batches, _, _ = r.get()
+		assert.Equal(t, [][]int{{1, 2}, {3, 4}, {5}}, batches)
+	})
+
+	t.Run("retries failed flush before later batches", func(t *testing.T) {
+		t.Parallel()
+
+		r := &batchRecorder{failures: 2}
+		var errs []error
+		b := NewBatcher(5*time.Millisecond, r.flush,
+			WithBatcherRetry(3, func(int) time.Duration {
+				return 10 * time.Millisecond
+			}),
+			WithOnError(func(err error) { errs = append(errs, err) }),
+		)
+		b.Add(1)
+		b.Add(2)
+		time.Sleep(10 * time.Millisecond)
+		b.Add(3)
+		time.Sleep(50 * time.Millisecond)
+
+		batches, attempts, overlap := r.get()
+		assert.Equal(t, [][]int{{1, 2}, {3}}, batches)
+		assert.Equal(t, 4, attempts)
+		assert.False(t, overlap)
+
+		require.NoError(t, b.Close(context.Background()))
+		assert.Empty(t, errs)
+	})
+
+	t.Run("reports error once attempts run out", func(t *testing.T) {
+		t.Parallel()
+
+		r := &batchRecorder{failures: 10}
+		errs := make(chan error, 10)
+		b := NewBatcher(5*time.Millisecond, r.flush,
+			WithBatcherRetry(2, func(int) time.Duration {
+				return 5 * time.Millisecond
+			}),
+			WithOnError(func(err error) { errs <- err }),
+		)
+		b.Add(1)
+
+		require.Error(t, <-errs)
+		require.NoError(t, b.Close(context.Background()))
+
+		batches, attempts, _ := r.get()
+		assert.Empty(t, batches)
+		assert.Equal(t, 2, attempts)
+		assert.Len(t, errs, 0)
+	})
+
+	t.Run("close flushes pending items", func(t *testing.T) {
+		t.Parallel()
+
+		r := &batchRecorder{}
+		b := NewBatcher(time.Second, r.flush)
+		b.Add(1)
+		b.Add(2)
+
+		require.NoError(t, b.Close(context.Background()))
+		batches, _, _ := r.get()
+		assert.Equal(t, [][]int{{1, 2}}, batches)
+
+		b.Add(3)
+		require.NoError(t, b.Close(context.Background()))
+		batches, _, _ = r.get()
+		assert.Equal(t, [][]int{{1, 2}}, batches)
+	})
+
+	t.Run("close gives up once context is done", func(t *testing.T) {
+		t.Parallel()
+
+		r := &batchRecorder{failures: 10}
+		b := NewBatcher(time.Second, r.flush,
+			WithBatcherRetry(10, func(int) time.Duration {
+				return time.Second
+			}),
+		)
+		b.Add(1)
+
+		ctx, cancel := context.WithTimeout(
+			context.Background(), 20*time.Millisecond,
+		)
+		defer cancel()
+
+		start := time.Now()
+		err := b.Close(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+
+		_, attempts, _ := r.get()
+		assert.Equal(t, 1, attempts)
+	})
+}
+
+// delivery is a batch delivered by a Batcher created with NewAckBatcher.
+type delivery struct {
+	items []int
+	at    time.Duration
+}
+
+// ackRecorder records the batches delivered by a Batcher created with
+// NewAckBatcher, and acknowledges each with the result of respond, called with
+// the number of the delivery, starting at 1. A nil result leaves the delivery
+// unacknowledged.
+type ackRecorder struct {
+	mux        sync.Mutex
+	start      time.Time
+	deliveries []delivery
+	respond    func(n int) *bool
+}
+
+func (r *ackRecorder) deliver(items []int, ack AckFunc) {
+	r.mux.Lock()
+	r.deliveries = append(r.deliveries, delivery{
+		items: items,
+		at:    time.Since(r.start),
+	})
+	ok := r.respond(len(r.deliveries))
+	r.mux.Unlock()
+
+	if ok != nil {
+		// Acknowledge asynchronously, as a broker would.
+		go func() {
+			time.Sleep(5 * time.Millisecond)
+			ack(*ok)
+			ack(!*ok)
+		}()
+	}
+}
+
+func (r *ackRecorder) get() []delivery {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	return append([]delivery(nil), r.deliveries...)
+}
+
+func TestNewAckBatcher(t *testing.T) {
+	t.Parallel()
+
+	ack, nack := true, false
+
+	t.Run("nacked batch is redelivered first", func(t *testing.T) {
+		t.Parallel()
+
+		r := &ackRecorder{start: time.Now(), respond: func(n int) *bool {
+			if n == 1 {
+				return &nack
+			}
+
+			return &ack
+		}}
+		b := NewAckBatcher(10*time.Millisecond, r.deliver,
+			WithMaxRedeliveries(2),
+		)
+
+		b.Add(1)
+		b.Add(2)
+		time.Sleep(12 * time.Millisecond)
+		// Added while the first batch is being nacked and redelivered.
+		b.Add(3)
+		time.Sleep(40 * time.Millisecond)
+
+		got := r.get()
+		require.Len(t, got, 3)
+		assert.Equal(t, []int{1, 2}, got[0].items)
+		assert.Equal(t, []int{1, 2}, got[1].items)
+		assert.Equal(t, []int{3}, got[2].items)
+
+		// Redelivered once the nack arrives.
+		assert.InDelta(t, 10*time.Millisecond, got[0].at,
+			float64(5*time.Millisecond))
+		assert.InDelta(t, 15*time.Millisecond, got[1].at,
+			float64(5*time.Millisecond))
+		// The newer batch waits for the redelivered one to be acknowledged.
+		assert.GreaterOrEqual(t, got[2].at, got[1].at+5*time.Millisecond)
+
+		require.NoError(t, b.Close(context.Background()))
+	})
+
+	t.Run("unacknowledged batch is redelivered", func(t *testing.T) {
+		t.Parallel()
+
+		r := &ackRecorder{start: time.Now(), respond: func(n int) *bool {
+			if n == 1 {
+				return nil
+			}
+
+			return &ack
+		}}
+		b := NewAckBatcher(10*time.Millisecond, r.deliver,
+			WithRedeliveryTimeout(20*time.Millisecond),
+			WithMaxRedeliveries(1),
+		)
+
+		b.Add(1)
+		time.Sleep(50 * time.Millisecond)
+
+		got := r.get()
+		require.Len(t, got, 2)
+		assert.Equal(t, []int{1}, got[1].items)
+		assert.InDelta(t, 30*time.Millisecond, got[1].at,
+			float64(5*time.Millisecond))
+
+		require.NoError(t, b.Close(context.Background()))
+	})
+
+	t.Run("gives up after max redeliveries", func(t *testing.T) {
+		t.Parallel()
+
+		r := &ackRecorder{start: time.Now(), respond: func(n int) *bool {
+			if n <= 3 {
+				return &nack
+			}
+
+			return &ack
+		}}
+
+		mux := sync.Mutex{}
+		var errs []error
+		var discarded [][]int
+		b := NewAckBatcher(time.Minute, r.deliver,
+			WithMaxRedeliveries(2),
+			WithOnError(func(err error) {
+				mux.Lock()
+				defer mux.Unlock()
+				errs = append(errs, err)
+			}),
+			WithOnDiscard(func(items []int) {
+				mux.Lock()
+				defer mux.Unlock()
+				discarded = append(discarded, items)
+			}),
+		)
+
+		b.Add(1)
+		b.Add(2)
+		require.NoError(t, b.Close(context.Background()))
+
+		got := r.get()
+		require.Len(t, got, 3)
+		for _, d := range got {
+			assert.Equal(t, []int{1, 2}, d.items)
+		}
+
+		mux.Lock()
+		defer mux.Unlock()
+		require.Len(t, errs, 1)
+		assert.ErrorIs(t, errs[0], ErrNacked)
+		assert.Equal(t, [][]int{{1, 2}}, discarded)
+	})
+
+	t.Run("close gives up waiting", func(t *testing.T) {
+		t.Parallel()
+
+		r := &ackRecorder{
+			start:   time.Now(),
+			respond: func(int) *bool { return nil },
+		}
+		b := NewAckBatcher(time.Minute, r.deliver)
+
+		b.Add(1)
+		ctx, cancel := context.WithTimeout(
+			context.Background(), 20*time.Millisecond,
+		)
+		defer cancel()
+
+		err := b.Close(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Len(t, r.get(), 1)
+	})
+}
+
+func TestWithOnDiscard_typeMismatch(t *testing.T) {
+	t.Parallel()
+
+	assert.Panics(t, func() {
+		NewBatcher(time.Minute, func(context.Context, []int) error {
+			return nil
+		}, WithOnDiscard(func([]string) {}))
+	})
+	assert.Panics(t, func() {
+		NewAckBatcher(time.Minute, func([]int, AckFunc) {},
+			WithOnDiscard(func([]string) {}),
+		)
+	})
+}
@@ -0,0 +1,79 @@
+package debounce
+
+import (
+	"time"
+)
+
+// Builder builds a Debouncer step by step, as an alternative to passing a long
+// list of Option values to NewDebouncer, which also makes it easy to leave out
+// options conditionally.
+//
+// A Builder is an immutable value, and each of its methods returns a new
+// Builder, so a base Builder can be shared and specialized without affecting
+// it.
+type Builder struct {
+	wait time.Duration
+	opts []Option
+}
+
+// NewBuilder returns a new Builder, with a wait time of zero and no options.
+func NewBuilder() Builder {
+	return Builder{}
+}
+
+// Wait returns a copy of b with the given wait time.
+func (b Builder) Wait(wait time.Duration) Builder {
+	b.wait = wait
+
+	return b
+}
+
+// MaxWait returns a copy of b with the given maximum wait time, like
+// WithMaxWait.
+func (b Builder) MaxWait(maxWait time.Duration) Builder {
+	return b.With(WithMaxWait(maxWait))
+}
+
+// Leading returns a copy of b which invokes the callback function right away
+// on the first call of a burst, like WithBurstPassThrough(1).
+func (b Builder) Leading() Builder {
+	return b.With(WithBurstPassThrough(1))
+}
+
+// OnInvoke returns a copy of b with the given invocation hook, like
+// WithOnInvoke.
+func (b Builder) OnInvoke(hook func(info InvokeInfo)) Builder {
+	return b.With(WithOnInvoke(hook))
+}
+
+// With returns a copy of b with the given options added, for options which
+// have no method of their own.
+func (b Builder) With(opts ...Option) Builder {
+	// Never append in place, as the slice may be shared with other copies.
+	b.opts = append(b.opts[:len(b.opts):len(b.opts)], opts...)
+
+	return b
+}
+
+// Build returns a new Debouncer for f, like NewDebouncer. It returns an error
+// wrapping ErrInvalidOption if any option was given invalid values, rather
+// than panicking like NewDebouncer. As with NewDebouncer, a wait time of zero
+// or less is valid, and a maximum wait time of zero or less disables it.
+func (b Builder) Build(f func()) (*Debouncer, error) {
+	if err := applyOptions(b.opts).validate(); err != nil {
+		return nil, err
+	}
+
+	return NewDebouncer(b.wait, f, b.opts...), nil
+}
+
+// MustBuild is like Build, but panics if b is invalid. It simplifies building
+// a Debouncer from a Builder which is known to be valid, like in tests.
+func (b Builder) MustBuild(f func()) *Debouncer {
+	d, err := b.Build(f)
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
@@ -0,0 +1,110 @@
+package debounce
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder(t *testing.T) {
+	t.Parallel()
+
+	t.Run("equivalent to options", func(t *testing.T) {
+		t.Parallel()
+
+		hook := func(InvokeInfo) {}
+		built, err := NewBuilder().
+			Wait(20 * time.Millisecond).
+			Leading().
+			MaxWait(time.Second).
+			OnInvoke(hook).
+			With(WithWorker()).
+			Build(func() {})
+		require.NoError(t, err)
+		defer built.Close()
+
+		want := NewDebouncer(20*time.Millisecond, func() {},
+			WithBurstPassThrough(1),
+			WithMaxWait(time.Second),
+			WithOnInvoke(hook),
+			WithWorker(),
+		)
+		defer want.Close()
+
+		assert.Equal(t, want.wait, built.wait)
+		assert.Equal(t, want.maxWait, built.maxWait)
+		assert.Equal(t, want.opts.burstPassThrough, built.opts.burstPassThrough)
+		assert.NotNil(t, built.opts.onInvoke)
+		assert.True(t, built.opts.worker)
+	})
+
+	t.Run("invokes", func(t *testing.T) {
+		t.Parallel()
+
+		got := make(chan InvokeReason, 2)
+		d := NewBuilder().
+			Wait(10 * time.Millisecond).
+			Leading().
+			OnInvoke(func(info InvokeInfo) { got <- info.Reason }).
+			MustBuild(func() {})
+
+		d.Debounce()
+		d.Debounce()
+		assert.Equal(t, InvokeImmediate, <-got)
+		assert.Equal(t, InvokeWait, <-got)
+	})
+
+	t.Run("copy safe", func(t *testing.T) {
+		t.Parallel()
+
+		base := NewBuilder().Wait(time.Second).With(WithWorker())
+		leading := base.Leading()
+		maxWait := base.MaxWait(time.Minute)
+
+		b := base.MustBuild(func() {})
+		defer b.Close()
+		l := leading.MustBuild(func() {})
+		defer l.Close()
+		m := maxWait.MustBuild(func() {})
+		defer m.Close()
+
+		assert.Equal(t, 0, b.opts.burstPassThrough)
+		assert.Equal(t, time.Duration(0), b.maxWait)
+		assert.Equal(t, 1, l.opts.burstPassThrough)
+		assert.Equal(t, time.Duration(0), l.maxWait)
+		assert.Equal(t, 0, m.opts.burstPassThrough)
+		assert.Equal(t, time.Minute, m.maxWait)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		t.Parallel()
+
+		for _, b := range []Builder{
+			NewBuilder().With(WithWaitRange(time.Second, time.Millisecond)),
+			NewBuilder().With(WithBackoff(1, time.Second, time.Second)),
+			NewBuilder().With(WithBackoff(2, time.Second, 0)),
+		} {
+			d, err := b.Build(func() {})
+			assert.ErrorIs(t, err, ErrInvalidOption)
+			assert.Nil(t, d)
+			assert.Panics(t, func() { b.MustBuild(func() {}) })
+		}
+	})
+
+	t.Run("negative waits", func(t *testing.T) {
+		t.Parallel()
+
+		// Like with NewDebouncer, negative waits are valid.
+		for _, b := range []Builder{
+			NewBuilder().Wait(-time.Second),
+			NewBuilder().With(WithWait(-time.Second)),
+			NewBuilder().Wait(time.Second).MaxWait(-time.Second),
+		} {
+			d, err := b.Build(func() {})
+			require.NoError(t, err)
+			require.NoError(t, d.Close())
+		}
+	})
+}
@@ -0,0 +1,156 @@
+package debounce
+
+import (
+	"context"
+	"time"
+)
+
+// Chan debounces values received from in, like NewTyped, and sends the value
+// of the last call of each burst to the returned channel.
+//
+// The returned channel has a buffer of one. When a value is due while the
+// previous one has not been received yet, the previous value is dropped in
+// favor of the new one, so a slow receiver always receives the latest value,
+// and never holds up reading from in.
+//
+// Once in is closed, any pending value is sent right away, or discarded with
+// WithDiscardOnClose, after which the returned channel is closed. When ctx is
+// done, any pending value is always discarded, and the returned channel is
+// closed. Either way, all timers are stopped, and no goroutines are left
+// running once the returned channel is closed.
+//
+// Optional behavior can be configured by passing one or more Option values.
+// Values are always sent in order from a single goroutine, as if WithWorker was
+// used.
+func Chan[T any](
+	ctx context.Context,
+	in <-chan T,
+	wait time.Duration,
+	opts ...Option,
+) <-chan T {
+	out := make(chan T, 1)
+	send := func(value T) { sendLatest(out, value) }
+	d := newTyped(wait, send, withWorker(opts))
+
+	go func() {
+		defer close(out)
+		forward(ctx, in, d, func(value T) { d.add(value) })
+	}()
+
+	return out
+}
+
+// Pipe collects values received from in into batches, like NewBatch, and sends
+// each batch to the returned channel, with values in the order they were
+// received.
+//
+// Batches are never dropped. While the receiver is not ready, further batches
+// are queued, and reading from in continues.
+//
+// Once in is closed, any pending batch is sent right away, or discarded with
+// WithDiscardOnClose, after which the returned channel is closed. When ctx is
+// done, any pending and queued batches are always discarded, and the returned
+// channel is closed. Either way, all timers are stopped, and no goroutines are
+// left running once the returned channel is closed.
+//
+// Optional behavior can be configured by passing one or more Option values,
+// like WithMaxWait and WithMaxBatchSize. Batches are always sent in order from
+// a single goroutine, as if WithWorker was used.
+func Pipe[T any](
+	ctx context.Context,
+	in <-chan T,
+	wait time.Duration,
+	opts ...Option,
+) <-chan []T {
+	out := make(chan []T)
+	send := func(batch []T) {
+		select {
+		case out <- batch:
+		case <-ctx.Done():
+		}
+	}
+	d := newBatch(wait, send, withWorker(opts))
+
+	go func() {
+		defer close(out)
+		forward(ctx, in, d, func(value T) { d.add([]T{value}) })
+	}()
+
+	return out
+}
+
+// forward passes values received from in to add, until in is closed or ctx is
+// done. Once in is closed, d is flushed, unless WithDiscardOnClose is used.
+// Either way d is closed before forward returns.
+func forward[T any](
+	ctx context.Context,
+	in <-chan T,
+	d *Debouncer,
+	add func(value T),
+) {
+	defer closeAndWait(d)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case value, ok := <-in:
+			if !ok {
+				if !d.opts.discardOnClose {
+					flushAndWait(ctx, d)
+				}
+
+				return
+			}
+			add(value)
+		}
+	}
+}
+
+// sendLatest sends value to ch without blocking, dropping the oldest value in
+// ch to make room if needed. It must not be called concurrently for the same
+// channel.
+func sendLatest[T any](ch chan T, value T) {
+	for {
+		select {
+		case ch <- value:
+			return
+		default:
+		}
+
+		select {
+		case <-ch:
+		default:
+		}
+	}
+}
+
+// withWorker returns opts with WithWorker added, leaving opts untouched.
+func withWorker(opts []Option) []Option {
+	return append(opts[:len(opts):len(opts)], WithWorker())
+}
+
+// flushAndWait flushes d, which must use WithWorker, and waits for the flushed
+// invocation to complete, or for ctx to be done.
+func flushAndWait(ctx context.Context, d *Debouncer) {
+	// With nothing pending, or once closed, there is nothing to wait for but
+	// the marker executed below, so the error of Flush does not matter.
+	_ = d.Flush()
+
+	done := make(chan struct{})
+	d.worker.Execute(func() { close(done) })
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
+
+// closeAndWait closes d, which must use WithWorker, and waits for its worker
+// to exit.
+func closeAndWait(d *Debouncer) {
+	// Close can only fail with ErrTimeout due to WithCloseTimeout, and the
+	// returned channel is closed either way once the worker exits.
+	_ = d.Close()
+	<-d.worker.exited
+}
@@ -0,0 +1,382 @@
+package debounce
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// receiveAll receives values from ch until it is closed, recording the time
+// each value was received at relative to start.
+func receiveAll[T any](ch <-chan T, start time.Time) ([]T, []time.Duration) {
+	var values []T
+	var times []time.Duration
+	for v := range ch {
+		values = append(values, v)
+		times = append(times, time.Since(start))
+	}
+
+	return values, times
+}
+
+func TestChan(t *testing.T) {
+	t.Parallel()
+
+	t.Run("bursts", func(t *testing.T) {
+		t.Parallel()
+
+		in := make(chan int)
+		start := time.Now()
+		out := Chan(context.Background(), in, 20*time.Millisecond)
+
+		go func() {
+			for i := 1; i <= 3; i++ {
+				in <- i
+				time.Sleep(5 * time.Millisecond)
+			}
+			time.Sleep(40 * time.Millisecond)
+			for i := 4; i <= 6; i++ {
+				in <- i
+				time.Sleep(5 * time.Millisecond)
+			}
+			time.Sleep(40 * time.Millisecond)
+			close(in)
+		}()
+
+		values, times := receiveAll(out, start)
+		assert.Equal(t, []int{3, 6}, values)
+		if assert.Len(t, times, 2) {
+			// from value at 10ms (+20ms wait = 30ms)
+			assert.InDelta(t, 30*time.Millisecond, times[0],
+				float64(10*time.Millisecond))
+			// from value at 65ms (+20ms wait = 85ms)
+			assert.InDelta(t, 85*time.Millisecond, times[1],
+				float64(10*time.Millisecond))
+		}
+	})
+
+	t.Run("input closed mid-burst", func(t *testing.T) {
+		t.Parallel()
+
+		in := make(chan int)
+		start := time.Now()
+		out := Chan(context.Background(), in, time.Hour)
+
+		in <- 1
+		in <- 2
+		close(in)
+
+		values, times := receiveAll(out, start)
+		assert.Equal(t, []int{2}, values)
+		if assert.Len(t, times, 1) {
+			assert.Less(t, times[0], 50*time.Millisecond)
+		}
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		in := make(chan int)
+		out := Chan(ctx, in, time.Hour)
+
+		in <- 1
+		cancel()
+
+		values, _ := receiveAll(out, time.Now())
+		assert.Empty(t, values)
+	})
+
+	t.Run("slow receiver", func(t *testing.T) {
+		t.Parallel()
+
+		in := make(chan int)
+		out := Chan(context.Background(), in, 0)
+
+		// Nothing receives while values are sent, and the input is never
+		// blocked, as older values are dropped.
+		for i := 1; i <= 5; i++ {
+			in <- i
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		assert.Equal(t, 5, <-out)
+		close(in)
+
+		values, _ := receiveAll(out, time.Now())
+		assert.Empty(t, values)
+	})
+}
+
+func TestPipe(t *testing.T) {
+	t.Parallel()
+
+	t.Run("quiet periods", func(t *testing.T) {
+		t.Parallel()
+
+		in := make(chan int)
+		start := time.Now()
+		out := Pipe(context.Background(), in, 20*time.Millisecond)
+
+		go func() {
+			for i := 1; i <= 3; i++ {
+				in <- i
+				time.Sleep(5 * time.Millisecond)
+			}
+			time.Sleep(40 * time.Millisecond)
+			for i := 4; i <= 5; i++ {
+				in <- i
+				time.Sleep(5 * time.Millisecond)
+			}
+			time.Sleep(40 * time.Millisecond)
+			close(in)
+		}()
+
+		batches, times := receiveAll(out, start)
+		assert.Equal(t, [][]int{{1, 2, 3}, {4, 5}}, batches)
+		if assert.Len(t, times, 2) {
+			// from value at 10ms (+20ms wait = 30ms)
+			assert.InDelta(t, 30*time.Millisecond, times[0],
+				float64(10*time.Millisecond))
+			// from value at 60ms (+20ms wait = 80ms)
+			assert.InDelta(t, 80*time.Millisecond, times[1],
+				float64(10*time.Millisecond))
+		}
+	})
+
+	t.Run("maxWait", func(t *testing.T) {
+		t.Parallel()
+
+		in := make(chan int)
+		start := time.Now()
+		out := Pipe(context.Background(), in, 20*time.Millisecond,
+			WithMaxWait(45*time.Millisecond),
+		)
+
+		go func() {
+			for i := 1; i <= 8; i++ {
+				in <- i
+				time.Sleep(10 * time.Millisecond)
+			}
+			close(in)
+		}()
+
+		batches, times := receiveAll(out, start)
+		assert.Equal(t, [][]int{{1, 2, 3, 4, 5}, {6, 7, 8}}, batches)
+		if assert.Len(t, times, 2) {
+			assert.InDelta(t, 45*time.Millisecond, times[0],
+				float64(10*time.Millisecond))
+			// the final partial batch is sent once the input is closed
+			assert.InDelta(t, 80*time.Millisecond, times[1],
+				float64(10*time.Millisecond))
+		}
+	})
+
+	t.Run("max batch size", func(t *testing.T) {
+		t.Parallel()
+
+		in := make(chan int)
+		out := Pipe(context.Background(), in, time.Hour,
+			WithMaxBatchSize(3),
+		)
+
+		go func() {
+			for i := 1; i <= 7; i++ {
+				in <- i
+			}
+			close(in)
+		}()
+
+		batches, _ := receiveAll(out, time.Now())
+		assert.Equal(t, [][]int{{1, 2, 3}, {4, 5, 6}, {7}}, batches)
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		in := make(chan int)
+		out := Pipe(ctx, in, time.Hour, WithMaxBatchSize(1))
+
+		// The batch is never received.
+		in <- 1
+		in <- 2
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+
+		time.Sleep(10 * time.Millisecond)
+		batches, _ := receiveAll(out, time.Now())
+		assert.Empty(t, batches)
+	})
+}
+
+func TestChan_inputClosed(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		values  []int
+		gap     time.Duration
+		discard bool
+		want    []int
+	}{
+		{name: "idle", want: nil},
+		{name: "mid-burst", values: []int{1, 2, 3}, want: []int{3}},
+		{
+			name:    "mid-burst discarded",
+			values:  []int{1, 2, 3},
+			discard: true,
+			want:    nil,
+		},
+		{
+			name:   "during maxWait window",
+			values: []int{1, 2, 3, 4, 5, 6},
+			gap:    10 * time.Millisecond,
+			want:   []int{4, 6},
+		},
+		{
+			name:    "during maxWait window discarded",
+			values:  []int{1, 2, 3, 4, 5, 6},
+			gap:     10 * time.Millisecond,
+			discard: true,
+			want:    []int{4},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := []Option{WithMaxWait(35 * time.Millisecond)}
+			if tt.discard {
+				opts = append(opts, WithDiscardOnClose())
+			}
+			in := make(chan int)
+			out := Chan(context.Background(), in, time.Hour, opts...)
+
+			go func() {
+				for _, v := range tt.values {
+					in <- v
+					time.Sleep(tt.gap)
+				}
+				close(in)
+			}()
+
+			values, _ := receiveAll(out, time.Now())
+			assert.Equal(t, tt.want, values)
+		})
+	}
+}
+
+func TestPipe_inputClosed(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		values  []int
+		gap     time.Duration
+		discard bool
+		want    [][]int
+	}{
+		{name: "idle", want: nil},
+		{name: "mid-burst", values: []int{1, 2, 3}, want: [][]int{{1, 2, 3}}},
+		{
+			name:    "mid-burst discarded",
+			values:  []int{1, 2, 3},
+			discard: true,
+			want:    nil,
+		},
+		{
+			name:   "during maxWait window",
+			values: []int{1, 2, 3, 4, 5, 6},
+			gap:    10 * time.Millisecond,
+			want:   [][]int{{1, 2, 3, 4}, {5, 6}},
+		},
+		{
+			name:    "during maxWait window discarded",
+			values:  []int{1, 2, 3, 4, 5, 6},
+			gap:     10 * time.Millisecond,
+			discard: true,
+			want:    [][]int{{1, 2, 3, 4}},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := []Option{WithMaxWait(35 * time.Millisecond)}
+			if tt.discard {
+				opts = append(opts, WithDiscardOnClose())
+			}
+			in := make(chan int)
+			out := Pipe(context.Background(), in, time.Hour, opts...)
+
+			go func() {
+				for _, v := range tt.values {
+					in <- v
+					time.Sleep(tt.gap)
+				}
+				close(in)
+			}()
+
+			batches, _ := receiveAll(out, time.Now())
+			assert.Equal(t, tt.want, batches)
+		})
+	}
+}
+
+// TestChan_goroutines is not parallel, so the number of goroutines is not
+// affected by other tests.
+func TestChan_goroutines(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ins := make([]chan int, 0, 4)
+	outs := make([]<-chan int, 0, 2)
+	batchOuts := make([]<-chan []int, 0, 2)
+	for i := 0; i < 2; i++ {
+		in := make(chan int)
+		ins = append(ins, in)
+		outs = append(outs, Chan(ctx, in, time.Hour,
+			WithMaxWait(time.Hour),
+		))
+
+		in = make(chan int)
+		ins = append(ins, in)
+		batchOuts = append(batchOuts, Pipe(ctx, in, time.Hour,
+			WithMaxWait(time.Hour),
+		))
+	}
+	for _, in := range ins {
+		in <- 1
+	}
+
+	// One adapter of each kind is stopped by closing its input, and the other
+	// by canceling the context.
+	close(ins[0])
+	close(ins[1])
+	assert.Equal(t, 1, <-outs[0])
+	assert.Equal(t, []int{1}, <-batchOuts[0])
+	cancel()
+
+	for _, out := range outs {
+		values, _ := receiveAll(out, time.Now())
+		assert.Empty(t, values)
+	}
+	for _, out := range batchOuts {
+		batches, _ := receiveAll(out, time.Now())
+		assert.Empty(t, batches)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	assert.LessOrEqual(t, runtime.NumGoroutine(), before)
+}
@@ -0,0 +1,201 @@
+package debounce
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Coalescer debounces fetches of a value, like a cache refresh, sharing the
+// result of each fetch between all callers waiting for it, and caching it
+// until it is invalidated.
+//
+// Calls to Get made while no fresh result is cached and no fetch is running are
+// debounced like calls to a function returned by New, and all of them wait for
+// the single fetch they are coalesced into. Calls made while a fetch is running
+// wait for that fetch, much like with singleflight, rather than scheduling
+// another one. Once a fetch succeeds, its result is returned right away by Get
+// until Invalidate is called.
+//
+// All methods are safe for concurrent use in goroutines.
+type Coalescer[T any] struct {
+	d     *Debouncer
+	fetch func(ctx context.Context) (T, error)
+
+	mux     sync.Mutex
+	pending *flight[T]
+	running *flight[T]
+	latest  T
+	ok      bool
+	// fresh is true while latest is cached, and gen is incremented by each
+	// call to Invalidate, so a fetch which was running at the time does not
+	// make its result fresh.
+	fresh  bool
+	gen    uint64
+	closed bool
+}
+
+// flight is a single fetch of a Coalescer, whose result is available once done
+// is closed.
+type flight[T any] struct {
+	done  chan struct{}
+	value T
+	err   error
+}
+
+// NewCoalescer returns a new Coalescer, which calls fetch once no calls to Get
+// have been made for the wait time.
+//
+// Optional behavior can be configured by passing one or more Option values.
+func NewCoalescer[T any](
+	wait time.Duration,
+	fetch func(ctx context.Context) (T, error),
+	opts ...Option,
+) *Coalescer[T] {
+	c := &Coalescer[T]{fetch: fetch}
+	c.d = newDebouncer(wait, func(ctx context.Context, _ InvokeInfo) {
+		c.invoke(ctx)
+	}, opts)
+
+	return c
+}
+
+// Get returns the cached result if it is fresh. Otherwise it waits for the
+// fetch covering the call, and returns its result. The value and error of each
+// fetch are returned to all callers which waited for it.
+//
+// If the fetch covering the call does not happen, because the call or the
+// invocation is suppressed, like with WithPredicate or WithInvokeCondition, or
+// because Cancel or Close is called first, Get returns ErrCanceled. If ctx is
+// done first, Get returns the context's error, without affecting the fetch or
+// other callers. Once the Coalescer has been closed, Get returns ErrClosed.
+func (c *Coalescer[T]) Get(ctx context.Context) (T, error) {
+	c.mux.Lock()
+	switch {
+	case c.closed:
+		c.mux.Unlock()
+		var zero T
+
+		return zero, ErrClosed
+	case c.fresh:
+		defer c.mux.Unlock()
+
+		return c.latest, nil
+	}
+
+	f := c.running
+	schedule := f == nil
+	if schedule {
+		if c.pending == nil {
+			c.pending = &flight[T]{done: make(chan struct{})}
+		}
+		f = c.pending
+	}
+	c.mux.Unlock()
+
+	// The call is made without holding the lock, as it may invoke fetch right
+	// away.
+	var skipped <-chan struct{}
+	var p *Promise
+	if schedule {
+		p = c.d.DebounceDone()
+		skipped = p.Done()
+	}
+
+	for {
+		select {
+		case <-f.done:
+			return f.value, f.err
+		case <-skipped:
+			// The flight may still have been fetched by an invocation
+			// covering earlier calls.
+			skipped = nil
+			if p.Err() != nil && !c.started(f) {
+				var zero T
+
+				return zero, ErrCanceled
+			}
+		case <-ctx.Done():
+			var zero T
+
+			return zero, ctx.Err()
+		}
+	}
+}
+
+// Latest returns the value of the most recent successful fetch, and reports if
+// there has been one. The value is kept until a later fetch succeeds, even once
+// it has been invalidated.
+func (c *Coalescer[T]) Latest() (T, bool) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	return c.latest, c.ok
+}
+
+// Invalidate marks the cached result as stale, so the next call to Get
+// schedules a new fetch. The result of a fetch which is running when
+// Invalidate is called is returned to the callers waiting for it, but is not
+// cached.
+func (c *Coalescer[T]) Invalidate() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.fresh = false
+	c.gen++
+}
+
+// Cancel cancels any pending fetch, making the calls to Get waiting for it
+// return ErrCanceled. A fetch which is already running is not affected.
+func (c *Coalescer[T]) Cancel() {
+	c.d.Cancel()
+}
+
+// Close cancels any pending fetch like Cancel, and cancels the context of a
+// running fetch, releasing the underlying Debouncer. Further calls to Get
+// return ErrClosed. Calling Close more than once has no effect.
+func (c *Coalescer[T]) Close() error {
+	c.mux.Lock()
+	c.closed = true
+	c.fresh = false
+	c.mux.Unlock()
+
+	return c.d.Close()
+}
+
+// started reports if f is running, or has been fetched.
+func (c *Coalescer[T]) started(f *flight[T]) bool {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	return c.running == f || c.pending != f
+}
+
+func (c *Coalescer[T]) invoke(ctx context.Context) {
+	c.mux.Lock()
+	f := c.pending
+	if f == nil {
+		// Nobody is waiting, as the calls which armed the timer have already
+		// been covered by an earlier fetch.
+		c.mux.Unlock()
+
+		return
+	}
+	c.pending = nil
+	c.running = f
+	gen := c.gen
+	c.mux.Unlock()
+
+	f.value, f.err = c.fetch(ctx)
+
+	c.mux.Lock()
+	c.running = nil
+	if f.err == nil {
+		c.latest = f.value
+		c.ok = true
+	}
+	c.fresh = f.err == nil && c.gen == gen && !c.closed
+	c.mux.Unlock()
+
+	close(f.done)
+}
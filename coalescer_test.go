@@ -0,0 +1,227 @@
+package debounce
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoalescer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("concurrent calls share one fetch", func(t *testing.T) {
+		t.Parallel()
+
+		var fetches int64
+		c := NewCoalescer(10*time.Millisecond,
+			func(context.Context) (int64, error) {
+				time.Sleep(10 * time.Millisecond)
+
+				return atomic.AddInt64(&fetches, 1), nil
+			},
+		)
+
+		_, ok := c.Latest()
+		assert.False(t, ok)
+
+		// Calls within the wait time are coalesced, and calls made while the
+		// fetch is running wait for it.
+		got := make(chan int64, 30)
+		wg := sync.WaitGroup{}
+		for i := 0; i < 30; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				time.Sleep(time.Duration(i%3) * 6 * time.Millisecond)
+				v, err := c.Get(context.Background())
+				assert.NoError(t, err)
+				got <- v
+			}(i)
+		}
+		wg.Wait()
+		close(got)
+
+		for v := range got {
+			assert.Equal(t, int64(1), v)
+		}
+		assert.Equal(t, int64(1), atomic.LoadInt64(&fetches))
+
+		v, ok := c.Latest()
+		assert.True(t, ok)
+		assert.Equal(t, int64(1), v)
+
+		// A later call returns the cached result.
+		v, err := c.Get(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), v)
+		assert.Equal(t, int64(1), atomic.LoadInt64(&fetches))
+
+		// Once invalidated, a later call starts a new fetch.
+		c.Invalidate()
+		v, err = c.Get(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), v)
+		assert.Equal(t, int64(2), atomic.LoadInt64(&fetches))
+	})
+
+	t.Run("invalidate while fetching", func(t *testing.T) {
+		t.Parallel()
+
+		started := make(chan struct{})
+		release := make(chan struct{})
+		var fetches int64
+		c := NewCoalescer(0, func(context.Context) (int64, error) {
+			n := atomic.AddInt64(&fetches, 1)
+			if n == 1 {
+				close(started)
+				<-release
+			}
+
+			return n, nil
+		})
+
+		got := make(chan int64)
+		go func() {
+			v, _ := c.Get(context.Background())
+			got <- v
+		}()
+		<-started
+		c.Invalidate()
+		close(release)
+		assert.Equal(t, int64(1), <-got)
+
+		// The result fetched across the invalidation is not cached.
+		v, err := c.Get(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), v)
+	})
+
+	t.Run("suppressed call", func(t *testing.T) {
+		t.Parallel()
+
+		c := NewCoalescer(time.Millisecond,
+			func(context.Context) (int, error) { return 1, nil },
+			WithPredicate(func() bool { return false }),
+		)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		_, err := c.Get(ctx)
+		assert.ErrorIs(t, err, ErrCanceled)
+	})
+
+	t.Run("skipped invocation", func(t *testing.T) {
+		t.Parallel()
+
+		var fetches int64
+		c := NewCoalescer(5*time.Millisecond,
+			func(context.Context) (int, error) {
+				atomic.AddInt64(&fetches, 1)
+
+				return 1, nil
+			},
+			WithInvokeCondition(func() bool { return false }),
+		)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		wg := sync.WaitGroup{}
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := c.Get(ctx)
+				assert.ErrorIs(t, err, ErrCanceled)
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, int64(0), atomic.LoadInt64(&fetches))
+	})
+
+	t.Run("close", func(t *testing.T) {
+		t.Parallel()
+
+		c := NewCoalescer(time.Hour,
+			func(context.Context) (int, error) { return 1, nil },
+		)
+
+		errs := make(chan error)
+		go func() {
+			_, err := c.Get(context.Background())
+			errs <- err
+		}()
+		for !c.d.Pending() {
+			time.Sleep(time.Millisecond)
+		}
+
+		assert.NoError(t, c.Close())
+		assert.ErrorIs(t, <-errs, ErrCanceled)
+
+		_, err := c.Get(context.Background())
+		assert.ErrorIs(t, err, ErrClosed)
+		assert.NoError(t, c.Close())
+	})
+
+	t.Run("errors are shared by waiters of a fetch", func(t *testing.T) {
+		t.Parallel()
+
+		errFetch := errors.New("fetch failed")
+		var fetches int64
+		c := NewCoalescer(10*time.Millisecond,
+			func(context.Context) (string, error) {
+				if atomic.AddInt64(&fetches, 1) == 1 {
+					return "", errFetch
+				}
+
+				return "ok", nil
+			},
+		)
+
+		wg := sync.WaitGroup{}
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := c.Get(context.Background())
+				assert.ErrorIs(t, err, errFetch)
+			}()
+		}
+		wg.Wait()
+
+		_, ok := c.Latest()
+		assert.False(t, ok)
+
+		v, err := c.Get(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "ok", v)
+		assert.Equal(t, int64(2), atomic.LoadInt64(&fetches))
+	})
+
+	t.Run("context done before fetch", func(t *testing.T) {
+		t.Parallel()
+
+		c := NewCoalescer(50*time.Millisecond,
+			func(context.Context) (int, error) { return 1, nil },
+		)
+
+		ctx, cancel := context.WithTimeout(
+			context.Background(), 10*time.Millisecond,
+		)
+		defer cancel()
+
+		_, err := c.Get(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		v, err := c.Get(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, v)
+	})
+}
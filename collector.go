@@ -0,0 +1,147 @@
+package debounce
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// defaultMaxErrors is the number of errors an error collector created with
+// NewErrorCollector holds per invocation, unless set with WithMaxPending.
+const defaultMaxErrors = 100
+
+// JoinedError is the error passed to the function of an error collector created
+// with NewErrorCollector, joining the errors reported since the previous
+// invocation, like errors.Join.
+type JoinedError struct {
+	// Errs holds the errors reported, in the order they were reported.
+	Errs []error
+	// Dropped is the number of errors reported which were dropped, as more
+	// errors were reported than the collector holds.
+	Dropped int
+}
+
+// Error returns the messages of the errors reported, separated by newlines,
+// followed by the number of errors dropped, if any.
+func (e *JoinedError) Error() string {
+	msgs := make([]string, 0, len(e.Errs)+1)
+	for _, err := range e.Errs {
+		msgs = append(msgs, err.Error())
+	}
+	if e.Dropped > 0 {
+		msgs = append(msgs, fmt.Sprintf("(%d more errors dropped)", e.Dropped))
+	}
+
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the errors reported.
+func (e *JoinedError) Unwrap() []error {
+	return e.Errs
+}
+
+// Is reports if any of the errors reported matches target, for versions of Go
+// whose errors.Is does not support unwrapping multiple errors.
+func (e *JoinedError) Is(target error) bool {
+	for _, err := range e.Errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As finds the first of the errors reported which matches target, for versions
+// of Go whose errors.As does not support unwrapping multiple errors.
+func (e *JoinedError) As(target interface{}) bool {
+	for _, err := range e.Errs {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NewErrorCollector returns a debounced function like New, which takes an
+// error, and collects the errors of all calls, for callbacks reporting or
+// repairing the failed operations behind them. Once wait time has elapsed
+// since the last call, f is called with a *JoinedError holding the errors
+// collected, which are then cleared.
+//
+// Nil errors are ignored, but still count as calls. When only nil errors were
+// reported since the previous invocation, f is called with nil.
+//
+// At most 100 errors are held per invocation, dropping the oldest errors once
+// more are reported, and counting them in JoinedError.Dropped. The limit and
+// which errors are dropped can be set with WithMaxPending.
+//
+// The returned reset function discards the collected errors, and cancels any
+// pending invocation of f, but is not required to be called, so can be ignored
+// if not needed.
+//
+// Both report and reset functions are safe for concurrent use in goroutines,
+// and can both be called multiple times.
+func NewErrorCollector(
+	wait time.Duration,
+	f func(err error),
+	opts ...Option,
+) (report func(err error), reset func()) {
+	onDrop := dropHook[error](newOptions(opts))
+	d := newDebouncer(wait, func(_ context.Context, info InvokeInfo) {
+		collected, _ := info.value.(errorBatch)
+		f(collected.err())
+	}, opts)
+	d.combine = func(acc, next interface{}) interface{} {
+		a, n := acc.(errorBatch), next.(errorBatch)
+		a.errs = append(a.errs[:len(a.errs):len(a.errs)], n.errs...)
+		a.dropped += n.dropped
+
+		return a
+	}
+
+	n := d.opts.maxPending
+	if n <= 0 {
+		n = defaultMaxErrors
+	}
+	limit := batchLimit[error](n, d.opts.dropPolicy)
+	d.limit = func(
+		pending, value interface{},
+	) (interface{}, interface{}, int, bool) {
+		p, v := pending.(errorBatch), value.(errorBatch)
+		errs, dropped, count, dropValue := limit(p.errs, v.errs)
+		p.errs, _ = errs.([]error)
+		p.dropped += count
+
+		return p, dropped, count, dropValue
+	}
+	d.onDrop = onDrop
+
+	return func(err error) {
+		var collected errorBatch
+		if err != nil {
+			collected.errs = []error{err}
+		}
+		d.add(collected)
+	}, d.cancelFunc()
+}
+
+// errorBatch holds the errors collected by NewErrorCollector, and the number of
+// errors dropped.
+type errorBatch struct {
+	errs    []error
+	dropped int
+}
+
+// err returns the errors of b joined into a *JoinedError, or nil if there are
+// none.
+func (b errorBatch) err() error {
+	if len(b.errs) == 0 && b.dropped == 0 {
+		return nil
+	}
+
+	return &JoinedError{Errs: b.errs, Dropped: b.dropped}
+}
@@ -0,0 +1,170 @@
+package debounce
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewErrorCollector(t *testing.T) {
+	t.Parallel()
+
+	newRecorder := func() (func(err error), func() []error) {
+		mux := sync.Mutex{}
+		var errs []error
+
+		return func(err error) {
+				mux.Lock()
+				defer mux.Unlock()
+				errs = append(errs, err)
+			}, func() []error {
+				mux.Lock()
+				defer mux.Unlock()
+
+				return append([]error(nil), errs...)
+			}
+	}
+
+	t.Run("joins errors", func(t *testing.T) {
+		t.Parallel()
+
+		errA := errors.New("a failed")
+		errB := errors.New("b failed")
+		errC := fmt.Errorf("c failed: %w", fs.ErrNotExist)
+
+		record, invocations := newRecorder()
+		report, _ := NewErrorCollector(10*time.Millisecond, record)
+
+		report(errA)
+		report(nil)
+		report(errB)
+		report(errC)
+		time.Sleep(30 * time.Millisecond)
+
+		got := invocations()
+		require.Len(t, got, 1)
+
+		var joined *JoinedError
+		require.ErrorAs(t, got[0], &joined)
+		assert.Equal(t, []error{errA, errB, errC}, joined.Errs)
+		assert.Equal(t, 0, joined.Dropped)
+		assert.Equal(t, "a failed\nb failed\nc failed: file does not exist",
+			got[0].Error())
+
+		for _, err := range []error{errA, errB, errC, fs.ErrNotExist} {
+			assert.ErrorIs(t, got[0], err)
+		}
+		assert.NotErrorIs(t, got[0], fs.ErrExist)
+
+		var pathErr *fs.PathError
+		assert.False(t, errors.As(got[0], &pathErr))
+
+		// Errors are cleared after each invocation.
+		report(errB)
+		time.Sleep(30 * time.Millisecond)
+
+		got = invocations()
+		require.Len(t, got, 2)
+		require.ErrorAs(t, got[1], &joined)
+		assert.Equal(t, []error{errB}, joined.Errs)
+	})
+
+	t.Run("nil errors", func(t *testing.T) {
+		t.Parallel()
+
+		record, invocations := newRecorder()
+		calls := make(chan int, 1)
+		report, _ := NewErrorCollector(10*time.Millisecond, record,
+			WithOnInvoke(func(info InvokeInfo) { calls <- info.Calls }),
+		)
+
+		report(nil)
+		report(nil)
+		time.Sleep(30 * time.Millisecond)
+
+		assert.Equal(t, []error{nil}, invocations())
+		assert.Equal(t, 2, <-calls)
+	})
+
+	t.Run("bounded", func(t *testing.T) {
+		t.Parallel()
+
+		errs := make([]error, 5)
+		for i := range errs {
+			errs[i] = fmt.Errorf("error %d", i)
+		}
+
+		tests := []struct {
+			name   string
+			policy DropPolicy
+			want   []error
+		}{
+			{name: "drop oldest", policy: DropOldest, want: errs[2:]},
+			{name: "drop newest", policy: DropNewest, want: errs[:3]},
+		}
+		for _, tt := range tests {
+			tt := tt
+			t.Run(tt.name, func(t *testing.T) {
+				t.Parallel()
+
+				mux := sync.Mutex{}
+				var dropped []error
+				record, invocations := newRecorder()
+				report, _ := NewErrorCollector(10*time.Millisecond, record,
+					WithMaxPending(3, tt.policy),
+					WithOnDrop(func(err error) {
+						mux.Lock()
+						defer mux.Unlock()
+						dropped = append(dropped, err)
+					}),
+				)
+
+				for _, err := range errs {
+					report(err)
+				}
+				time.Sleep(30 * time.Millisecond)
+
+				got := invocations()
+				require.Len(t, got, 1)
+
+				var joined *JoinedError
+				require.ErrorAs(t, got[0], &joined)
+				assert.Equal(t, tt.want, joined.Errs)
+				assert.Equal(t, 2, joined.Dropped)
+				assert.Contains(t, got[0].Error(), "(2 more errors dropped)")
+
+				mux.Lock()
+				defer mux.Unlock()
+				assert.Len(t, dropped, 2)
+			})
+		}
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		t.Parallel()
+
+		record, invocations := newRecorder()
+		report, reset := NewErrorCollector(10*time.Millisecond, record)
+
+		report(errors.New("discarded"))
+		reset()
+		time.Sleep(30 * time.Millisecond)
+		assert.Empty(t, invocations())
+
+		errA := errors.New("a failed")
+		report(errA)
+		time.Sleep(30 * time.Millisecond)
+
+		got := invocations()
+		require.Len(t, got, 1)
+		var joined *JoinedError
+		require.ErrorAs(t, got[0], &joined)
+		assert.Equal(t, []error{errA}, joined.Errs)
+	})
+}
@@ -0,0 +1,172 @@
+package debounce
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Msg is a message received by a Consumer, holding a value along with a
+// function to report the outcome of processing it, like acknowledging a
+// message received from a queue.
+type Msg[T any] struct {
+	Value T
+	// Done is called exactly once by the Consumer, with the error of the
+	// processing run covering the message, or nil if it succeeded.
+	Done func(err error)
+}
+
+// Consumer debounces processing of messages received from a channel, and
+// reports the outcome to each message, as created with NewConsumer.
+type Consumer[T any] struct {
+	d       *Debouncer
+	process func(ctx context.Context, latest T) error
+	ctx     context.Context
+	cancel  context.CancelFunc
+
+	// stop is closed by Close, and exited once the Consumer has stopped.
+	stop     chan struct{}
+	stopOnce sync.Once
+	exited   chan struct{}
+
+	mux     sync.Mutex
+	pending []Msg[T]
+}
+
+// NewConsumer returns a new Consumer which receives messages from in, and
+// passes the value of the latest message to process once no messages have been
+// received for the wait time. The Done function of every message covered by a
+// processing run is called with the error returned by process, so messages are
+// only acknowledged once processing which includes them has succeeded. Errors
+// are also passed to the hook set with WithOnError.
+//
+// Once in is closed, or Close is called, outstanding messages are processed
+// right away, after which the Consumer stops. With WithDiscardOnClose,
+// outstanding messages are not processed, and their Done function is called
+// with ErrClosed instead. Either way, the Done function of every message
+// received is called exactly once before the Consumer stops.
+//
+// The context passed to process is canceled when Close gives up on waiting for
+// outstanding messages to be processed.
+//
+// Optional behavior can be configured by passing one or more Option values,
+// like WithMaxWait. Messages are always processed in order from a single
+// goroutine, as if WithWorker was used.
+func NewConsumer[T any](
+	in <-chan Msg[T],
+	wait time.Duration,
+	process func(ctx context.Context, latest T) error,
+	opts ...Option,
+) *Consumer[T] {
+	c := &Consumer[T]{
+		process: process,
+		stop:    make(chan struct{}),
+		exited:  make(chan struct{}),
+	}
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+	c.d = NewDebouncer(wait, c.invoke, withWorker(opts)...)
+
+	go c.run(in)
+
+	return c
+}
+
+// Close stops receiving messages, processes outstanding messages right away,
+// and waits for the Consumer to stop. If ctx is done before then, Close cancels
+// the context passed to a running process call, calls the Done function of
+// messages not processed yet with ErrClosed, and returns the context's error.
+//
+// Calling Close more than once, or once in has been closed, only waits for the
+// Consumer to stop.
+func (c *Consumer[T]) Close(ctx context.Context) error {
+	c.stopOnce.Do(func() { close(c.stop) })
+
+	select {
+	case <-c.exited:
+		return nil
+	case <-ctx.Done():
+	}
+
+	c.cancel()
+	<-c.exited
+
+	return ctx.Err()
+}
+
+// Done returns a channel which is closed once the Consumer has stopped, after
+// in was closed or Close was called.
+func (c *Consumer[T]) Done() <-chan struct{} {
+	return c.exited
+}
+
+func (c *Consumer[T]) run(in <-chan Msg[T]) {
+	defer close(c.exited)
+
+	c.receive(in)
+	if !c.d.opts.discardOnClose {
+		flushAndWait(c.ctx, c.d)
+	}
+	closeAndWait(c.d)
+
+	// Fail messages which were never processed, as processing was discarded,
+	// or given up on by Close.
+	c.mux.Lock()
+	msgs := c.pending
+	c.pending = nil
+	c.mux.Unlock()
+	complete(msgs, ErrClosed)
+}
+
+// receive adds messages received from in to the pending messages, until in is
+// closed or Close is called.
+func (c *Consumer[T]) receive(in <-chan Msg[T]) {
+	for {
+		select {
+		case <-c.stop:
+			return
+		case msg, ok := <-in:
+			if !ok {
+				return
+			}
+
+			c.mux.Lock()
+			c.pending = append(c.pending, msg)
+			c.mux.Unlock()
+			c.d.Debounce()
+		}
+	}
+}
+
+// invoke processes the latest pending message, and completes all pending
+// messages with the result. Messages received while processing is running are
+// left for the next invocation.
+func (c *Consumer[T]) invoke() {
+	c.mux.Lock()
+	msgs := c.pending
+	c.pending = nil
+	c.mux.Unlock()
+
+	if len(msgs) == 0 {
+		return
+	}
+	if c.ctx.Err() != nil {
+		complete(msgs, ErrClosed)
+
+		return
+	}
+
+	err := c.process(c.ctx, msgs[len(msgs)-1].Value)
+	if err != nil && c.d.opts.onError != nil {
+		c.d.opts.onError(err)
+	}
+	complete(msgs, err)
+}
+
+// complete calls the Done function of each message with err.
+func complete[T any](msgs []Msg[T], err error) {
+	for _, msg := range msgs {
+		if msg.Done != nil {
+			msg.Done(err)
+		}
+	}
+}
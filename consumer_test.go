@@ -0,0 +1,174 @@
+package debounce
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// msgRecorder records the errors each message is completed with.
+type msgRecorder struct {
+	mux  sync.Mutex
+	errs map[int][]error
+}
+
+func (r *msgRecorder) Msg(value int) Msg[int] {
+	return Msg[int]{Value: value, Done: func(err error) {
+		r.mux.Lock()
+		defer r.mux.Unlock()
+
+		if r.errs == nil {
+			r.errs = map[int][]error{}
+		}
+		r.errs[value] = append(r.errs[value], err)
+	}}
+}
+
+// Errs returns the errors each message was completed with.
+func (r *msgRecorder) Errs() map[int][]error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	errs := map[int][]error{}
+	for value, e := range r.errs {
+		errs[value] = append([]error(nil), e...)
+	}
+
+	return errs
+}
+
+func TestNewConsumer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("coalesces messages", func(t *testing.T) {
+		t.Parallel()
+
+		errProcess := errors.New("process failed")
+		mux := sync.Mutex{}
+		var processed []int
+		in := make(chan Msg[int])
+		r := &msgRecorder{}
+		c := NewConsumer(in, 20*time.Millisecond,
+			func(_ context.Context, latest int) error {
+				mux.Lock()
+				defer mux.Unlock()
+				processed = append(processed, latest)
+				if latest == 5 {
+					return errProcess
+				}
+
+				return nil
+			},
+		)
+
+		for i := 1; i <= 3; i++ {
+			in <- r.Msg(i)
+			time.Sleep(5 * time.Millisecond)
+		}
+		time.Sleep(40 * time.Millisecond)
+		assert.Equal(t, map[int][]error{1: {nil}, 2: {nil}, 3: {nil}}, r.Errs())
+
+		in <- r.Msg(4)
+		in <- r.Msg(5)
+		time.Sleep(40 * time.Millisecond)
+		close(in)
+		<-c.Done()
+
+		mux.Lock()
+		defer mux.Unlock()
+		assert.Equal(t, []int{3, 5}, processed)
+		assert.Equal(t, map[int][]error{
+			1: {nil}, 2: {nil}, 3: {nil}, 4: {errProcess}, 5: {errProcess},
+		}, r.Errs())
+		assert.NoError(t, c.Close(context.Background()))
+	})
+
+	t.Run("input closed", func(t *testing.T) {
+		t.Parallel()
+
+		in := make(chan Msg[int], 3)
+		r := &msgRecorder{}
+		latest := make(chan int, 1)
+		c := NewConsumer(in, time.Hour,
+			func(_ context.Context, value int) error {
+				latest <- value
+
+				return nil
+			},
+		)
+
+		for i := 1; i <= 3; i++ {
+			in <- r.Msg(i)
+		}
+		close(in)
+
+		select {
+		case <-c.Done():
+		case <-time.After(time.Second):
+			require.Fail(t, "consumer did not stop")
+		}
+		assert.Equal(t, 3, <-latest)
+		assert.Equal(t, map[int][]error{1: {nil}, 2: {nil}, 3: {nil}}, r.Errs())
+	})
+
+	t.Run("discard on close", func(t *testing.T) {
+		t.Parallel()
+
+		in := make(chan Msg[int])
+		r := &msgRecorder{}
+		c := NewConsumer(in, time.Hour,
+			func(context.Context, int) error {
+				assert.Fail(t, "unexpected processing")
+
+				return nil
+			},
+			WithDiscardOnClose(),
+		)
+
+		in <- r.Msg(1)
+		in <- r.Msg(2)
+		require.NoError(t, c.Close(context.Background()))
+
+		assert.Equal(t, map[int][]error{
+			1: {ErrClosed}, 2: {ErrClosed},
+		}, r.Errs())
+	})
+
+	t.Run("close timeout", func(t *testing.T) {
+		t.Parallel()
+
+		in := make(chan Msg[int])
+		r := &msgRecorder{}
+		started := make(chan struct{}, 1)
+		c := NewConsumer(in, 5*time.Millisecond,
+			func(ctx context.Context, _ int) error {
+				started <- struct{}{}
+				<-ctx.Done()
+
+				return ctx.Err()
+			},
+		)
+
+		in <- r.Msg(1)
+		<-started
+		in <- r.Msg(2)
+
+		ctx, cancel := context.WithTimeout(
+			context.Background(), 20*time.Millisecond,
+		)
+		defer cancel()
+		assert.ErrorIs(t, c.Close(ctx), context.DeadlineExceeded)
+
+		// The running processing was canceled, and the message received
+		// meanwhile was never processed.
+		assert.Equal(t, map[int][]error{
+			1: {context.Canceled}, 2: {ErrClosed},
+		}, r.Errs())
+		assert.NoError(t, c.Close(context.Background()))
+	})
+}
@@ -0,0 +1,23 @@
+package debounce
+
+import "time"
+
+// NewCounted returns a debounced function like New, but f receives the number
+// of calls coalesced into each invocation. Calls let through right away with
+// WithBurstPassThrough are each passed a count of 1.
+//
+// The returned cancel function can be used to cancel any pending invocation of
+// f, discarding the calls counted for it, but is not required to be called, so
+// can be ignored if not needed.
+//
+// Both debounced and cancel functions are safe for concurrent use in
+// goroutines, and can both be called multiple times.
+func NewCounted(
+	wait time.Duration,
+	f func(n int),
+	opts ...Option,
+) (debounced func(), cancel func()) {
+	d := newReduce(wait, func(acc, next int) int { return acc + next }, f, opts)
+
+	return func() { d.add(1) }, d.cancelFunc()
+}
@@ -0,0 +1,159 @@
+package debounce
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCounted(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		wait       time.Duration
+		opts       []Option
+		calls      []testOp
+		wantCounts map[time.Duration][]int
+	}{
+		{
+			name: "many calls, one cancel, two triggers",
+			wait: 20 * time.Millisecond,
+			calls: []testOp{
+				{delay: 5 * time.Millisecond},
+				{delay: 5 * time.Millisecond},
+				{delay: 10 * time.Millisecond}, // trigger 1
+				{delay: 35 * time.Millisecond},
+				{delay: 40 * time.Millisecond},
+				{delay: 50 * time.Millisecond, cancel: true},
+				{delay: 80 * time.Millisecond},
+				{delay: 90 * time.Millisecond},
+				{delay: 100 * time.Millisecond}, // trigger 2
+			},
+			wantCounts: map[time.Duration][]int{
+				25 * time.Millisecond: nil,
+				// from call at 10ms (+20ms wait = 30ms)
+				35 * time.Millisecond:  {3},
+				115 * time.Millisecond: {3},
+				// call at 100ms (+20ms wait = 120ms)
+				125 * time.Millisecond: {3, 3},
+				150 * time.Millisecond: {3, 3},
+			},
+		},
+		{
+			name: "leading and trailing",
+			wait: 20 * time.Millisecond,
+			opts: []Option{WithBurstPassThrough(1)},
+			calls: []testOp{
+				{delay: 10 * time.Millisecond}, // leading
+				{delay: 15 * time.Millisecond},
+				{delay: 15 * time.Millisecond},
+				{delay: 20 * time.Millisecond}, // trailing
+			},
+			wantCounts: map[time.Duration][]int{
+				5 * time.Millisecond:  nil,
+				15 * time.Millisecond: {1},
+				35 * time.Millisecond: {1},
+				// from call at 20ms (+20ms wait = 40ms)
+				45 * time.Millisecond:  {1, 3},
+				150 * time.Millisecond: {1, 3},
+			},
+		},
+		{
+			name: "until right before maxWait",
+			wait: 20 * time.Millisecond,
+			opts: []Option{WithMaxWait(50 * time.Millisecond)},
+			calls: []testOp{
+				{delay: 0 * time.Millisecond},
+				{delay: 10 * time.Millisecond},
+				{delay: 20 * time.Millisecond},
+				{delay: 30 * time.Millisecond},
+				{delay: 40 * time.Millisecond},
+				{delay: 60 * time.Millisecond},
+			},
+			wantCounts: map[time.Duration][]int{
+				45 * time.Millisecond: nil,
+				// tick over at 50ms via maxWait
+				55 * time.Millisecond: {5},
+				// from call at 60ms (+20ms wait = 80ms)
+				85 * time.Millisecond:  {5, 1},
+				150 * time.Millisecond: {5, 1},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mux := sync.RWMutex{}
+
+			var got []int
+			d, c := NewCounted(tt.wait, func(n int) {
+				mux.Lock()
+				defer mux.Unlock()
+				got = append(got, n)
+			}, tt.opts...)
+
+			wg := sync.WaitGroup{}
+			for _, op := range tt.calls {
+				wg.Add(1)
+				go func(delay time.Duration, cancel bool) {
+					defer wg.Done()
+					time.Sleep(delay)
+					if cancel {
+						c()
+					} else {
+						d()
+					}
+				}(op.delay, op.cancel)
+			}
+
+			for delay, counts := range tt.wantCounts {
+				wg.Add(1)
+				go func(interval time.Duration, counts []int) {
+					defer wg.Done()
+					time.Sleep(interval)
+
+					mux.RLock()
+					defer mux.RUnlock()
+					assert.Equal(t, counts, got, "at %s", interval)
+				}(delay, counts)
+			}
+
+			wg.Wait()
+		})
+	}
+}
+
+func TestNewCounted_concurrent(t *testing.T) {
+	t.Parallel()
+
+	mux := sync.Mutex{}
+	total := 0
+	d, _ := NewCounted(time.Millisecond, func(n int) {
+		mux.Lock()
+		defer mux.Unlock()
+		total += n
+	}, WithMaxWait(2*time.Millisecond))
+
+	wg := sync.WaitGroup{}
+	for w := 0; w < 10; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				d()
+			}
+		}()
+	}
+	wg.Wait()
+
+	time.Sleep(20 * time.Millisecond)
+
+	mux.Lock()
+	defer mux.Unlock()
+	assert.Equal(t, 1000, total)
+}
@@ -8,7 +8,7 @@
 package debounce
 
 import (
-	"sync"
+	"context"
 	"time"
 )
 
@@ -23,26 +23,19 @@ import (
 //
 // The debounced function does not wait for f to complete, so f needs to be
 // thread-safe as it may be invoked again before the previous invocation
-// completes.
-func New(wait time.Duration, f func()) (debounced func(), cancel func()) {
-	var mux sync.Mutex
-	timer := stoppedTimer(f)
-
-	debounced = func() {
-		mux.Lock()
-		defer mux.Unlock()
-
-		timer.Reset(wait)
-	}
-
-	cancel = func() {
-		mux.Lock()
-		defer mux.Unlock()
-
-		timer.Stop()
-	}
+// completes. The exception is a wait time of zero or less combined with
+// WithZeroWaitSync, in which case the debounced function invokes f
+// synchronously.
+//
+// Optional behavior can be configured by passing one or more Option values.
+func New(
+	wait time.Duration,
+	f func(),
+	opts ...Option,
+) (debounced func(), cancel func()) {
+	d := NewDebouncer(wait, f, opts...)
 
-	return debounced, cancel
+	return d.Debounce, d.cancelFunc()
 }
 
 // NewWithMaxWait returns a debounced function like New, but with a maximum wait
@@ -57,53 +50,152 @@ func New(wait time.Duration, f func()) (debounced func(), cancel func()) {
 //
 // The debounced function does not wait for f to complete, so f needs to be
 // thread-safe as it may be invoked again before the previous invocation
-// completes.
+// completes. As with New, the exception is a wait time of zero or less
+// combined with WithZeroWaitSync.
+//
+// Optional behavior can be configured by passing one or more Option values.
 func NewWithMaxWait(
 	wait, maxWait time.Duration,
 	f func(),
+	opts ...Option,
 ) (debounced func(), cancel func()) {
-	var mux sync.Mutex
-	var dirty bool
-	var timer *time.Timer
-	var maxTimer *time.Timer
+	d := NewDebouncer(wait, f, opts...).withMaxWait(maxWait)
 
-	cb := func() {
-		mux.Lock()
-		defer mux.Unlock()
+	return d.Debounce, d.cancelFunc()
+}
 
-		if !dirty {
-			return
+// NewWithError returns a debounced function like New, but for a function f
+// which can fail. Each non-nil error returned by f is passed to the hook set
+// with WithOnError, or discarded if no hook is set.
+//
+// The returned cancel function can be used to cancel any pending invocation of
+// f, but is not required to be called, so can be ignored if not needed.
+//
+// Both debounced and cancel functions are safe for concurrent use in
+// goroutines, and can both be called multiple times.
+//
+// Optional behavior can be configured by passing one or more Option values.
+func NewWithError(
+	wait time.Duration,
+	f func() error,
+	opts ...Option,
+) (debounced func(), cancel func()) {
+	var d *Debouncer
+	d = newDebouncer(wait, func(context.Context, InvokeInfo) {
+		if err := f(); err != nil && d.opts.onError != nil {
+			d.opts.onError(err)
 		}
+	}, opts)
 
-		go f()
-		timer.Stop()
-		maxTimer.Stop()
-		dirty = false
-	}
+	return d.Debounce, d.cancelFunc()
+}
 
-	timer = stoppedTimer(cb)
-	maxTimer = stoppedTimer(cb)
+// NewContext returns a debounced function like New, but f receives a context
+// for each invocation, which is canceled when a newer invocation starts, when
+// the returned cancel function is called, or once the invocation completes.
+// This allows a slow invocation to stop early once its work has been
+// superseded.
+//
+// The returned cancel function cancels any pending invocation of f, along with
+// the context of a running one. It is not required to be called, so can be
+// ignored if not needed.
+//
+// Both debounced and cancel functions are safe for concurrent use in
+// goroutines, and can both be called multiple times.
+//
+// Optional behavior can be configured by passing one or more Option values.
+func NewContext(
+	wait time.Duration,
+	f func(ctx context.Context),
+	opts ...Option,
+) (debounced func(), cancel func()) {
+	d := NewDebouncerCtx(wait, f, opts...)
+	d.supersede = true
 
-	debounced = func() {
-		mux.Lock()
-		defer mux.Unlock()
+	return d.Debounce, d.cancelFunc()
+}
 
-		timer.Reset(wait)
+// NewEdgeFuncs returns a debounced function like New, but which calls leading
+// on the leading edge of a burst of calls, and trailing on its trailing edge.
+//
+// The first call of a burst calls leading right away, as with
+// WithBurstPassThrough(1). Further calls within the wait time call trailing
+// once wait time has elapsed since the last of them, or once the maximum wait
+// time set with WithMaxWait has elapsed. A burst with a single call therefore
+// only calls leading.
+//
+// Passing nil for leading disables the leading edge, so every burst calls
+// trailing as New would. Passing nil for trailing disables the trailing edge,
+// so only the first call of each burst has any effect.
+//
+// The returned cancel function can be used to cancel any pending call of
+// trailing, but is not required to be called, so can be ignored if not needed.
+//
+// Both debounced and cancel functions are safe for concurrent use in
+// goroutines, and can both be called multiple times.
+//
+// Optional behavior can be configured by passing one or more Option values.
+func NewEdgeFuncs(
+	wait time.Duration,
+	leading, trailing func(),
+	opts ...Option,
+) (debounced func(), cancel func()) {
+	if leading != nil {
+		opts = append([]Option{WithBurstPassThrough(1)}, opts...)
+	}
 
-		// Mark as dirty, and start maxTimer if we were not already dirty.
-		if !dirty {
-			dirty = true
-			maxTimer.Reset(maxWait)
+	d := newDebouncer(wait, func(_ context.Context, info InvokeInfo) {
+		if info.Reason == InvokeImmediate && leading != nil {
+			leading()
+
+			return
+		}
+		if trailing != nil {
+			trailing()
 		}
+	}, opts)
+
+	return d.Debounce, d.cancelFunc()
+}
+
+// NewTwoStage returns a debounced function like New, but which calls preview
+// once shortWait has elapsed since the last call, and then final once longWait
+// has elapsed since the last call, for example to show a cheap preview as soon
+// as calls pause, and to do expensive work once they have stopped. A call made
+// before final is called restarts both stages, even when preview has already
+// been called.
+//
+// Options apply to both stages, as if preview and final each had a debounced
+// function of their own.
+//
+// The returned cancel function can be used to cancel any pending call of
+// preview and final, but is not required to be called, so can be ignored if
+// not needed.
+//
+// Both debounced and cancel functions are safe for concurrent use in
+// goroutines, and can both be called multiple times.
+//
+// NewTwoStage panics if shortWait is not less than longWait.
+func NewTwoStage(
+	shortWait, longWait time.Duration,
+	preview, final func(),
+	opts ...Option,
+) (debounced func(), cancel func()) {
+	if shortWait >= longWait {
+		panic("debounce: invalid two-stage waits")
 	}
 
-	cancel = func() {
-		mux.Lock()
-		defer mux.Unlock()
+	p := NewDebouncer(shortWait, preview, opts...)
+	f := NewDebouncer(longWait, final, opts...)
 
-		timer.Stop()
-		maxTimer.Stop()
-		dirty = false
+	debounced = func() {
+		p.Debounce()
+		f.Debounce()
+	}
+	cancelPreview, cancelFinal := p.cancelFunc(), f.cancelFunc()
+	cancel = func() {
+		cancelPreview()
+		cancelFinal()
 	}
 
 	return debounced, cancel
@@ -1,14 +1,18 @@
 package debounce
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var maxRetries = flag.Int("max-retries", 0, "Maximum number of retries")
@@ -242,6 +246,26 @@ func TestNewWithMaxWait(t *testing.T) {
 				100 * time.Millisecond: 1,
 			},
 		},
+		{
+			name:    "until right before maxWait, wait much longer",
+			wait:    500 * time.Millisecond,
+			maxwait: 50 * time.Millisecond,
+			calls: []testOp{
+				{delay: 0 * time.Millisecond},
+				{delay: 10 * time.Millisecond},
+				{delay: 20 * time.Millisecond},
+				{delay: 30 * time.Millisecond},
+				{delay: 40 * time.Millisecond},
+			},
+			wantTriggers: map[time.Duration]int{
+				45 * time.Millisecond: 0,
+				// tick over at 50ms via maxWait, without waiting for the
+				// wait time to elapse after the last call at 40ms
+				55 * time.Millisecond: 1,
+				// still 1 at at the end
+				100 * time.Millisecond: 1,
+			},
+		},
 		{
 			name:    "until right after maxWait",
 			wait:    20 * time.Millisecond,
@@ -265,6 +289,31 @@ func TestNewWithMaxWait(t *testing.T) {
 				150 * time.Millisecond: 2,
 			},
 		},
+		{
+			name:    "pause right after maxWait, wait longer than maxWait",
+			wait:    100 * time.Millisecond,
+			maxwait: 50 * time.Millisecond,
+			calls: []testOp{
+				{delay: 0 * time.Millisecond},
+				{delay: 10 * time.Millisecond},
+				{delay: 20 * time.Millisecond},
+				{delay: 30 * time.Millisecond},
+				{delay: 40 * time.Millisecond},
+				// maxWait triggers at 50ms (0ms + 50ms)
+				{delay: 60 * time.Millisecond},
+			},
+			wantTriggers: map[time.Duration]int{
+				45 * time.Millisecond: 0,
+				// tick over at 50ms via maxWait, without waiting for another
+				// call after the boundary
+				57 * time.Millisecond:  1,
+				105 * time.Millisecond: 1,
+				// tick over at 110ms via maxWait (60ms + 50ms)
+				120 * time.Millisecond: 2,
+				// still 2 at the end
+				200 * time.Millisecond: 2,
+			},
+		},
 		{
 			name:    "until two maxWaits and one wait exipry",
 			wait:    20 * time.Millisecond,
@@ -393,3 +442,497 @@ func TestNewWithMaxWait(t *testing.T) {
 		})
 	}
 }
+
+func TestNewWithMaxWait_timerBackends(t *testing.T) {
+	t.Parallel()
+
+	for _, backend := range timerBackends {
+		backend := backend
+		t.Run(backend.name, func(t *testing.T) {
+			t.Parallel()
+
+			calls := make(chan struct{}, 10)
+			invoked := func() {
+				select {
+				case <-calls:
+				case <-time.After(time.Second):
+					require.FailNow(t, "not invoked")
+				}
+			}
+
+			// The wait time never elapses, so invocations are only made via
+			// the maximum wait time.
+			d, c := NewWithMaxWait(time.Minute, 20*time.Millisecond, func() {
+				calls <- struct{}{}
+			}, backend.opts...)
+			defer c()
+
+			for i := 0; i < 5; i++ {
+				d()
+			}
+			invoked()
+
+			// The maximum wait time restarts with the next burst.
+			d()
+			invoked()
+
+			// Neither timer fires once canceled.
+			d()
+			c()
+			d()
+			invoked()
+			assert.Len(t, calls, 0)
+
+			// The maximum wait time never elapses, so invocations are only
+			// made via the wait time.
+			d, c = NewWithMaxWait(10*time.Millisecond, time.Minute, func() {
+				calls <- struct{}{}
+			}, backend.opts...)
+			defer c()
+
+			d()
+			d()
+			invoked()
+			assert.Len(t, calls, 0)
+		})
+	}
+}
+
+func TestNewWithError(t *testing.T) {
+	t.Parallel()
+
+	t.Run("errors per failing invocation", func(t *testing.T) {
+		t.Parallel()
+
+		var n int64
+		errs := make(chan error, 10)
+		d, _ := NewWithError(5*time.Millisecond, func() error {
+			if i := atomic.AddInt64(&n, 1); i%2 == 1 {
+				return fmt.Errorf("invocation %d", i)
+			}
+
+			return nil
+		}, WithOnError(func(err error) { errs <- err }))
+
+		for i := 0; i < 3; i++ {
+			d()
+			time.Sleep(20 * time.Millisecond)
+		}
+
+		assert.Equal(t, int64(3), atomic.LoadInt64(&n))
+		require.Len(t, errs, 2)
+		assert.EqualError(t, <-errs, "invocation 1")
+		assert.EqualError(t, <-errs, "invocation 3")
+	})
+
+	t.Run("blocking hook", func(t *testing.T) {
+		t.Parallel()
+
+		var n int64
+		release := make(chan struct{})
+		d, _ := NewWithError(5*time.Millisecond, func() error {
+			atomic.AddInt64(&n, 1)
+
+			return errors.New("failed")
+		}, WithOnError(func(error) { <-release }))
+		defer close(release)
+
+		for i := 0; i < 3; i++ {
+			d()
+			time.Sleep(20 * time.Millisecond)
+		}
+
+		assert.Equal(t, int64(3), atomic.LoadInt64(&n))
+	})
+
+	t.Run("without hook", func(t *testing.T) {
+		t.Parallel()
+
+		var n int64
+		d, _ := NewWithError(5*time.Millisecond, func() error {
+			atomic.AddInt64(&n, 1)
+
+			return errors.New("failed")
+		})
+
+		d()
+		time.Sleep(20 * time.Millisecond)
+
+		assert.Equal(t, int64(1), atomic.LoadInt64(&n))
+	})
+}
+
+func TestNewContext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("superseded", func(t *testing.T) {
+		t.Parallel()
+
+		var n int64
+		first := make(chan error, 1)
+		second := make(chan error, 1)
+		d, _ := NewContext(5*time.Millisecond, func(ctx context.Context) {
+			if atomic.AddInt64(&n, 1) > 1 {
+				second <- ctx.Err()
+
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Second):
+			}
+			first <- ctx.Err()
+		})
+
+		d()
+		time.Sleep(20 * time.Millisecond)
+		d()
+
+		select {
+		case err := <-first:
+			assert.ErrorIs(t, err, context.Canceled)
+		case <-time.After(500 * time.Millisecond):
+			require.FailNow(t, "first invocation was not canceled")
+		}
+		assert.NoError(t, <-second)
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		t.Parallel()
+
+		errs := make(chan error, 1)
+		d, c := NewContext(5*time.Millisecond, func(ctx context.Context) {
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Second):
+			}
+			errs <- ctx.Err()
+		})
+
+		d()
+		time.Sleep(20 * time.Millisecond)
+		c()
+
+		select {
+		case err := <-errs:
+			assert.ErrorIs(t, err, context.Canceled)
+		case <-time.After(500 * time.Millisecond):
+			require.FailNow(t, "invocation was not canceled")
+		}
+	})
+}
+
+func TestNewEdgeFuncs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		wait         time.Duration
+		noLeading    bool
+		noTrailing   bool
+		calls        []testOp
+		wantTriggers map[time.Duration]string
+	}{
+		{
+			name: "one call",
+			wait: 20 * time.Millisecond,
+			calls: []testOp{
+				{delay: 10 * time.Millisecond},
+			},
+			wantTriggers: map[time.Duration]string{
+				5 * time.Millisecond:   "",
+				15 * time.Millisecond:  "L",
+				150 * time.Millisecond: "L",
+			},
+		},
+		{
+			name: "burst",
+			wait: 20 * time.Millisecond,
+			calls: []testOp{
+				{delay: 10 * time.Millisecond}, // leading
+				{delay: 15 * time.Millisecond},
+				{delay: 20 * time.Millisecond}, // trailing
+				{delay: 70 * time.Millisecond}, // leading
+			},
+			wantTriggers: map[time.Duration]string{
+				15 * time.Millisecond: "L",
+				35 * time.Millisecond: "L",
+				// from call at 20ms (+20ms wait = 40ms)
+				45 * time.Millisecond:  "LT",
+				75 * time.Millisecond:  "LTL",
+				150 * time.Millisecond: "LTL",
+			},
+		},
+		{
+			name: "cancel",
+			wait: 20 * time.Millisecond,
+			calls: []testOp{
+				{delay: 10 * time.Millisecond}, // leading
+				{delay: 15 * time.Millisecond},
+				{delay: 20 * time.Millisecond, cancel: true},
+			},
+			wantTriggers: map[time.Duration]string{
+				15 * time.Millisecond:  "L",
+				150 * time.Millisecond: "L",
+			},
+		},
+		{
+			name:      "no leading",
+			wait:      20 * time.Millisecond,
+			noLeading: true,
+			calls: []testOp{
+				{delay: 10 * time.Millisecond},
+				{delay: 15 * time.Millisecond},
+			},
+			wantTriggers: map[time.Duration]string{
+				15 * time.Millisecond: "",
+				// from call at 15ms (+20ms wait = 35ms)
+				40 * time.Millisecond:  "T",
+				150 * time.Millisecond: "T",
+			},
+		},
+		{
+			name:       "no trailing",
+			wait:       20 * time.Millisecond,
+			noTrailing: true,
+			calls: []testOp{
+				{delay: 10 * time.Millisecond},
+				{delay: 15 * time.Millisecond},
+				{delay: 70 * time.Millisecond},
+			},
+			wantTriggers: map[time.Duration]string{
+				15 * time.Millisecond:  "L",
+				45 * time.Millisecond:  "L",
+				75 * time.Millisecond:  "LL",
+				150 * time.Millisecond: "LL",
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mux := sync.RWMutex{}
+
+			got := ""
+			record := func(edge string) func() {
+				return func() {
+					mux.Lock()
+					defer mux.Unlock()
+					got += edge
+				}
+			}
+			leading, trailing := record("L"), record("T")
+			if tt.noLeading {
+				leading = nil
+			}
+			if tt.noTrailing {
+				trailing = nil
+			}
+
+			d, c := NewEdgeFuncs(tt.wait, leading, trailing)
+
+			wg := sync.WaitGroup{}
+			for _, op := range tt.calls {
+				wg.Add(1)
+				go func(delay time.Duration, cancel bool) {
+					defer wg.Done()
+					time.Sleep(delay)
+					if cancel {
+						c()
+					} else {
+						d()
+					}
+				}(op.delay, op.cancel)
+			}
+
+			for delay, want := range tt.wantTriggers {
+				wg.Add(1)
+				go func(interval time.Duration, want string) {
+					defer wg.Done()
+					time.Sleep(interval)
+
+					mux.RLock()
+					defer mux.RUnlock()
+					assert.Equal(t, want, got, "at %s", interval)
+				}(delay, want)
+			}
+
+			wg.Wait()
+		})
+	}
+}
+
+func TestNewEdgeFuncs_maxWait(t *testing.T) {
+	t.Parallel()
+
+	edges := make(chan string, 10)
+	next := func() string {
+		select {
+		case edge := <-edges:
+			return edge
+		case <-time.After(time.Second):
+			require.FailNow(t, "no edge called")
+
+			return ""
+		}
+	}
+
+	// The wait time never elapses, so the trailing edge is only reached via
+	// the maximum wait time.
+	d, cancel := NewEdgeFuncs(time.Minute,
+		func() { edges <- "L" }, func() { edges <- "T" },
+		WithMaxWait(20*time.Millisecond),
+	)
+	defer cancel()
+
+	d()
+	assert.Equal(t, "L", next())
+	d()
+	d()
+	assert.Equal(t, "T", next())
+
+	// The burst is still going on, so the next call is not a leading edge.
+	d()
+	assert.Equal(t, "T", next())
+	assert.Len(t, edges, 0)
+}
+
+func TestNewTwoStage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		calls        []testOp
+		wantTriggers map[time.Duration]string
+	}{
+		{
+			name: "single burst",
+			calls: []testOp{
+				{delay: 0 * time.Millisecond},
+				{delay: 5 * time.Millisecond},
+			},
+			wantTriggers: map[time.Duration]string{
+				10 * time.Millisecond: "",
+				// from call at 5ms (+10ms short wait = 15ms)
+				20 * time.Millisecond: "P",
+				// from call at 5ms (+40ms long wait = 45ms)
+				40 * time.Millisecond:  "P",
+				50 * time.Millisecond:  "PF",
+				150 * time.Millisecond: "PF",
+			},
+		},
+		{
+			name: "interrupted final",
+			calls: []testOp{
+				{delay: 0 * time.Millisecond},
+				// after preview at 10ms, before final at 40ms
+				{delay: 25 * time.Millisecond},
+			},
+			wantTriggers: map[time.Duration]string{
+				15 * time.Millisecond: "P",
+				// from call at 25ms (+10ms short wait = 35ms)
+				40 * time.Millisecond: "PP",
+				// final from call at 0ms does not happen at 40ms
+				50 * time.Millisecond: "PP",
+				// from call at 25ms (+40ms long wait = 65ms)
+				70 * time.Millisecond:  "PPF",
+				150 * time.Millisecond: "PPF",
+			},
+		},
+		{
+			name: "back to back bursts",
+			calls: []testOp{
+				{delay: 0 * time.Millisecond},
+				{delay: 5 * time.Millisecond},
+				{delay: 60 * time.Millisecond},
+				{delay: 65 * time.Millisecond},
+			},
+			wantTriggers: map[time.Duration]string{
+				20 * time.Millisecond:  "P",
+				50 * time.Millisecond:  "PF",
+				80 * time.Millisecond:  "PFP",
+				110 * time.Millisecond: "PFPF",
+				150 * time.Millisecond: "PFPF",
+			},
+		},
+		{
+			name: "cancel",
+			calls: []testOp{
+				{delay: 0 * time.Millisecond},
+				{delay: 20 * time.Millisecond, cancel: true},
+			},
+			wantTriggers: map[time.Duration]string{
+				15 * time.Millisecond:  "P",
+				150 * time.Millisecond: "P",
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mux := sync.RWMutex{}
+
+			got := ""
+			record := func(stage string) func() {
+				return func() {
+					mux.Lock()
+					defer mux.Unlock()
+					got += stage
+				}
+			}
+
+			d, c := NewTwoStage(
+				10*time.Millisecond, 40*time.Millisecond,
+				record("P"), record("F"),
+			)
+
+			wg := sync.WaitGroup{}
+			for _, op := range tt.calls {
+				wg.Add(1)
+				go func(delay time.Duration, cancel bool) {
+					defer wg.Done()
+					time.Sleep(delay)
+					if cancel {
+						c()
+					} else {
+						d()
+					}
+				}(op.delay, op.cancel)
+			}
+
+			for delay, want := range tt.wantTriggers {
+				wg.Add(1)
+				go func(interval time.Duration, want string) {
+					defer wg.Done()
+					time.Sleep(interval)
+
+					mux.RLock()
+					defer mux.RUnlock()
+					assert.Equal(t, want, got, "at %s", interval)
+				}(delay, want)
+			}
+
+			wg.Wait()
+		})
+	}
+}
+
+func TestNewTwoStage_InvalidWaits(t *testing.T) {
+	t.Parallel()
+
+	assert.Panics(t, func() {
+		NewTwoStage(40*time.Millisecond, 10*time.Millisecond,
+			func() {}, func() {},
+		)
+	})
+	assert.Panics(t, func() {
+		NewTwoStage(10*time.Millisecond, 10*time.Millisecond,
+			func() {}, func() {},
+		)
+	})
+}
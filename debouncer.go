@@ -0,0 +1,1468 @@
+package debounce
+
+import (
+	"context"
+	"math/rand"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Debouncer debounces calls to its Debounce method, invoking a callback
+// function once calls have stopped for the wait time. It is the type behind
+// the functions returned by New and NewWithMaxWait, and offers additional
+// control over its lifecycle.
+//
+// All methods of a Debouncer are safe for concurrent use in goroutines.
+type Debouncer struct {
+	mux      sync.Mutex
+	f        func(ctx context.Context, info InvokeInfo)
+	opts     *options
+	wait     time.Duration
+	maxWait  time.Duration
+	timer    *guardedTimer
+	maxTimer *guardedTimer
+	rand     *rand.Rand
+
+	// combine combines the values passed to calls coalesced into a single
+	// invocation. It defaults to combineCallbacks, and is replaced by the
+	// generic debounced functions, like NewTyped.
+	combine func(acc, next interface{}) interface{}
+	// same reports if a call's value is the same as the combined value of the
+	// pending burst, in which case the call is ignored. It is only set for
+	// NewDedupe and NewDedupeFunc.
+	same func(pending, value interface{}) bool
+	// full reports if the combined value of a burst is complete, and should be
+	// passed to f right away. It is only set for NewWeighted, and for
+	// collecting debounced functions like NewBatch with WithMaxBatchSize.
+	full func(value interface{}) bool
+	// limit makes room for value in the pending value of a burst, as set with
+	// WithMaxPending, returning the pending value to keep, and the values
+	// dropped along with their number. It reports if value itself is to be
+	// dropped. It is only set for NewBatch with WithMaxPending.
+	limit func(pending, value interface{}) (
+		kept, dropped interface{}, n int, dropValue bool,
+	)
+	// onDrop calls the hook set with WithOnDrop for each of the dropped values
+	// returned by limit.
+	onDrop func(dropped interface{})
+	// deadlineTimer invokes f at the deadline set with WithDeadline or
+	// SetDeadline.
+	deadlineTimer *guardedTimer
+	// deferTimer invokes f once an invocation deferred by WithQuota or
+	// WithRateLimiter is allowed.
+	deferTimer *guardedTimer
+	// idleTimer calls the hook set with WithOnIdle.
+	idleTimer timer
+	// evictTimer evicts the Debouncer from its Group once it has been idle for
+	// the time set with WithGroupMaxIdle.
+	evictTimer timer
+	// onEvict removes the Debouncer from its Group, and is called by evict
+	// while holding the lock.
+	onEvict func()
+	// quotaTimes holds the times of the most recent invocations, up to the
+	// quota set with WithQuota.
+	quotaTimes []time.Time
+	// callTimes holds the times of the most recent calls, up to the number set
+	// with WithActivationRate.
+	callTimes []time.Time
+	// deferredReason is the reason of the invocation deferred by WithQuota or
+	// WithRateLimiter.
+	deferredReason InvokeReason
+	// reservedAt is the time from which the invocation reserved with the
+	// rate limiter set with WithRateLimiter is allowed.
+	reservedAt time.Time
+
+	// dirty is true while a burst of calls is pending invocation.
+	dirty bool
+	// burstWait is the wait time used for the current burst.
+	burstWait time.Duration
+	// burstMaxWait is the maximum wait time used for the current burst.
+	burstMaxWait time.Duration
+	// lastCall is the time of the last call to the debounced function.
+	lastCall time.Time
+	// timerExpiry is the time the wait timer was last set to expire at.
+	timerExpiry time.Time
+	// now returns the current time, and is only replaced in tests.
+	now func() time.Time
+	// backoffScale is the multiplier applied to the wait by WithBackoff.
+	backoffScale float64
+	// passThrough is the number of calls let through by WithBurstPassThrough
+	// since the debounced function was last idle.
+	passThrough int
+	// confirming is true while the confirmation window set with
+	// WithConfirmWindow runs, once the wait time of a burst has elapsed.
+	confirming bool
+	// running is the number of executions of f currently in progress.
+	running int
+	// queued is true if an execution is queued by WithSerializedExecution.
+	queued bool
+	// rearm is true if an invocation was dropped by WithDropIfRunning.
+	rearm bool
+	// closed is true once Close has been called.
+	closed bool
+	// drained is closed once no invocations are running, when Close waits for
+	// them due to WithCloseTimeout, or Wait is called.
+	drained chan struct{}
+	// gateQueue holds a channel for each caller waiting in Gate, in the order
+	// they called it. The channel of the first caller is closed.
+	gateQueue []chan struct{}
+	// gateAt is the time Gate last let a caller through.
+	gateAt time.Time
+	// evicted is true once the Debouncer has been evicted from its Group.
+	evicted bool
+	// throttle is true for a Debouncer created by NewThrottle, which uses its
+	// wait time as the throttle interval, and throttleTrailing if it was
+	// created with WithThrottleTrailing.
+	throttle         bool
+	throttleTrailing bool
+	// throttledUntil is the end of the current throttle interval.
+	throttledUntil time.Time
+	// adaptive is true for a Debouncer created by NewAdaptive, which uses its
+	// wait time and adaptMax as the bounds of the adaptive wait time, derived
+	// with the parameters set with WithAdaptiveWait.
+	adaptive       bool
+	adaptMax       time.Duration
+	adaptFactor    float64
+	adaptSmoothing float64
+	// gapAvg is the moving average of the time between calls, in nanoseconds,
+	// when adaptive is true. It is zero until the second call.
+	gapAvg float64
+	// stats holds the counters returned by Stats.
+	stats Stats
+	// worker runs invocations when WithWorker is used.
+	worker *worker
+	// invocations is the number of invocations which have been started or
+	// queued, as counted by WithAutoStop.
+	invocations int
+	// autoStopDone is true once the hook set with WithAutoStop has fired.
+	autoStopDone bool
+	// burst describes the calls of the pending burst.
+	burst InvokeInfo
+	// queuedInfo describes the calls of the invocation queued by
+	// WithSerializedExecution.
+	queuedInfo InvokeInfo
+	// lastCallers holds the call sites of the most recent invocation.
+	lastCallers []uintptr
+	// lastRan is the time the most recent invocation of f completed.
+	lastRan time.Time
+
+	// ctx is the parent context of all invocations, and is canceled by Close.
+	ctx       context.Context
+	ctxCancel context.CancelFunc
+	// supersede is true for a Debouncer created by NewContext, which cancels
+	// the context of an invocation when a newer one starts.
+	supersede bool
+	// invokeCancel cancels the context of the most recent invocation, when
+	// supersede is true and it is still running.
+	invokeCancel context.CancelFunc
+	// invokeGen is incremented each time an invocation starts, when supersede
+	// is true.
+	invokeGen uint64
+}
+
+// NewDebouncer returns a new Debouncer which invokes f once wait time has
+// elapsed since the last call to its Debounce method.
+//
+// Optional behavior can be configured by passing one or more Option values.
+func NewDebouncer(wait time.Duration, f func(), opts ...Option) *Debouncer {
+	return newDebouncer(wait, func(context.Context, InvokeInfo) { f() }, opts)
+}
+
+// NewDebouncerCtx returns a new Debouncer like NewDebouncer, but f receives a
+// context which is canceled when the Debouncer is closed, or when the timeout
+// set with WithInvokeTimeout expires.
+//
+// The Debouncer does not stop f when the context is canceled, it is up to f to
+// return early when it sees the context being done.
+func NewDebouncerCtx(
+	wait time.Duration,
+	f func(ctx context.Context),
+	opts ...Option,
+) *Debouncer {
+	return newDebouncer(
+		wait, func(ctx context.Context, _ InvokeInfo) { f(ctx) }, opts,
+	)
+}
+
+func newDebouncer(
+	wait time.Duration,
+	f func(ctx context.Context, info InvokeInfo),
+	opts []Option,
+) *Debouncer {
+	d := &Debouncer{
+		f:            f,
+		opts:         newOptions(opts),
+		wait:         wait,
+		backoffScale: 1,
+		now:          time.Now,
+		combine:      combineCallbacks,
+	}
+	d.ctx, d.ctxCancel = context.WithCancel(context.Background())
+
+	if d.opts.reschedule != nil {
+		r, f := rescheduler{d: d}, d.opts.reschedule
+		d.f = func(context.Context, InvokeInfo) { f(r) }
+	}
+	if d.opts.randSource != nil {
+		//nolint:gosec // Jitter does not need a cryptographic source.
+		d.rand = rand.New(d.opts.randSource)
+	}
+
+	d.timer = d.newFireTimer(InvokeWait)
+	d.deadlineTimer = d.newFireTimer(InvokeDeadline)
+	// The defer timer passes no reason, as deferred invocations keep the
+	// reason they were originally triggered for.
+	d.deferTimer = d.newFireTimer(0)
+	d.idleTimer = d.newTimer(d.idle)
+
+	if d.opts.worker {
+		d.worker = newWorker()
+		d.opts.executor = d.worker
+	}
+	// The deadline timer may fire right away, and takes the lock when it does.
+	d.mux.Lock()
+	d.setDeadline(d.opts.deadline)
+	d.mux.Unlock()
+
+	if d.opts.hasWait {
+		d.wait = d.opts.wait
+	}
+	if d.opts.maxWait > 0 {
+		d.withMaxWait(d.opts.maxWait)
+	}
+	if d.opts.registry != nil && !d.opts.registry.add(d) {
+		d.mux.Lock()
+		d.close()
+		d.mux.Unlock()
+	}
+
+	return d
+}
+
+// withMaxWait enables the maxWait timer, which invokes f when maxWait has
+// elapsed since the first call of a burst. The maximum wait time set with
+// WithMaxWait takes precedence over maxWait.
+func (d *Debouncer) withMaxWait(maxWait time.Duration) *Debouncer {
+	if d.opts.maxWait > 0 {
+		maxWait = d.opts.maxWait
+	}
+
+	d.maxWait = maxWait
+	if d.maxTimer == nil {
+		d.maxTimer = d.newFireTimer(InvokeMaxWait)
+	}
+
+	return d
+}
+
+// Debounce schedules an invocation of the callback function, postponing any
+// already pending invocation until wait time has elapsed since this call.
+//
+// Calling Debounce after Close has no effect.
+func (d *Debouncer) Debounce() {
+	d.add(nil)
+}
+
+// DebounceWith schedules an invocation like Debounce, but the pending
+// invocation calls f instead of the callback function, unless a later call
+// replaces it. It is the same as DebounceWithPriority with a priority of 0.
+//
+// Calling DebounceWith after Close has no effect.
+func (d *Debouncer) DebounceWith(f func()) {
+	d.add(callback{f: f})
+}
+
+// DebounceWithPriority schedules an invocation like DebounceWith, but f only
+// replaces the function of the pending invocation if priority is greater than
+// or equal to the priority it was passed with. Calls to Debounce and
+// DebounceWith have a priority of 0, and a nil f stands for the callback
+// function. The pending priority is reset after each invocation.
+//
+// Calling DebounceWithPriority after Close has no effect.
+func (d *Debouncer) DebounceWithPriority(f func(), priority int) {
+	d.add(callback{f: f, priority: priority})
+}
+
+// DebounceCtx schedules an invocation of the callback function like Debounce,
+// for a call made on behalf of a caller with the given context. The context
+// passed to the callback function carries the earliest deadline of the
+// contexts of all calls coalesced into the invocation, so the invocation
+// respects the strictest deadline of its callers. The context of the call is
+// not used otherwise, and canceling it does not cancel the invocation.
+//
+// The deadlines of contexts which are already done when the invocation starts
+// still apply, unless WithIgnoreExpiredDeadlines is used. With
+// WithDropCanceledCalls, calls whose context is done when the invocation
+// starts are dropped from it instead, and the invocation is skipped if no
+// other calls are left.
+//
+// Calling DebounceCtx after Close has no effect.
+func (d *Debouncer) DebounceCtx(ctx context.Context) {
+	d.submit(d.callerPC(0), nil, nil, ctx)
+}
+
+// DebounceDone schedules an invocation of the callback function like
+// Debounce, and returns a Promise which completes once the invocation covering
+// this call has completed.
+//
+// Calling DebounceDone after Close returns a Promise which has already
+// completed with ErrCanceled.
+func (d *Debouncer) DebounceDone() *Promise {
+	p := newPromise()
+	d.submit(d.callerPC(0), nil, p, nil)
+
+	return p
+}
+
+// add records a call passing value, and executes the callback function if it
+// is due right away. It must be called directly by the exported function
+// called by users, so call sites are captured correctly.
+//
+// It reports false if the Debouncer has been evicted from its Group, in which
+// case the call has no effect.
+func (d *Debouncer) add(value interface{}) bool {
+	return d.submit(d.callerPC(1), value, nil, nil)
+}
+
+// callerPC returns the program counter of the call site of the exported
+// function called by users, when WithCallSiteCapture is used. It must be
+// called directly by the exported function, or with skip set to the number of
+// functions in between.
+func (d *Debouncer) callerPC(skip int) uintptr {
+	if !d.opts.captureCallers {
+		return 0
+	}
+
+	// Skip runtime.Callers, callerPC, and the function calling it.
+	var pcs [1]uintptr
+	if runtime.Callers(3+skip+d.opts.callerSkip, pcs[:]) > 0 {
+		return pcs[0]
+	}
+
+	return 0
+}
+
+// submit records a call passing value made from pc, and executes the callback
+// function if it is due right away. The promise p, if not nil, is completed
+// once the invocation covering the call has completed. The context ctx, if not
+// nil, is the context of a call made with DebounceCtx.
+//
+// It reports false if the Debouncer has been evicted from its Group, in which
+// case the call has no effect.
+func (d *Debouncer) submit(
+	pc uintptr,
+	value interface{},
+	p *Promise,
+	ctx context.Context,
+) bool {
+	// The predicate is called without holding the lock, as it may block.
+	allowed := d.opts.predicate == nil || d.opts.predicate()
+	if !allowed {
+		d.suppressed(SuppressPredicate)
+	}
+
+	info, dropped, ok, evicted := d.debounce(allowed, pc, value, p, ctx)
+	if dropped != nil && d.onDrop != nil {
+		d.onDrop(dropped)
+	}
+
+	switch {
+	case !ok:
+	case d.synchronous():
+		d.run(info)
+	default:
+		d.execute(info)
+	}
+
+	return !evicted
+}
+
+// debounce records a call passing value made from pc, and reports if the
+// callback function should be executed right away, and if the Debouncer has
+// been evicted from its Group. Any values dropped due to WithMaxPending are
+// returned too. The promise p and context ctx, if not nil, travel along with
+// the call.
+func (d *Debouncer) debounce(
+	allowed bool,
+	pc uintptr,
+	value interface{},
+	p *Promise,
+	ctx context.Context,
+) (info InvokeInfo, dropped interface{}, ok, evicted bool) {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	if d.closed {
+		p.complete(ErrCanceled)
+
+		return InvokeInfo{}, nil, false, d.evicted
+	}
+
+	d.stats.Calls++
+	if !allowed {
+		d.stats.Suppressed++
+		p.complete(ErrCanceled)
+
+		return InvokeInfo{}, nil, false, false
+	}
+
+	dropped, covered := d.coalesce(value, p)
+	if covered {
+		return InvokeInfo{}, dropped, false, false
+	}
+
+	call := d.newCall(pc, value, p, ctx)
+	now := d.now()
+	d.observeCall(now)
+
+	if d.throttle {
+		info, ok = d.throttleCall(now, call)
+
+		return info, dropped, ok, false
+	}
+
+	if d.passes(now) {
+		info, ok = d.invoke(call)
+
+		return info, dropped, ok, false
+	}
+
+	// A call during the confirmation window set with WithConfirmWindow
+	// cancels the pending invocation, rather than postponing it. The burst
+	// carries on with this call, so the maximum wait time still applies.
+	if d.confirming {
+		d.confirming = false
+		d.burst.complete(ErrCanceled)
+		d.burst = InvokeInfo{}
+	}
+
+	d.burst = d.burst.merge(call, d.combine)
+	d.arm()
+
+	if d.full != nil && d.full(d.burst.value) {
+		info, ok = d.flush(InvokeMaxBatchSize)
+
+		return info, dropped, ok, false
+	}
+
+	return InvokeInfo{}, dropped, false, false
+}
+
+// coalesce reports if a call passing value is covered by the pending burst,
+// either because it repeats the pending value, or because it is dropped due
+// to WithMaxPending, in which case the promise p, if not nil, joins the
+// burst. Any values dropped to make room for value are returned too. Must be
+// called while holding the lock.
+func (d *Debouncer) coalesce(
+	value interface{},
+	p *Promise,
+) (dropped interface{}, covered bool) {
+	if !d.dirty {
+		return nil, false
+	}
+
+	// A call repeating the pending value is covered by the pending invocation,
+	// so it is ignored without postponing it.
+	if d.same != nil && d.same(d.burst.value, value) {
+		d.stats.Suppressed++
+		if p != nil {
+			d.burst.promises = append(d.burst.promises, p)
+		}
+
+		return nil, true
+	}
+
+	// Make room for the call's value when the pending burst is at the limit
+	// set with WithMaxPending. A dropped call leaves the timers alone.
+	if d.limit != nil {
+		var n int
+		var dropValue bool
+		d.burst.value, dropped, n, dropValue = d.limit(d.burst.value, value)
+		d.stats.Dropped += n
+		if dropValue {
+			if p != nil {
+				d.burst.promises = append(d.burst.promises, p)
+			}
+
+			return dropped, true
+		}
+	}
+
+	return dropped, false
+}
+
+// newCall returns the InvokeInfo of a single call passing value made from pc,
+// carrying the promise p and context ctx, if not nil.
+func (d *Debouncer) newCall(
+	pc uintptr,
+	value interface{},
+	p *Promise,
+	ctx context.Context,
+) InvokeInfo {
+	call := InvokeInfo{Calls: 1, Reason: InvokeImmediate, value: value}
+	if pc != 0 {
+		call.Callers = []uintptr{pc}
+	}
+	if p != nil {
+		call.promises = []*Promise{p}
+	}
+	// Contexts which can never be done have no deadline, and are never
+	// dropped by WithDropCanceledCalls, so there is no need to keep them.
+	// Other contexts are only kept if they need to be checked once the
+	// invocation starts, otherwise only the earliest deadline is, so a long
+	// burst of calls does not pile up contexts.
+	if ctx != nil && ctx.Done() != nil {
+		if d.opts.dropCanceled || d.opts.ignoreExpired {
+			call.ctxs = []context.Context{ctx}
+		} else if deadline, ok := ctx.Deadline(); ok {
+			call.earliest = deadline
+		}
+	}
+
+	return call
+}
+
+// observeCall records a call made at now, resetting any state which depends
+// on the idle time since the previous call, and restarting the idle and
+// eviction timers. Must be called while holding the lock.
+func (d *Debouncer) observeCall(now time.Time) {
+	if !d.lastCall.IsZero() {
+		idle := elapsed(d.lastCall, now)
+		if d.opts.backoff && idle >= d.opts.backoffResetAfter {
+			d.backoffScale = 1
+		}
+		if idle >= d.idleWait() {
+			d.passThrough = 0
+		}
+		if d.adaptive {
+			d.observeGap(idle)
+		}
+	}
+	d.lastCall = now
+
+	if d.opts.onIdle != nil {
+		d.idleTimer.Reset(d.opts.idleAfter)
+	}
+	if d.evictTimer != nil {
+		d.evictTimer.Reset(d.opts.groupMaxIdle)
+	}
+}
+
+// passes reports if a call made at now should invoke the callback function
+// right away rather than being debounced. Must be called while holding the
+// lock.
+func (d *Debouncer) passes(now time.Time) bool {
+	// Without a wait time there is nothing to debounce, so invoke right away
+	// unless something else defers the invocation.
+	if d.zeroWait() && !d.dirty && d.deferral(now) == 0 {
+		return true
+	}
+
+	// Let calls through while they arrive slower than the rate set with
+	// WithActivationRate, as long as no trailing invocation is pending, so
+	// calls debounced during a spike are not overtaken.
+	if d.opts.activationRate > 0 && !d.activated(now) && !d.dirty &&
+		d.deferral(now) == 0 {
+		return true
+	}
+
+	// Let the first calls of a burst through when WithBurstPassThrough is
+	// used, as long as no trailing invocation is pending.
+	if !d.dirty && d.passThrough < d.opts.burstPassThrough &&
+		d.deferral(now) == 0 {
+		d.passThrough++
+
+		return true
+	}
+
+	return false
+}
+
+// arm starts a new burst if one is not already pending, and (re)starts the
+// wait timer. Must be called while holding the lock.
+func (d *Debouncer) arm() {
+	if !d.dirty {
+		d.dirty = true
+		d.burstWait = d.nextWait()
+
+		d.armMaxTimer(0)
+
+		if d.opts.firstWait > 0 {
+			d.resetTimer(d.opts.firstWait, true)
+
+			return
+		}
+
+		d.resetTimer(d.burstWait, true)
+
+		return
+	}
+
+	if d.adaptive {
+		d.burstWait = d.nextWait()
+	}
+	d.resetTimer(d.burstWait, false)
+}
+
+// armMaxTimer starts the maximum wait timer of a new burst, if there is one, to
+// expire after the burst's maximum wait time, or after atLeast if that is
+// longer. Must be called while holding the lock.
+func (d *Debouncer) armMaxTimer(atLeast time.Duration) {
+	if d.maxTimer == nil {
+		return
+	}
+
+	d.burstMaxWait = d.maxWait
+	if d.opts.maxWaitJitter > 0 {
+		d.burstMaxWait += d.randDuration(0, d.opts.maxWaitJitter-1)
+	}
+	if d.burstMaxWait < atLeast {
+		d.burstMaxWait = atLeast
+	}
+	now := d.now()
+	d.maxTimer.Reset(elapsed(now, d.roundUp(now.Add(d.burstMaxWait))))
+}
+
+// resetTimer (re)starts the wait timer to expire after wait. Unless force is
+// true, the timer is left alone if the new expiry time is within the slack set
+// by WithSlack of the current one, or falls within the same granularity bucket
+// set by WithTimerGranularity. Must be called while holding the lock.
+func (d *Debouncer) resetTimer(wait time.Duration, force bool) {
+	now := d.now()
+	expiry := d.roundUp(now.Add(wait))
+	if !force {
+		if expiry.Equal(d.timerExpiry) {
+			return
+		}
+		if d.opts.slack > 0 && elapsed(d.timerExpiry, expiry) < d.opts.slack {
+			return
+		}
+	}
+
+	d.timerExpiry = expiry
+	d.timer.Reset(elapsed(now, expiry))
+}
+
+// roundUp rounds t up to the next multiple of the granularity set with
+// WithTimerGranularity, if any.
+func (d *Debouncer) roundUp(t time.Time) time.Time {
+	g := d.opts.timerGranularity
+	if g <= 0 {
+		return t
+	}
+
+	if rounded := t.Truncate(g); rounded.Before(t) {
+		return rounded.Add(g)
+	}
+
+	return t
+}
+
+// Cancel cancels any pending invocation of the callback function, including
+// one whose timer has already expired, but which has not started yet.
+// Invocations which have already started are not affected; use Wait to wait
+// for them to complete.
+func (d *Debouncer) Cancel() {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	d.cancel()
+	d.throttledUntil = time.Time{}
+	if d.invokeCancel != nil {
+		d.invokeCancel()
+		d.invokeCancel = nil
+	}
+}
+
+// cancelFunc returns the cancel function of a debounced function returned by a
+// constructor like New, which cancels like Cancel, but also removes d from the
+// Registry set with WithRegistry, as such a Debouncer is never closed.
+func (d *Debouncer) cancelFunc() func() {
+	if d.opts.registry == nil {
+		return d.Cancel
+	}
+
+	return func() {
+		d.Cancel()
+		d.opts.registry.remove(d)
+	}
+}
+
+// Flush invokes any pending invocation of the callback function right away,
+// regardless of the wait and maximum wait times, WithSchedule, WithQuota and
+// WithRateLimiter. It has no effect if no invocation is pending, in which case
+// it returns ErrNothingPending, or if the Debouncer has been closed, in which
+// case it returns ErrClosed.
+func (d *Debouncer) Flush() error {
+	info, ok, err := d.flushNow()
+	if ok {
+		d.execute(info)
+	}
+
+	return err
+}
+
+// flushNow ends the pending burst of calls if there is one, and reports if the
+// callback function should be executed. It returns ErrNothingPending if there
+// is no pending burst, and ErrClosed if the Debouncer has been closed.
+func (d *Debouncer) flushNow() (InvokeInfo, bool, error) {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	switch {
+	case d.closed:
+		return InvokeInfo{}, false, ErrClosed
+	case !d.dirty:
+		return InvokeInfo{}, false, ErrNothingPending
+	}
+
+	info, ok := d.release(InvokeFlush)
+
+	return info, ok, nil
+}
+
+// flushDone is like Flush, but returns a Promise which completes once the
+// flushed invocation has completed, or nil if no invocation is pending.
+func (d *Debouncer) flushDone() *Promise {
+	d.mux.Lock()
+	if !d.dirty {
+		d.mux.Unlock()
+
+		return nil
+	}
+	p := newPromise()
+	d.burst.promises = append(d.burst.promises, p)
+	info, ok := d.release(InvokeFlush)
+	d.mux.Unlock()
+
+	if ok {
+		d.execute(info)
+	}
+
+	return p
+}
+
+// Close cancels any pending invocation of the callback function, and cancels
+// the context of any running invocations. Further calls to Debounce have no
+// effect. Calling Close more than once has no effect.
+//
+// With WithCloseTimeout, Close invokes any pending invocation right away
+// instead, and waits for running invocations to complete before canceling
+// their context. If they are still running once the timeout expires, Close
+// returns ErrTimeout.
+func (d *Debouncer) Close() error {
+	d.mux.Lock()
+	if d.closed || d.opts.closeTimeout <= 0 {
+		d.close()
+		d.mux.Unlock()
+
+		return nil
+	}
+
+	info, ok := InvokeInfo{}, false
+	if d.dirty {
+		info, ok = d.release(InvokeFlush)
+	}
+	// Further calls have no effect while waiting, but the timers and worker
+	// are only stopped once running invocations have completed.
+	d.closed = true
+	drained := d.drainedChan()
+	d.mux.Unlock()
+
+	if ok {
+		d.execute(info)
+	}
+
+	var err error
+	if drained != nil {
+		t := time.NewTimer(d.opts.closeTimeout)
+		select {
+		case <-drained:
+		case <-t.C:
+			err = ErrTimeout
+		}
+		t.Stop()
+	}
+
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	d.close()
+
+	return err
+}
+
+// close closes the Debouncer. Must be called while holding the lock.
+func (d *Debouncer) close() {
+	d.closed = true
+	d.cancel()
+	d.deadlineTimer.Stop()
+	d.idleTimer.Stop()
+	if d.evictTimer != nil {
+		d.evictTimer.Stop()
+	}
+	d.ctxCancel()
+
+	if d.worker != nil {
+		d.worker.stop()
+	}
+	if d.opts.registry != nil {
+		d.opts.registry.remove(d)
+	}
+}
+
+// InFlight returns the number of invocations of the callback function which
+// are running, including any queued due to WithSerializedExecution. Unlike a
+// pending invocation, an invocation which has started can not be canceled by
+// Cancel.
+func (d *Debouncer) InFlight() int {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	if d.queued {
+		// The queued execution is run by the running one once it completes,
+		// so it is not counted by running.
+		return d.running + 1
+	}
+
+	return d.running
+}
+
+// Wait blocks until no invocations of the callback function are running, for
+// example to make sure that an invocation which had already started when
+// Cancel was called has completed. If ctx is done first, Wait returns the
+// context's error.
+//
+// Invocations queued due to WithSerializedExecution, or started while Wait is
+// blocked, are waited for too, so Wait may block for as long as invocations
+// keep starting.
+func (d *Debouncer) Wait(ctx context.Context) error {
+	d.mux.Lock()
+	drained := d.drainedChan()
+	d.mux.Unlock()
+
+	if drained == nil {
+		return nil
+	}
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// drainedChan returns a channel which is closed once no invocations are
+// running, or nil if none are running. Must be called while holding the lock.
+func (d *Debouncer) drainedChan() chan struct{} {
+	if d.running == 0 {
+		return nil
+	}
+	if d.drained == nil {
+		d.drained = make(chan struct{})
+	}
+
+	return d.drained
+}
+
+// Pending reports if an invocation of the callback function is pending.
+func (d *Debouncer) Pending() bool {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	return d.dirty
+}
+
+// pendingValue returns the combined value of the pending burst, and reports if
+// there is one.
+func (d *Debouncer) pendingValue() (interface{}, bool) {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	return d.burst.value, d.burst.Calls > 0
+}
+
+// peek calls f with the combined value of the pending burst while holding the
+// lock, so f can copy it before further calls modify it. It does not call f if
+// there is no pending burst.
+func (d *Debouncer) peek(f func(value interface{})) {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	if d.burst.Calls > 0 {
+		f(d.burst.value)
+	}
+}
+
+// LastBurstCallers returns the program counters of the call sites which fed
+// the most recent invocation of the callback function, as recorded when
+// WithCallSiteCapture is used. They can be resolved with
+// runtime.CallersFrames.
+func (d *Debouncer) LastBurstCallers() []uintptr {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	return append([]uintptr(nil), d.lastCallers...)
+}
+
+// RanWithin reports if an invocation of the callback function completed within
+// the last dur, including exactly dur ago. Invocations which are still running
+// do not count, unlike with a Suppressor using WithCountInFlight.
+func (d *Debouncer) RanWithin(dur time.Duration) bool {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	return d.ranWithin(d.now(), dur, false)
+}
+
+// ranWithin reports if an invocation completed within dur before now, or if
+// inFlight is true, is running. Must be called while holding the lock.
+func (d *Debouncer) ranWithin(
+	now time.Time,
+	dur time.Duration,
+	inFlight bool,
+) bool {
+	if inFlight && d.running > 0 {
+		return true
+	}
+
+	return !d.lastRan.IsZero() && elapsed(d.lastRan, now) <= dur
+}
+
+// Stats returns a snapshot of the Debouncer's activity counters.
+func (d *Debouncer) Stats() Stats {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	return d.stats
+}
+
+// SetDeadline sets an absolute time by which any pending invocation of the
+// callback function is invoked, replacing any previously set deadline. A zero
+// time clears the deadline.
+//
+// The deadline only fires once, and is not affected by Cancel.
+func (d *Debouncer) SetDeadline(t time.Time) {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	if d.closed {
+		return
+	}
+
+	d.setDeadline(t)
+}
+
+// setDeadline (re)arms the deadline timer. Must be called while holding the
+// lock.
+func (d *Debouncer) setDeadline(t time.Time) {
+	d.deadlineTimer.Stop()
+	if !t.IsZero() {
+		d.deadlineTimer.Reset(time.Until(t))
+	}
+}
+
+// fire is called by timer t once it expires, and invokes f for the given
+// reason if there is a pending burst of calls.
+func (d *Debouncer) fire(reason InvokeReason, t *guardedTimer) {
+	if info, ok := d.trigger(reason, t); ok {
+		d.execute(info)
+	}
+}
+
+// idle is called by the idle timer, and calls the hook set with WithOnIdle,
+// unless an invocation is still pending.
+func (d *Debouncer) idle() {
+	d.mux.Lock()
+	if d.closed {
+		d.mux.Unlock()
+
+		return
+	}
+
+	// Only consider the Debouncer idle once the pending invocation is done.
+	if d.dirty {
+		d.idleTimer.Reset(d.opts.idleAfter)
+		d.mux.Unlock()
+
+		return
+	}
+	d.mux.Unlock()
+
+	d.opts.onIdle()
+}
+
+// trigger ends the pending burst of calls if there is one, and reports if the
+// callback function should be executed.
+func (d *Debouncer) trigger(
+	reason InvokeReason,
+	t *guardedTimer,
+) (InvokeInfo, bool) {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	// The timer may have been reset or stopped since it expired, like by
+	// Cancel followed by a new call, in which case the expiry is stale.
+	if !t.fired() || !d.dirty {
+		return InvokeInfo{}, false
+	}
+
+	// Once the wait time elapses, run the confirmation window set with
+	// WithConfirmWindow before invoking.
+	if reason == InvokeWait && d.opts.confirmWindow > 0 && !d.confirming {
+		d.confirming = true
+		d.resetTimer(d.opts.confirmWindow, true)
+
+		return InvokeInfo{}, false
+	}
+
+	if reason == 0 {
+		reason = d.deferredReason
+	}
+
+	return d.flush(reason)
+}
+
+// flush ends the pending burst of calls for the given reason, and reports if
+// the callback function should be executed. Must be called while holding the
+// lock.
+func (d *Debouncer) flush(reason InvokeReason) (InvokeInfo, bool) {
+	// Keep the burst pending until the quota and rate limiter allow another
+	// invocation.
+	if delay := d.deferral(d.now()); delay > 0 {
+		d.timer.Stop()
+		if d.maxTimer != nil {
+			d.maxTimer.Stop()
+		}
+		d.deferTimer.Reset(delay)
+		d.deferredReason = reason
+
+		return InvokeInfo{}, false
+	}
+
+	return d.release(reason)
+}
+
+// release ends the pending burst of calls right away for the given reason, and
+// reports if the callback function should be executed. Must be called while
+// holding the lock.
+func (d *Debouncer) release(reason InvokeReason) (InvokeInfo, bool) {
+	info := d.burst
+	info.Reason = reason
+	d.stop()
+	d.backoff()
+
+	return d.invoke(info)
+}
+
+// invoke records an invocation of f for the calls described by info, and
+// reports if f should be executed, as opposed to the invocation being queued
+// or dropped. Must be called while holding the lock.
+func (d *Debouncer) invoke(info InvokeInfo) (InvokeInfo, bool) {
+	if d.running > 0 {
+		switch {
+		case d.opts.dropIfRunning:
+			// Keep the calls around for the invocation armed once the
+			// running one completes.
+			d.rearm = true
+			d.burst = info.merge(d.burst, d.combine)
+
+			return InvokeInfo{}, false
+		case d.opts.serialized:
+			if !d.queued {
+				d.queued = true
+				d.invoked()
+			}
+			d.queuedInfo = d.queuedInfo.merge(info, d.combine)
+
+			return InvokeInfo{}, false
+		}
+	}
+
+	d.running++
+	d.invoked()
+
+	return info, true
+}
+
+// invoked records an invocation which is about to run. Must be called while
+// holding the lock.
+func (d *Debouncer) invoked() {
+	d.recordQuota()
+	if d.throttle {
+		d.throttledUntil = d.now().Add(d.wait)
+	}
+
+	d.invocations++
+	if d.opts.autoStop > 0 && d.invocations >= d.opts.autoStop {
+		d.closed = true
+		d.cancel()
+		d.deadlineTimer.Stop()
+	}
+}
+
+// execute hands an invocation to the executor. Must not be called while
+// holding the lock, as the executor may block.
+func (d *Debouncer) execute(info InvokeInfo) {
+	d.opts.executor.Execute(func() { d.run(info) })
+}
+
+// run calls f, followed by any execution which was queued while f was running.
+func (d *Debouncer) run(info InvokeInfo) {
+	for {
+		// Dropping calls due to WithDropCanceledCalls may leave none.
+		live := true
+		reason := SuppressInvokeCondition
+		if d.opts.dropCanceled {
+			var dropped int
+			info, dropped = info.dropCanceled()
+			if dropped > 0 && info.Calls == 0 {
+				live = false
+				reason = SuppressCanceled
+			}
+		}
+
+		// The condition is called without holding the lock, as it may block.
+		invoked := live &&
+			(d.opts.invokeCondition == nil || d.opts.invokeCondition())
+		if invoked {
+			d.mux.Lock()
+			d.lastCallers = info.Callers
+			d.mux.Unlock()
+
+			if d.opts.onInvoke != nil {
+				d.opts.onInvoke(info)
+			}
+			d.call(info)
+			info.complete(nil)
+		} else {
+			d.suppressed(reason)
+			info.complete(ErrCanceled)
+		}
+
+		d.mux.Lock()
+		if invoked {
+			d.stats.Invocations++
+			d.lastRan = d.now()
+		} else {
+			d.stats.Skipped++
+		}
+
+		if d.queued {
+			d.queued = false
+			info = d.queuedInfo
+			d.queuedInfo = InvokeInfo{}
+			d.mux.Unlock()
+
+			continue
+		}
+
+		d.running--
+		if d.running == 0 && d.drained != nil {
+			close(d.drained)
+			d.drained = nil
+		}
+		if d.rearm {
+			d.rearm = false
+			d.arm()
+		}
+		autoStopped := d.autoStopped()
+		d.mux.Unlock()
+
+		if autoStopped {
+			if d.worker != nil {
+				d.worker.stop()
+			}
+			if d.opts.onAutoStop != nil {
+				d.opts.onAutoStop()
+			}
+		}
+
+		return
+	}
+}
+
+// autoStopped reports if the last invocation allowed by WithAutoStop has just
+// completed, and does so only once. Must be called while holding the lock.
+func (d *Debouncer) autoStopped() bool {
+	if d.opts.autoStop <= 0 || d.autoStopDone || d.running > 0 ||
+		d.invocations < d.opts.autoStop {
+		return false
+	}
+
+	d.autoStopDone = true
+
+	return true
+}
+
+// call calls f with a context for the invocation, and the calls which led to
+// it.
+func (d *Debouncer) call(info InvokeInfo) {
+	if cb, ok := info.value.(callback); ok && cb.f != nil {
+		cb.f()
+
+		return
+	}
+
+	ctx := d.ctx
+	if d.supersede {
+		var cancel context.CancelFunc
+		ctx, cancel = d.supersedeCtx()
+		defer cancel()
+	}
+
+	if deadline, ok := info.deadline(d.opts.ignoreExpired); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline)
+		defer cancel()
+	}
+
+	if d.opts.invokeTimeout <= 0 {
+		d.f(ctx, info)
+
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, d.opts.invokeTimeout)
+	defer cancel()
+
+	d.f(ctx, info)
+}
+
+// supersedeCtx returns a context for an invocation which is starting, and
+// cancels the context of the previous invocation. The returned cancel function
+// must be called once the invocation has completed.
+func (d *Debouncer) supersedeCtx() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(d.ctx)
+
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	if d.invokeCancel != nil {
+		d.invokeCancel()
+	}
+	d.invokeCancel = cancel
+	d.invokeGen++
+	gen := d.invokeGen
+
+	return ctx, func() {
+		d.mux.Lock()
+		if d.invokeGen == gen {
+			d.invokeCancel = nil
+		}
+		d.mux.Unlock()
+
+		cancel()
+	}
+}
+
+// suppressed calls the hook set with WithOnSuppressed, if any. Must not be
+// called while holding the lock.
+func (d *Debouncer) suppressed(reason SuppressReason) {
+	if d.opts.onSuppressed != nil {
+		d.opts.onSuppressed(reason)
+	}
+}
+
+// cancel stops all timers and discards any pending burst, completing the
+// promises of its calls with ErrCanceled. Must be called while holding the
+// lock.
+func (d *Debouncer) cancel() {
+	d.burst.complete(ErrCanceled)
+	d.stop()
+}
+
+// stop stops all timers and clears any pending burst. Must be called while
+// holding the lock.
+func (d *Debouncer) stop() {
+	d.burst = InvokeInfo{}
+	d.timer.Stop()
+	if d.maxTimer != nil {
+		d.maxTimer.Stop()
+	}
+	d.deferTimer.Stop()
+	d.dirty = false
+	d.confirming = false
+}
+
+// deferral returns how long an invocation must be deferred for by the schedule
+// set with WithSchedule, the quota set with WithQuota and the rate limiter set
+// with WithRateLimiter, or zero if it may happen right away. Must be called
+// while holding the lock.
+func (d *Debouncer) deferral(now time.Time) time.Duration {
+	if d.opts.schedule != nil {
+		if delay := elapsed(now, d.opts.schedule.Next(now)); delay > 0 {
+			return delay
+		}
+	}
+
+	if delay := d.quotaDelay(now); delay > 0 {
+		return delay
+	}
+
+	if d.opts.limiter == nil {
+		return 0
+	}
+
+	// Hold on to a reservation until it is used, so a deferred invocation
+	// does not ask the rate limiter again when it is due.
+	if d.reservedAt.IsZero() {
+		d.reservedAt = now.Add(d.opts.limiter.Reserve())
+	}
+	if delay := elapsed(now, d.reservedAt); delay > 0 {
+		return delay
+	}
+	d.reservedAt = time.Time{}
+
+	return 0
+}
+
+// quotaDelay returns how long until the quota set with WithQuota allows
+// another invocation, or zero if it allows one right away. Must be called
+// while holding the lock.
+func (d *Debouncer) quotaDelay(now time.Time) time.Duration {
+	if d.opts.quota <= 0 || len(d.quotaTimes) < d.opts.quota {
+		return 0
+	}
+
+	return d.opts.quotaWindow - elapsed(d.quotaTimes[0], now)
+}
+
+// recordQuota records an invocation against the quota set with WithQuota. Must
+// be called while holding the lock.
+func (d *Debouncer) recordQuota() {
+	if d.opts.quota <= 0 {
+		return
+	}
+
+	if len(d.quotaTimes) == d.opts.quota {
+		d.quotaTimes = append(d.quotaTimes[:0], d.quotaTimes[1:]...)
+	}
+	d.quotaTimes = append(d.quotaTimes, d.now())
+}
+
+// activated records a call made at now, and reports if the calls made within
+// the window set with WithActivationRate, including this one, exceed its rate.
+// Must be called while holding the lock.
+func (d *Debouncer) activated(now time.Time) bool {
+	n := d.opts.activationRate
+	if len(d.callTimes) == n {
+		d.callTimes = append(d.callTimes[:0], d.callTimes[1:]...)
+	}
+	d.callTimes = append(d.callTimes, now)
+
+	return len(d.callTimes) == n &&
+		elapsed(d.callTimes[0], now) < d.opts.activationWindow
+}
+
+// zeroWait reports if the Debouncer has no wait time, in which case calls
+// invoke the callback function right away.
+func (d *Debouncer) zeroWait() bool {
+	return d.wait <= 0 && !d.opts.waitRange && d.opts.firstWait <= 0
+}
+
+// synchronous reports if calls invoke the callback function synchronously,
+// which is the case when there is no wait time and WithZeroWaitSync is used.
+func (d *Debouncer) synchronous() bool {
+	return d.zeroWait() && d.opts.zeroWaitSync
+}
+
+// idleWait returns how long the debounced function must not have been called
+// for it to be considered idle. Must be called while holding the lock.
+func (d *Debouncer) idleWait() time.Duration {
+	if d.burstWait > 0 {
+		return d.burstWait
+	}
+
+	return d.wait
+}
+
+// nextWait returns the wait time to use for a new burst of calls. Must be
+// called while holding the lock.
+func (d *Debouncer) nextWait() time.Duration {
+	wait := d.wait
+	switch {
+	case d.adaptive:
+		wait = d.adaptiveWait()
+	case d.opts.waitRange:
+		wait = d.randDuration(d.opts.waitMin, d.opts.waitMax)
+	}
+
+	if d.opts.backoff {
+		wait = minDuration(
+			time.Duration(float64(wait)*d.backoffScale), d.opts.backoffMax,
+		)
+	}
+
+	return wait
+}
+
+// backoff grows the wait time of the next burst when WithBackoff is used,
+// until the wait of the burst which has just ended reached the maximum. Must
+// be called while holding the lock.
+func (d *Debouncer) backoff() {
+	if !d.opts.backoff || d.burstWait >= d.opts.backoffMax {
+		return
+	}
+
+	d.backoffScale *= d.opts.backoffFactor
+}
+
+// newFireTimer returns a stopped timer which calls fire with reason once it
+// expires.
+func (d *Debouncer) newFireTimer(reason InvokeReason) *guardedTimer {
+	t := &guardedTimer{}
+	t.timer = d.newTimer(func() { d.fire(reason, t) })
+
+	return t
+}
+
+// newTimer returns a stopped timer which calls f once it expires, run by the
+// Scheduler set with WithScheduler, if any.
+func (d *Debouncer) newTimer(f func()) timer {
+	if d.opts.scheduler != nil {
+		return d.opts.scheduler.newTimer(f)
+	}
+
+	return stoppedTimer(f)
+}
+
+// randDuration returns a random duration in [low, high]. Must be called while
+// holding the lock.
+func (d *Debouncer) randDuration(low, high time.Duration) time.Duration {
+	if high <= low {
+		return low
+	}
+
+	n := int64(high-low) + 1
+	if d.rand != nil {
+		return low + time.Duration(d.rand.Int63n(n))
+	}
+
+	return low + time.Duration(globalRand.Int63n(n))
+}
+
+// minDuration returns the smaller of a and b.
+func minDuration(a, b time.Duration) time.Duration {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+// elapsed returns the time elapsed from start to end.
+//
+// Times returned by time.Now carry a monotonic clock reading which Sub uses,
+// making the result immune to changes of the wall clock. For times without a
+// monotonic reading, a wall clock stepping backwards would yield a negative
+// duration, which is treated as no time having elapsed.
+func elapsed(start, end time.Time) time.Duration {
+	if d := end.Sub(start); d > 0 {
+		return d
+	}
+
+	return 0
+}
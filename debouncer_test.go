@@ -0,0 +1,608 @@
+package debounce
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebouncer_Close(t *testing.T) {
+	t.Parallel()
+
+	mux := sync.RWMutex{}
+	n := 0
+	d := NewDebouncer(10*time.Millisecond, func() {
+		mux.Lock()
+		defer mux.Unlock()
+		n++
+	})
+
+	d.Debounce()
+	d.Close()
+	d.Close()
+	d.Debounce()
+	time.Sleep(30 * time.Millisecond)
+
+	mux.RLock()
+	defer mux.RUnlock()
+	assert.Equal(t, 0, n)
+}
+
+func TestNewDebouncerCtx(t *testing.T) {
+	t.Parallel()
+
+	t.Run("invoke timeout", func(t *testing.T) {
+		t.Parallel()
+
+		deadlines := make(chan time.Time, 2)
+		d := NewDebouncerCtx(5*time.Millisecond, func(ctx context.Context) {
+			deadline, ok := ctx.Deadline()
+			assert.True(t, ok)
+			deadlines <- deadline
+		}, WithInvokeTimeout(time.Minute))
+
+		for i := 0; i < 2; i++ {
+			start := time.Now()
+			d.Debounce()
+			deadline := <-deadlines
+
+			assert.WithinDuration(t, start.Add(time.Minute), deadline,
+				50*time.Millisecond)
+		}
+	})
+
+	t.Run("invoke timeout expires", func(t *testing.T) {
+		t.Parallel()
+
+		errs := make(chan error, 1)
+		d := NewDebouncerCtx(5*time.Millisecond, func(ctx context.Context) {
+			<-ctx.Done()
+			errs <- ctx.Err()
+		}, WithInvokeTimeout(10*time.Millisecond))
+
+		d.Debounce()
+		select {
+		case err := <-errs:
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+		case <-time.After(time.Second):
+			t.Fatal("context was not canceled")
+		}
+	})
+
+	t.Run("canceled on close", func(t *testing.T) {
+		t.Parallel()
+
+		started := make(chan struct{})
+		errs := make(chan error, 1)
+		d := NewDebouncerCtx(5*time.Millisecond, func(ctx context.Context) {
+			close(started)
+			<-ctx.Done()
+			errs <- ctx.Err()
+		}, WithInvokeTimeout(time.Minute))
+
+		d.Debounce()
+		<-started
+		d.Close()
+
+		select {
+		case err := <-errs:
+			require.ErrorIs(t, err, context.Canceled)
+		case <-time.After(time.Second):
+			t.Fatal("context was not canceled")
+		}
+	})
+}
+
+func TestDebouncer_DebounceCtx(t *testing.T) {
+	t.Parallel()
+
+	type invocation struct {
+		calls    int
+		deadline time.Time
+		hasDL    bool
+		err      error
+	}
+	newRecorder := func(
+		opts ...Option,
+	) (*Debouncer, <-chan invocation) {
+		invocations := make(chan invocation, 1)
+		d := newDebouncer(time.Hour, func(
+			ctx context.Context, info InvokeInfo,
+		) {
+			deadline, ok := ctx.Deadline()
+			invocations <- invocation{
+				calls:    info.Calls,
+				deadline: deadline,
+				hasDL:    ok,
+				err:      ctx.Err(),
+			}
+		}, opts)
+
+		return d, invocations
+	}
+	withDeadline := func(deadline time.Time) context.Context {
+		ctx, cancel := context.WithDeadline(context.Background(), deadline)
+		t.Cleanup(cancel)
+
+		return ctx
+	}
+
+	t.Run("earliest deadline", func(t *testing.T) {
+		t.Parallel()
+
+		base := time.Now()
+		d, invocations := newRecorder()
+
+		d.DebounceCtx(withDeadline(base.Add(time.Hour)))
+		d.DebounceCtx(withDeadline(base.Add(30 * time.Minute)))
+		d.DebounceCtx(context.Background())
+		d.Debounce()
+
+		// Only the earliest deadline is kept, rather than every context.
+		d.mux.Lock()
+		assert.Empty(t, d.burst.ctxs)
+		assert.True(t, base.Add(30*time.Minute).Equal(d.burst.earliest))
+		d.mux.Unlock()
+
+		require.NoError(t, d.Flush())
+
+		inv := <-invocations
+		assert.Equal(t, 4, inv.calls)
+		assert.True(t, inv.hasDL)
+		assert.True(t, base.Add(30*time.Minute).Equal(inv.deadline))
+		assert.NoError(t, inv.err)
+
+		// Deadlines do not carry over to the next invocation.
+		d.Debounce()
+		require.NoError(t, d.Flush())
+		inv = <-invocations
+		assert.False(t, inv.hasDL)
+	})
+
+	t.Run("invoke timeout", func(t *testing.T) {
+		t.Parallel()
+
+		base := time.Now()
+		d, invocations := newRecorder(WithInvokeTimeout(time.Minute))
+
+		// The invoke timeout is stricter than the caller's deadline.
+		d.DebounceCtx(withDeadline(base.Add(time.Hour)))
+		require.NoError(t, d.Flush())
+		inv := <-invocations
+		assert.WithinDuration(t, base.Add(time.Minute), inv.deadline,
+			time.Second)
+
+		// The caller's deadline is stricter than the invoke timeout.
+		d.DebounceCtx(withDeadline(base.Add(10 * time.Second)))
+		require.NoError(t, d.Flush())
+		inv = <-invocations
+		assert.True(t, base.Add(10*time.Second).Equal(inv.deadline))
+	})
+
+	t.Run("expired deadline", func(t *testing.T) {
+		t.Parallel()
+
+		base := time.Now()
+		d, invocations := newRecorder()
+
+		d.DebounceCtx(withDeadline(base.Add(-time.Second)))
+		d.DebounceCtx(withDeadline(base.Add(time.Hour)))
+		require.NoError(t, d.Flush())
+
+		inv := <-invocations
+		assert.Equal(t, 2, inv.calls)
+		assert.True(t, base.Add(-time.Second).Equal(inv.deadline))
+		assert.ErrorIs(t, inv.err, context.DeadlineExceeded)
+	})
+
+	t.Run("ignore expired deadlines", func(t *testing.T) {
+		t.Parallel()
+
+		base := time.Now()
+		d, invocations := newRecorder(WithIgnoreExpiredDeadlines())
+
+		d.DebounceCtx(withDeadline(base.Add(-time.Second)))
+		d.DebounceCtx(withDeadline(base.Add(time.Hour)))
+		require.NoError(t, d.Flush())
+
+		inv := <-invocations
+		assert.Equal(t, 2, inv.calls)
+		assert.True(t, base.Add(time.Hour).Equal(inv.deadline))
+		assert.NoError(t, inv.err)
+
+		// With only expired deadlines, there is no deadline at all.
+		d.DebounceCtx(withDeadline(base.Add(-time.Second)))
+		require.NoError(t, d.Flush())
+		inv = <-invocations
+		assert.False(t, inv.hasDL)
+	})
+
+	t.Run("drop canceled calls", func(t *testing.T) {
+		t.Parallel()
+
+		base := time.Now()
+		reasons := make(chan SuppressReason, 1)
+		d, invocations := newRecorder(
+			WithDropCanceledCalls(),
+			WithCloseTimeout(time.Second),
+			WithOnSuppressed(func(reason SuppressReason) {
+				reasons <- reason
+			}),
+		)
+
+		canceled, cancel := context.WithDeadline(
+			context.Background(), base.Add(time.Minute),
+		)
+		d.DebounceCtx(canceled)
+		d.DebounceCtx(withDeadline(base.Add(time.Hour)))
+		d.DebounceCtx(withDeadline(base.Add(-time.Second)))
+		d.Debounce()
+		cancel()
+		require.NoError(t, d.Flush())
+
+		inv := <-invocations
+		assert.Equal(t, 2, inv.calls)
+		assert.True(t, base.Add(time.Hour).Equal(inv.deadline))
+		assert.NoError(t, inv.err)
+
+		// An invocation left without calls is skipped.
+		d.DebounceCtx(canceled)
+		d.DebounceCtx(canceled)
+		require.NoError(t, d.Flush())
+
+		assert.Equal(t, SuppressCanceled, <-reasons)
+		require.NoError(t, d.Close())
+		stats := d.Stats()
+		assert.Equal(t, 1, stats.Skipped)
+		assert.Equal(t, 1, stats.Invocations)
+		assert.Empty(t, invocations)
+	})
+}
+
+type fakeClock struct {
+	mux sync.Mutex
+	t   time.Time
+}
+
+func (fc *fakeClock) Now() time.Time {
+	fc.mux.Lock()
+	defer fc.mux.Unlock()
+
+	return fc.t
+}
+
+func (fc *fakeClock) Add(d time.Duration) {
+	fc.mux.Lock()
+	defer fc.mux.Unlock()
+
+	fc.t = fc.t.Add(d)
+}
+
+func TestDebouncer_clockSteps(t *testing.T) {
+	t.Parallel()
+
+	t.Run("backwards", func(t *testing.T) {
+		t.Parallel()
+
+		// Wall clock times without monotonic readings.
+		clock := &fakeClock{t: time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)}
+		calls := make(chan struct{}, 10)
+		d := NewDebouncer(20*time.Millisecond, func() {
+			calls <- struct{}{}
+		}, WithBurstPassThrough(1))
+		d.now = clock.Now
+
+		d.Debounce() // passed through
+		<-calls
+
+		// The clock steps back two hours, which must neither be seen as the
+		// debouncer having been idle, nor fire anything early.
+		clock.Add(-2 * time.Hour)
+		d.Debounce()
+
+		select {
+		case <-calls:
+			t.Fatal("invoked right after clock stepped backwards")
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		select {
+		case <-calls:
+		case <-time.After(time.Second):
+			t.Fatal("pending invocation was delayed")
+		}
+	})
+
+	t.Run("forwards", func(t *testing.T) {
+		t.Parallel()
+
+		clock := &fakeClock{t: time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)}
+		calls := make(chan struct{}, 10)
+		d := NewDebouncer(20*time.Millisecond, func() {
+			calls <- struct{}{}
+		})
+		d.now = clock.Now
+
+		start := time.Now()
+		d.Debounce()
+		clock.Add(2 * time.Hour)
+
+		select {
+		case <-calls:
+			assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+		case <-time.After(time.Second):
+			t.Fatal("pending invocation was delayed")
+		}
+	})
+}
+
+func TestElapsed(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Minute, elapsed(start, start.Add(time.Minute)))
+	assert.Equal(t, time.Duration(0), elapsed(start, start.Add(-time.Hour)))
+}
+
+func TestDebouncer_Pending(t *testing.T) {
+	t.Parallel()
+
+	fired := make(chan struct{}, 1)
+	d := NewDebouncer(10*time.Millisecond, func() {
+		fired <- struct{}{}
+	})
+
+	assert.False(t, d.Pending())
+	d.Debounce()
+	assert.True(t, d.Pending())
+	<-fired
+	assert.False(t, d.Pending())
+
+	d.Debounce()
+	d.Cancel()
+	assert.False(t, d.Pending())
+}
+
+func TestDebouncer_Flush(t *testing.T) {
+	t.Parallel()
+
+	fired := make(chan time.Time, 2)
+	d := NewDebouncer(50*time.Millisecond, func() {
+		fired <- time.Now()
+	}, WithQuota(1, time.Hour))
+
+	// Nothing pending.
+	assert.ErrorIs(t, d.Flush(), ErrNothingPending)
+
+	start := time.Now()
+	d.Debounce()
+	assert.NoError(t, d.Flush())
+	assert.Less(t, (<-fired).Sub(start), 25*time.Millisecond)
+	assert.False(t, d.Pending())
+	assert.ErrorIs(t, d.Flush(), ErrNothingPending)
+
+	// The quota is exhausted, but does not hold back a flush.
+	d.Debounce()
+	assert.NoError(t, d.Flush())
+	assert.Less(t, (<-fired).Sub(start), 25*time.Millisecond)
+
+	time.Sleep(70 * time.Millisecond)
+	assert.Len(t, fired, 0)
+
+	d.Debounce()
+	assert.NoError(t, d.Close())
+	assert.ErrorIs(t, d.Flush(), ErrClosed)
+	assert.Len(t, fired, 0)
+}
+
+func TestDebouncer_DebounceWithPriority(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		calls func(d *Debouncer, f func(name string) func())
+		want  []string
+	}{
+		{
+			name: "last wins without priorities",
+			calls: func(d *Debouncer, f func(name string) func()) {
+				d.DebounceWith(f("a"))
+				d.DebounceWith(f("b"))
+			},
+			want: []string{"b"},
+		},
+		{
+			name: "higher priority is kept",
+			calls: func(d *Debouncer, f func(name string) func()) {
+				d.DebounceWithPriority(f("full"), 10)
+				d.DebounceWithPriority(f("incremental"), 1)
+				d.DebounceWith(f("plain"))
+				d.Debounce()
+			},
+			want: []string{"full"},
+		},
+		{
+			name: "equal priority replaces",
+			calls: func(d *Debouncer, f func(name string) func()) {
+				d.DebounceWithPriority(f("a"), 5)
+				d.DebounceWithPriority(f("b"), 1)
+				d.DebounceWithPriority(f("c"), 5)
+			},
+			want: []string{"c"},
+		},
+		{
+			name: "higher priority replaces",
+			calls: func(d *Debouncer, f func(name string) func()) {
+				d.DebounceWith(f("a"))
+				d.DebounceWithPriority(f("b"), 1)
+				d.DebounceWithPriority(f("c"), 2)
+			},
+			want: []string{"c"},
+		},
+		{
+			name: "default callback",
+			calls: func(d *Debouncer, f func(name string) func()) {
+				d.DebounceWith(f("a"))
+				d.Debounce()
+			},
+			want: []string{"default"},
+		},
+		{
+			name: "priority resets after invocation",
+			calls: func(d *Debouncer, f func(name string) func()) {
+				d.DebounceWithPriority(f("a"), 10)
+				d.Flush()
+				d.DebounceWithPriority(f("b"), 1)
+			},
+			want: []string{"a", "b"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mux := sync.Mutex{}
+			var got []string
+			f := func(name string) func() {
+				return func() {
+					mux.Lock()
+					defer mux.Unlock()
+					got = append(got, name)
+				}
+			}
+
+			d := NewDebouncer(10*time.Millisecond, f("default"))
+			tt.calls(d, f)
+			time.Sleep(30 * time.Millisecond)
+
+			mux.Lock()
+			defer mux.Unlock()
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDebouncer_cancelStaleTimer(t *testing.T) {
+	t.Parallel()
+
+	wait := time.Millisecond
+	var earliest int64
+	var early, invocations int64
+	d := NewDebouncer(wait, func() {
+		if time.Now().UnixNano() < atomic.LoadInt64(&earliest) {
+			atomic.AddInt64(&early, 1)
+		}
+		atomic.AddInt64(&invocations, 1)
+	})
+
+	ctx := context.Background()
+	for i := 0; i < 1000; i++ {
+		d.Cancel()
+		require.NoError(t, d.Wait(ctx))
+
+		// Cancel may race with a timer which has just expired, whose stale
+		// expiry must not invoke the burst of the call made next early.
+		atomic.StoreInt64(&earliest, time.Now().Add(wait).UnixNano())
+		d.Debounce()
+		time.Sleep(time.Duration(i%5) * wait / 2)
+	}
+	d.Cancel()
+	require.NoError(t, d.Wait(ctx))
+
+	n := atomic.LoadInt64(&invocations)
+	time.Sleep(5 * wait)
+
+	assert.Equal(t, int64(0), atomic.LoadInt64(&early))
+	assert.Equal(t, n, atomic.LoadInt64(&invocations))
+	assert.Positive(t, n)
+}
+
+func TestDebouncer_Wait(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	d := NewDebouncer(time.Millisecond, func() { <-release })
+
+	assert.Equal(t, 0, d.InFlight())
+	assert.NoError(t, d.Wait(context.Background()))
+
+	d.Debounce()
+	require.Eventually(t, func() bool { return d.InFlight() == 1 },
+		time.Second, time.Millisecond)
+
+	// Cancel does not stop an invocation which has already started.
+	d.Cancel()
+	assert.Equal(t, 1, d.InFlight())
+
+	ctx, cancel := context.WithTimeout(context.Background(),
+		10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, d.Wait(ctx), context.DeadlineExceeded)
+
+	waited := make(chan error, 1)
+	go func() { waited <- d.Wait(context.Background()) }()
+
+	select {
+	case <-waited:
+		t.Fatal("Wait returned while invocation is running")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case err := <-waited:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Wait did not return once invocation completed")
+	}
+	assert.Equal(t, 0, d.InFlight())
+}
+
+func TestDebouncer_Wait_serialized(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	calls := make(chan struct{}, 2)
+	d := NewDebouncer(time.Hour, func() {
+		calls <- struct{}{}
+		<-release
+	}, WithSerializedExecution())
+
+	d.Debounce()
+	require.NoError(t, d.Flush())
+	<-calls
+	assert.Equal(t, 1, d.InFlight())
+
+	// The execution queued behind the running one counts as in flight.
+	d.Debounce()
+	require.NoError(t, d.Flush())
+	assert.Equal(t, 2, d.InFlight())
+
+	waited := make(chan error, 1)
+	go func() { waited <- d.Wait(context.Background()) }()
+
+	release <- struct{}{}
+	<-calls
+	assert.Equal(t, 1, d.InFlight())
+	select {
+	case <-waited:
+		t.Fatal("Wait returned while queued invocation is running")
+	default:
+	}
+
+	close(release)
+	select {
+	case err := <-waited:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Wait did not return once queued invocation completed")
+	}
+	assert.Equal(t, 0, d.InFlight())
+}
@@ -0,0 +1,7 @@
+// Package debounceslog provides a log/slog Handler which coalesces repeated
+// log records, so a message logged hundreds of times per second during an
+// incident is only written once, followed by a summary of how many times it
+// repeated.
+//
+// The package requires Go 1.21 or later, as it builds on log/slog.
+package debounceslog
@@ -0,0 +1,144 @@
+//go:build go1.21
+
+package debounceslog
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/romdo/go-debounce"
+)
+
+// SuppressedKey is the key of the attribute holding the number of records
+// suppressed, which is added to summary records.
+const SuppressedKey = "suppressed"
+
+// Handler is a slog.Handler which coalesces repeated log records before
+// passing them to another slog.Handler.
+//
+// The first record for a key is passed through right away. Further records
+// for the key are suppressed, until none have been handled for the wait time,
+// or until the maximum wait time set with debounce.WithMaxWait has elapsed.
+// The last suppressed record is then passed through as a summary, with an
+// attribute holding the number of records suppressed, and the next record for
+// the key is passed through right away again.
+//
+// All methods are safe for concurrent use in goroutines.
+type Handler struct {
+	next  slog.Handler
+	wait  time.Duration
+	key   func(r slog.Record) string
+	opts  []debounce.Option
+	group *debounce.Group[string]
+
+	mux     sync.Mutex
+	pending map[string]*suppressed
+}
+
+// suppressed holds the records suppressed for a key.
+type suppressed struct {
+	last  slog.Record
+	count int
+}
+
+// NewHandler returns a new Handler which passes records to next, coalescing
+// records with the same level and message.
+//
+// Optional behavior can be configured by passing one or more debounce.Option
+// values, which apply to each key as for a debounce.Group. Keys are evicted
+// once idle for the wait time, unless debounce.WithGroupMaxIdle is passed.
+func NewHandler(
+	next slog.Handler,
+	wait time.Duration,
+	opts ...debounce.Option,
+) *Handler {
+	return NewHandlerFunc(next, wait, levelMessage, opts...)
+}
+
+// NewHandlerFunc returns a new Handler like NewHandler, but which coalesces
+// records for which key returns the same key.
+func NewHandlerFunc(
+	next slog.Handler,
+	wait time.Duration,
+	key func(r slog.Record) string,
+	opts ...debounce.Option,
+) *Handler {
+	h := &Handler{
+		next:    next,
+		wait:    wait,
+		key:     key,
+		opts:    opts,
+		pending: map[string]*suppressed{},
+	}
+	groupOpts := append([]debounce.Option{
+		debounce.WithGroupMaxIdle(wait),
+	}, opts...)
+	h.group = debounce.NewGroup(wait, h.summarize, groupOpts...)
+
+	return h
+}
+
+// Enabled reports whether the next handler handles records at the given
+// level.
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle passes r to the next handler if it is the first record for its key,
+// and suppresses it otherwise.
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	key := h.key(r)
+
+	h.mux.Lock()
+	s, ok := h.pending[key]
+	if !ok {
+		h.pending[key] = &suppressed{}
+	} else {
+		s.last = r.Clone()
+		s.count++
+	}
+	h.mux.Unlock()
+
+	h.group.Debounce(key)
+	if ok {
+		return nil
+	}
+
+	return h.next.Handle(ctx, r)
+}
+
+// WithAttrs returns a new Handler passing records to the next handler with
+// the given attributes added. It coalesces records separately from h.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return NewHandlerFunc(h.next.WithAttrs(attrs), h.wait, h.key, h.opts...)
+}
+
+// WithGroup returns a new Handler passing records to the next handler with
+// the given group. It coalesces records separately from h.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return NewHandlerFunc(h.next.WithGroup(name), h.wait, h.key, h.opts...)
+}
+
+// summarize ends the window of key, and passes a summary of the records
+// suppressed during it to the next handler, if any.
+func (h *Handler) summarize(key string) {
+	h.mux.Lock()
+	s := h.pending[key]
+	delete(h.pending, key)
+	h.mux.Unlock()
+
+	if s == nil || s.count == 0 {
+		return
+	}
+
+	r := s.last
+	r.AddAttrs(slog.Int(SuppressedKey, s.count))
+	_ = h.next.Handle(context.Background(), r)
+}
+
+// levelMessage is the default key of a record, made of its level and message.
+func levelMessage(r slog.Record) string {
+	return r.Level.String() + " " + r.Message
+}
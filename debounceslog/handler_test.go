@@ -0,0 +1,197 @@
+//go:build go1.21
+
+package debounceslog
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/romdo/go-debounce"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder is a slog.Handler which records the records passed to it.
+type recorder struct {
+	mux     *sync.Mutex
+	records *[]slog.Record
+	attrs   []slog.Attr
+}
+
+func newRecorder() *recorder {
+	return &recorder{mux: &sync.Mutex{}, records: &[]slog.Record{}}
+}
+
+func (r *recorder) Enabled(context.Context, slog.Level) bool { return true }
+
+func (r *recorder) Handle(_ context.Context, rec slog.Record) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	rec = rec.Clone()
+	rec.AddAttrs(r.attrs...)
+	*r.records = append(*r.records, rec)
+
+	return nil
+}
+
+func (r *recorder) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &recorder{
+		mux:     r.mux,
+		records: r.records,
+		attrs:   append(r.attrs[:len(r.attrs):len(r.attrs)], attrs...),
+	}
+}
+
+func (r *recorder) WithGroup(string) slog.Handler { return r }
+
+// get returns the messages and attributes of the records so far.
+func (r *recorder) get() []map[string]any {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	var got []map[string]any
+	for _, rec := range *r.records {
+		m := map[string]any{"msg": rec.Message}
+		rec.Attrs(func(a slog.Attr) bool {
+			m[a.Key] = a.Value.Any()
+
+			return true
+		})
+		got = append(got, m)
+	}
+
+	return got
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("passes through first record", func(t *testing.T) {
+		t.Parallel()
+
+		rec := newRecorder()
+		log := slog.New(NewHandler(rec, 20*time.Millisecond))
+
+		log.Warn("disk full")
+		log.Info("disk full")
+		log.Warn("other")
+
+		assert.Equal(t, []map[string]any{
+			{"msg": "disk full"},
+			{"msg": "disk full"},
+			{"msg": "other"},
+		}, rec.get())
+
+		time.Sleep(40 * time.Millisecond)
+		assert.Len(t, rec.get(), 3)
+	})
+
+	t.Run("summarizes suppressed records", func(t *testing.T) {
+		t.Parallel()
+
+		rec := newRecorder()
+		log := slog.New(NewHandler(rec, 20*time.Millisecond))
+
+		for i := 0; i < 5; i++ {
+			log.Warn("disk full", "i", i)
+		}
+		assert.Len(t, rec.get(), 1)
+
+		time.Sleep(40 * time.Millisecond)
+		assert.Equal(t, []map[string]any{
+			{"msg": "disk full", "i": int64(0)},
+			{"msg": "disk full", "i": int64(4), SuppressedKey: int64(4)},
+		}, rec.get())
+
+		// The next record starts a new window.
+		log.Warn("disk full", "i", 5)
+		assert.Len(t, rec.get(), 3)
+	})
+
+	t.Run("max wait", func(t *testing.T) {
+		t.Parallel()
+
+		rec := newRecorder()
+		log := slog.New(NewHandler(rec, 20*time.Millisecond,
+			debounce.WithMaxWait(45*time.Millisecond),
+		))
+
+		for i := 0; i < 6; i++ {
+			log.Warn("disk full")
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		// records at 10ms to 40ms are summarized when maxWait expires at
+		// 45ms, and the record at 50ms starts a new window
+		got := rec.get()
+		require.Len(t, got, 3)
+		assert.Equal(t, int64(4), got[1][SuppressedKey])
+		assert.NotContains(t, got[2], SuppressedKey)
+	})
+
+	t.Run("key function", func(t *testing.T) {
+		t.Parallel()
+
+		rec := newRecorder()
+		log := slog.New(NewHandlerFunc(rec, 20*time.Millisecond,
+			func(slog.Record) string { return "all" },
+		))
+
+		log.Warn("a")
+		log.Error("b")
+		log.Info("c")
+		time.Sleep(40 * time.Millisecond)
+
+		assert.Equal(t, []map[string]any{
+			{"msg": "a"},
+			{"msg": "c", SuppressedKey: int64(2)},
+		}, rec.get())
+	})
+
+	t.Run("with attrs", func(t *testing.T) {
+		t.Parallel()
+
+		rec := newRecorder()
+		log := slog.New(NewHandler(rec, 20*time.Millisecond))
+		sub := log.With("sub", true)
+
+		log.Warn("disk full")
+		sub.Warn("disk full")
+		sub.Warn("disk full")
+		time.Sleep(40 * time.Millisecond)
+
+		assert.Equal(t, []map[string]any{
+			{"msg": "disk full"},
+			{"msg": "disk full", "sub": true},
+			{"msg": "disk full", "sub": true, SuppressedKey: int64(1)},
+		}, rec.get())
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		t.Parallel()
+
+		rec := newRecorder()
+		log := slog.New(NewHandler(rec, 20*time.Millisecond))
+
+		wg := sync.WaitGroup{}
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 10; j++ {
+					log.Warn("disk full")
+				}
+			}()
+		}
+		wg.Wait()
+		time.Sleep(40 * time.Millisecond)
+
+		got := rec.get()
+		require.Len(t, got, 2)
+		assert.Equal(t, int64(99), got[1][SuppressedKey])
+	})
+}
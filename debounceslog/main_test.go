@@ -0,0 +1,30 @@
+//go:build go1.21
+
+package debounceslog
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"testing"
+)
+
+var maxRetries = flag.Int("max-retries", 0, "Maximum number of retries")
+
+// Due to the timing-based nature of the test suite, we want to support
+// automatically retrying the tests a few times to avoid flakiness.
+func TestMain(m *testing.M) {
+	flag.Parse()
+
+	code := m.Run()
+
+	for i := 0; code != 0 && i < *maxRetries; i++ {
+		fmt.Fprintf(os.Stderr,
+			"===\n=== WARN  Tests failed, retrying (%d/%d)...\n===\n",
+			i+1, *maxRetries,
+		)
+		code = m.Run()
+	}
+
+	os.Exit(code)
+}
@@ -0,0 +1,71 @@
+package debounce
+
+import "time"
+
+// DebounceTimer is a debouncer with an API like time.Timer, for code which is
+// structured around selecting on a timer's channel, rather than around
+// callback functions.
+//
+// Calls to Touch are debounced like calls to a function returned by New, and
+// each invocation sends the current time on C instead of calling a callback
+// function. C has a buffer of one. A tick which is due while the previous one
+// has not been received yet is coalesced into it, so at most one tick is ever
+// pending.
+//
+// All methods are safe for concurrent use in goroutines.
+type DebounceTimer struct {
+	// C is the channel on which the ticks are delivered. It is never closed.
+	C <-chan time.Time
+
+	c chan time.Time
+	d *Debouncer
+}
+
+// NewDebounceTimer returns a new DebounceTimer, which sends on its channel
+// once Touch has not been called for the wait time.
+//
+// Optional behavior can be configured by passing one or more Option values,
+// like WithBurstPassThrough for a tick on the leading edge of a burst, and
+// WithMaxWait.
+func NewDebounceTimer(wait time.Duration, opts ...Option) *DebounceTimer {
+	c := make(chan time.Time, 1)
+	t := &DebounceTimer{C: c, c: c}
+	t.d = NewDebouncer(wait, func() {
+		select {
+		case c <- time.Now():
+		default:
+		}
+	}, opts...)
+
+	return t
+}
+
+// Touch schedules a tick, postponing any pending tick, like calling a function
+// returned by New.
+func (t *DebounceTimer) Touch() {
+	t.d.Debounce()
+}
+
+// Reset discards any pending tick, along with a tick sent on C which has not
+// been received yet, so the next tick is for calls to Touch made afterwards.
+func (t *DebounceTimer) Reset() {
+	t.d.Cancel()
+
+	select {
+	case <-t.c:
+	default:
+	}
+}
+
+// Stop discards any pending tick, and stops the DebounceTimer, making further
+// calls to Touch have no effect. It reports if a tick was pending, like
+// time.Timer's Stop method. A tick sent on C before Stop was called can still
+// be received.
+func (t *DebounceTimer) Stop() bool {
+	pending := t.d.Pending()
+	// Like time.Timer's Stop, Stop has no error to report, and Close can only
+	// fail with ErrTimeout due to WithCloseTimeout.
+	_ = t.d.Close()
+
+	return pending
+}
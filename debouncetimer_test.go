@@ -0,0 +1,142 @@
+package debounce
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDebounceTimer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("burst", func(t *testing.T) {
+		t.Parallel()
+
+		timer := NewDebounceTimer(20 * time.Millisecond)
+		defer timer.Stop()
+
+		start := time.Now()
+		timer.Touch()
+		time.Sleep(10 * time.Millisecond)
+		timer.Touch()
+
+		select {
+		case at := <-timer.C:
+			// from call at 10ms (+20ms wait = 30ms)
+			assert.GreaterOrEqual(t, at.Sub(start), 30*time.Millisecond)
+			assert.Less(t, at.Sub(start), 45*time.Millisecond)
+		case <-time.After(time.Second):
+			t.Fatal("no tick")
+		}
+
+		select {
+		case <-timer.C:
+			t.Fatal("unexpected tick")
+		case <-time.After(40 * time.Millisecond):
+		}
+	})
+
+	t.Run("leading and max wait", func(t *testing.T) {
+		t.Parallel()
+
+		timer := NewDebounceTimer(20*time.Millisecond,
+			WithBurstPassThrough(1), WithMaxWait(30*time.Millisecond),
+		)
+		defer timer.Stop()
+
+		start := time.Now()
+		var ticks []time.Duration
+		deadline := time.After(100 * time.Millisecond)
+		touch := time.NewTicker(10 * time.Millisecond)
+		defer touch.Stop()
+
+		// touch at 0ms, 10ms, 20ms and 30ms
+		timer.Touch()
+		touches := 1
+		for len(ticks) < 2 {
+			select {
+			case at := <-timer.C:
+				ticks = append(ticks, at.Sub(start))
+			case <-touch.C:
+				if touches < 4 {
+					touches++
+					timer.Touch()
+				}
+			case <-deadline:
+				t.Fatalf("missing ticks, got %v", ticks)
+			}
+		}
+
+		// leading at 0ms, and maxWait from the call at 10ms at 40ms, which
+		// covers the last call at 30ms
+		assert.Less(t, ticks[0], 5*time.Millisecond)
+		assert.InDelta(t, 40*time.Millisecond, ticks[1],
+			float64(5*time.Millisecond))
+
+		select {
+		case <-timer.C:
+			t.Fatal("unexpected tick")
+		case <-time.After(40 * time.Millisecond):
+		}
+	})
+
+	t.Run("unreceived ticks are coalesced", func(t *testing.T) {
+		t.Parallel()
+
+		timer := NewDebounceTimer(5 * time.Millisecond)
+		defer timer.Stop()
+
+		timer.Touch()
+		time.Sleep(15 * time.Millisecond)
+		timer.Touch()
+		time.Sleep(15 * time.Millisecond)
+
+		assert.Len(t, timer.C, 1)
+		<-timer.C
+		assert.Len(t, timer.C, 0)
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		t.Parallel()
+
+		timer := NewDebounceTimer(5 * time.Millisecond)
+		defer timer.Stop()
+
+		timer.Touch()
+		time.Sleep(15 * time.Millisecond)
+		timer.Touch()
+		timer.Reset()
+		time.Sleep(15 * time.Millisecond)
+		assert.Len(t, timer.C, 0)
+
+		timer.Touch()
+		select {
+		case <-timer.C:
+		case <-time.After(time.Second):
+			t.Fatal("no tick")
+		}
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		t.Parallel()
+
+		timer := NewDebounceTimer(5*time.Millisecond, WithWorker())
+		assert.False(t, timer.Stop())
+
+		timer = NewDebounceTimer(5*time.Millisecond, WithWorker())
+		timer.Touch()
+		assert.True(t, timer.Stop())
+
+		timer.Touch()
+		time.Sleep(15 * time.Millisecond)
+		assert.Len(t, timer.C, 0)
+
+		// The worker goroutine exits once stopped.
+		select {
+		case <-timer.d.worker.exited:
+		case <-time.After(time.Second):
+			t.Fatal("worker did not exit")
+		}
+	})
+}
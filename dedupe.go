@@ -0,0 +1,43 @@
+package debounce
+
+import "time"
+
+// NewDedupe returns a debounced function like NewTyped, but which ignores calls
+// passing the same value as the one pending, so repeating a value does not
+// postpone its invocation of f. A call passing a different value behaves as
+// usual. Ignored calls are counted as suppressed in Stats.
+//
+// The returned cancel function can be used to cancel any pending invocation of
+// f, discarding its value, but is not required to be called, so can be ignored
+// if not needed.
+//
+// Both debounced and cancel functions are safe for concurrent use in
+// goroutines, and can both be called multiple times.
+func NewDedupe[T comparable](
+	wait time.Duration,
+	f func(value T),
+	opts ...Option,
+) (debounced func(value T), cancel func()) {
+	return NewDedupeFunc(wait, func(a, b T) bool { return a == b }, f, opts...)
+}
+
+// NewDedupeFunc returns a debounced function like NewDedupe, but for values
+// which are not comparable, using equal to compare them instead. The equal
+// function is called while holding the debounced function's internal lock, so
+// it should return quickly, and must not call the debounced function.
+func NewDedupeFunc[T any](
+	wait time.Duration,
+	equal func(a, b T) bool,
+	f func(value T),
+	opts ...Option,
+) (debounced func(value T), cancel func()) {
+	d := newTyped(wait, f, opts)
+	d.same = func(pending, value interface{}) bool {
+		a, _ := pending.(T)
+		b, _ := value.(T)
+
+		return equal(a, b)
+	}
+
+	return func(value T) { d.add(value) }, d.cancelFunc()
+}
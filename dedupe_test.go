@@ -0,0 +1,139 @@
+package debounce
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDedupe(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		wait       time.Duration
+		calls      []typedOp
+		wantValues map[time.Duration][]int
+	}{
+		{
+			name: "repeated value does not postpone",
+			wait: 20 * time.Millisecond,
+			calls: []typedOp{
+				{delay: 10 * time.Millisecond, value: 1},
+				{delay: 20 * time.Millisecond, value: 1},
+				{delay: 25 * time.Millisecond, value: 1},
+			},
+			wantValues: map[time.Duration][]int{
+				25 * time.Millisecond: nil,
+				// from call at 10ms (+20ms wait = 30ms)
+				35 * time.Millisecond:  {1},
+				150 * time.Millisecond: {1},
+			},
+		},
+		{
+			name: "changed value postpones",
+			wait: 20 * time.Millisecond,
+			calls: []typedOp{
+				{delay: 10 * time.Millisecond, value: 1},
+				{delay: 20 * time.Millisecond, value: 2},
+				{delay: 25 * time.Millisecond, value: 2},
+			},
+			wantValues: map[time.Duration][]int{
+				35 * time.Millisecond: nil,
+				// from call at 20ms (+20ms wait = 40ms)
+				45 * time.Millisecond:  {2},
+				150 * time.Millisecond: {2},
+			},
+		},
+		{
+			name: "value changed back postpones",
+			wait: 20 * time.Millisecond,
+			calls: []typedOp{
+				{delay: 10 * time.Millisecond, value: 1},
+				{delay: 15 * time.Millisecond, value: 2},
+				{delay: 20 * time.Millisecond, value: 1},
+			},
+			wantValues: map[time.Duration][]int{
+				35 * time.Millisecond: nil,
+				// from call at 20ms (+20ms wait = 40ms)
+				45 * time.Millisecond:  {1},
+				150 * time.Millisecond: {1},
+			},
+		},
+		{
+			name: "same value after invocation",
+			wait: 20 * time.Millisecond,
+			calls: []typedOp{
+				{delay: 10 * time.Millisecond, value: 1},
+				{delay: 40 * time.Millisecond, value: 1},
+			},
+			wantValues: map[time.Duration][]int{
+				// from call at 10ms (+20ms wait = 30ms)
+				35 * time.Millisecond: {1},
+				// from call at 40ms (+20ms wait = 60ms)
+				65 * time.Millisecond:  {1, 1},
+				150 * time.Millisecond: {1, 1},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mux := sync.RWMutex{}
+
+			var got []int
+			d, _ := NewDedupe(tt.wait, func(value int) {
+				mux.Lock()
+				defer mux.Unlock()
+				got = append(got, value)
+			})
+
+			wg := sync.WaitGroup{}
+			for _, op := range tt.calls {
+				wg.Add(1)
+				go func(op typedOp) {
+					defer wg.Done()
+					time.Sleep(op.delay)
+					d(op.value)
+				}(op)
+			}
+
+			for delay, values := range tt.wantValues {
+				wg.Add(1)
+				go func(interval time.Duration, values []int) {
+					defer wg.Done()
+					time.Sleep(interval)
+
+					mux.RLock()
+					defer mux.RUnlock()
+					assert.Equal(t, values, got, "at %s", interval)
+				}(delay, values)
+			}
+
+			wg.Wait()
+		})
+	}
+}
+
+func TestNewDedupeFunc(t *testing.T) {
+	t.Parallel()
+
+	got := make(chan []byte, 2)
+	d, _ := NewDedupeFunc(20*time.Millisecond, bytes.Equal, func(b []byte) {
+		got <- b
+	})
+
+	d([]byte("a"))
+	time.Sleep(10 * time.Millisecond)
+	start := time.Now()
+	d([]byte("a"))
+
+	assert.Equal(t, []byte("a"), <-got)
+	// The repeated value did not postpone the invocation.
+	assert.Less(t, time.Since(start), 15*time.Millisecond)
+}
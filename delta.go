@@ -0,0 +1,112 @@
+package debounce
+
+import (
+	"sync"
+	"time"
+)
+
+// NewDelta returns a debounced function like NewTyped, but which passes f both
+// the value of the previous invocation and the new one, so f can act on the
+// difference between them. The first invocation is passed the zero value of T
+// as prev. An invocation only becomes the previous one for later invocations
+// once f has returned.
+//
+// The returned cancel function can be used to cancel any pending invocation of
+// f, discarding its value, but is not required to be called, so can be ignored
+// if not needed. With WithDeltaReset, cancel also forgets the previous value,
+// so the next invocation is passed the zero value as prev again.
+//
+// Both debounced and cancel functions are safe for concurrent use in
+// goroutines, and can both be called multiple times.
+//
+// Optional behavior can be configured by passing one or more DeltaOption
+// values, which include all Option values.
+func NewDelta[T any](
+	wait time.Duration,
+	f func(prev, curr T),
+	opts ...DeltaOption,
+) (debounced func(value T), cancel func()) {
+	o := deltaOptions{}
+	for _, opt := range opts {
+		opt.applyDelta(&o)
+	}
+
+	dl := &delta[T]{f: f}
+	d := newTyped(wait, dl.invoke, o.opts)
+
+	cancel = d.cancelFunc()
+	if o.reset {
+		cancelDebouncer := cancel
+		cancel = func() {
+			cancelDebouncer()
+			dl.reset()
+		}
+	}
+
+	return func(value T) { d.add(value) }, cancel
+}
+
+// DeltaOption configures optional behavior of a debounced function returned by
+// NewDelta. Any Option is a DeltaOption, and so is WithDeltaReset.
+type DeltaOption interface {
+	applyDelta(o *deltaOptions)
+}
+
+type deltaOptions struct {
+	opts  []Option
+	reset bool
+}
+
+type deltaOption func(o *deltaOptions)
+
+func (f deltaOption) applyDelta(o *deltaOptions) {
+	f(o)
+}
+
+func (f Option) applyDelta(o *deltaOptions) {
+	o.opts = append(o.opts, f)
+}
+
+// WithDeltaReset makes the cancel function returned by NewDelta also forget
+// the previous value, so the next invocation is passed the zero value as prev.
+func WithDeltaReset() DeltaOption {
+	return deltaOption(func(o *deltaOptions) {
+		o.reset = true
+	})
+}
+
+// delta keeps track of the previous value passed to a function returned by
+// NewDelta.
+type delta[T any] struct {
+	f func(prev, curr T)
+
+	mux  sync.Mutex
+	prev T
+	gen  uint64
+}
+
+func (dl *delta[T]) invoke(curr T) {
+	dl.mux.Lock()
+	prev, gen := dl.prev, dl.gen
+	dl.mux.Unlock()
+
+	dl.f(prev, curr)
+
+	dl.mux.Lock()
+	defer dl.mux.Unlock()
+
+	// Invocations which started before a reset must not bring back a value
+	// from before it.
+	if dl.gen == gen {
+		dl.prev = curr
+	}
+}
+
+func (dl *delta[T]) reset() {
+	dl.mux.Lock()
+	defer dl.mux.Unlock()
+
+	var zero T
+	dl.prev = zero
+	dl.gen++
+}
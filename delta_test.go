@@ -0,0 +1,63 @@
+package debounce
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type deltaPair struct {
+	prev int
+	curr int
+}
+
+func TestNewDelta(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		opts []DeltaOption
+		want []deltaPair
+	}{
+		{
+			name: "cancel keeps previous value",
+			want: []deltaPair{{0, 2}, {2, 4}, {4, 6}},
+		},
+		{
+			name: "cancel with reset forgets previous value",
+			opts: []DeltaOption{WithDeltaReset()},
+			want: []deltaPair{{0, 2}, {2, 4}, {0, 6}},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := make(chan deltaPair, 10)
+			d, cancel := NewDelta(10*time.Millisecond, func(prev, curr int) {
+				got <- deltaPair{prev, curr}
+			}, tt.opts...)
+
+			d(1)
+			d(2)
+			assert.Equal(t, tt.want[0], <-got)
+
+			d(3)
+			d(4)
+			assert.Equal(t, tt.want[1], <-got)
+
+			d(5)
+			cancel()
+			d(6)
+			assert.Equal(t, tt.want[2], <-got)
+
+			select {
+			case p := <-got:
+				assert.Failf(t, "unexpected invocation", "%v", p)
+			case <-time.After(30 * time.Millisecond):
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package debounce
+
+import "errors"
+
+var (
+	// ErrCanceled is the error of a Promise for a call which is not covered by
+	// any invocation of the callback function, as it was canceled with Cancel,
+	// the Debouncer was closed, or the call or its invocation was suppressed.
+	ErrCanceled = errors.New("debounce: canceled")
+
+	// ErrClosed is returned by operations on a Debouncer, or a type built on
+	// one like a Writer, which has been closed. It is also the error of a
+	// Promise returned by a Syncer which has been closed, and of messages a
+	// Consumer stopped without processing.
+	ErrClosed = errors.New("debounce: closed")
+
+	// ErrNothingPending is returned by Debouncer.Flush when no invocation is
+	// pending, so there is nothing to flush.
+	ErrNothingPending = errors.New("debounce: nothing pending")
+
+	// ErrTimeout is returned by Close when invocations are still running once
+	// the grace period set with WithCloseTimeout has expired. It is also the
+	// error of a batch delivered by a Batcher created with NewAckBatcher which
+	// is not acknowledged in time.
+	ErrTimeout = errors.New("debounce: timeout")
+
+	// ErrNacked is the error of a batch delivered by a Batcher created with
+	// NewAckBatcher which is rejected.
+	ErrNacked = errors.New("debounce: batch rejected")
+
+	// ErrInvalidOption is returned when building a Debouncer with invalid
+	// settings, like a negative wait time.
+	ErrInvalidOption = errors.New("debounce: invalid option")
+)
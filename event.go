@@ -0,0 +1,59 @@
+package debounce
+
+import (
+	"context"
+	"time"
+)
+
+// NewMultiEvent returns a debounced function like New, but which is called with
+// the name of an event, and passes f the number of times each event was fired
+// since the previous invocation. Each invocation of f receives a new map,
+// which f is free to keep or modify.
+//
+// When the first calls of a burst are let through with WithBurstPassThrough,
+// each of their invocations is passed a map holding only the event which
+// triggered it.
+//
+// The returned cancel function can be used to cancel any pending invocation of
+// f, discarding the events counted for it, but is not required to be called,
+// so can be ignored if not needed.
+//
+// Both fire and cancel functions are safe for concurrent use in goroutines,
+// and can both be called multiple times.
+func NewMultiEvent(
+	wait time.Duration,
+	f func(events map[string]int),
+	opts ...Option,
+) (fire func(event string), cancel func()) {
+	d := newDebouncer(wait, func(_ context.Context, info InvokeInfo) {
+		f(countEvents(info.value))
+	}, opts)
+	d.combine = func(acc, next interface{}) interface{} {
+		events := countEvents(acc)
+		switch next := next.(type) {
+		case string:
+			events[next]++
+		case map[string]int:
+			for event, n := range next {
+				events[event] += n
+			}
+		}
+
+		return events
+	}
+
+	return func(event string) { d.add(event) }, d.cancelFunc()
+}
+
+// countEvents returns value as a map of event counts, which is either value
+// itself, or a new map counting value once if it is the name of a single event.
+func countEvents(value interface{}) map[string]int {
+	switch value := value.(type) {
+	case map[string]int:
+		return value
+	case string:
+		return map[string]int{value: 1}
+	default:
+		return map[string]int{}
+	}
+}
@@ -0,0 +1,61 @@
+package debounce
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMultiEvent(t *testing.T) {
+	t.Parallel()
+
+	t.Run("mixed events", func(t *testing.T) {
+		t.Parallel()
+
+		got := make(chan map[string]int, 2)
+		fire, _ := NewMultiEvent(20*time.Millisecond, func(e map[string]int) {
+			got <- e
+		})
+
+		fire("rows")
+		fire("schema")
+		fire("rows")
+		fire("rows")
+		assert.Equal(t, map[string]int{"rows": 3, "schema": 1}, <-got)
+
+		fire("acl")
+		assert.Equal(t, map[string]int{"acl": 1}, <-got)
+	})
+
+	t.Run("leading", func(t *testing.T) {
+		t.Parallel()
+
+		got := make(chan map[string]int, 2)
+		fire, _ := NewMultiEvent(20*time.Millisecond, func(e map[string]int) {
+			got <- e
+		}, WithBurstPassThrough(1), WithSerializedExecution())
+
+		fire("rows")
+		fire("schema")
+		fire("acl")
+		fire("schema")
+		assert.Equal(t, map[string]int{"rows": 1}, <-got)
+		assert.Equal(t, map[string]int{"schema": 2, "acl": 1}, <-got)
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		t.Parallel()
+
+		got := make(chan map[string]int, 2)
+		fire, cancel := NewMultiEvent(
+			20*time.Millisecond,
+			func(e map[string]int) { got <- e },
+		)
+
+		fire("rows")
+		cancel()
+		fire("acl")
+		assert.Equal(t, map[string]int{"acl": 1}, <-got)
+	})
+}
@@ -0,0 +1,122 @@
+package debounce
+
+import "sync"
+
+// Executor runs invocations of callback functions, allowing them to be run on
+// a specific goroutine, like a GUI main loop or a game tick loop.
+type Executor interface {
+	// Execute runs f, or arranges for f to be run at a later time.
+	Execute(f func())
+}
+
+// ExecutorFunc is an adapter to allow the use of ordinary functions as
+// Executors.
+type ExecutorFunc func(f func())
+
+// Execute calls ef(f).
+func (ef ExecutorFunc) Execute(f func()) {
+	ef(f)
+}
+
+// goExecutor is the default Executor, which runs each function in a new
+// goroutine.
+type goExecutor struct{}
+
+func (goExecutor) Execute(f func()) {
+	go f()
+}
+
+// worker is an Executor which runs functions one at a time on a single
+// goroutine.
+//
+// Functions are queued rather than handed over on an unbuffered channel, so
+// Execute never blocks, even when called from the function being run by the
+// worker. The queue needs no bound of its own, as each queued function is an
+// invocation which has already been counted as running by the Debouncer.
+type worker struct {
+	mux     sync.Mutex
+	queue   []func()
+	stopped bool
+	notify  chan struct{}
+	done    chan struct{}
+	exited  chan struct{}
+	once    sync.Once
+}
+
+func newWorker() *worker {
+	w := &worker{
+		notify: make(chan struct{}, 1),
+		done:   make(chan struct{}),
+		exited: make(chan struct{}),
+	}
+	go w.loop()
+
+	return w
+}
+
+// Execute queues f to be run by the worker goroutine. Once the worker has been
+// stopped, f is run on a new goroutine instead, like with the default
+// Executor.
+func (w *worker) Execute(f func()) {
+	w.mux.Lock()
+	if w.stopped {
+		w.mux.Unlock()
+		go f()
+
+		return
+	}
+	w.queue = append(w.queue, f)
+	w.mux.Unlock()
+
+	select {
+	case w.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (w *worker) loop() {
+	defer close(w.exited)
+
+	for {
+		select {
+		case <-w.done:
+			w.drain()
+
+			return
+		case <-w.notify:
+		}
+
+		w.drain()
+	}
+}
+
+// drain runs queued functions until the queue is empty.
+func (w *worker) drain() {
+	for {
+		w.mux.Lock()
+		if len(w.queue) == 0 {
+			w.mux.Unlock()
+
+			return
+		}
+		f := w.queue[0]
+		w.queue[0] = nil
+		w.queue = w.queue[1:]
+		w.mux.Unlock()
+
+		f()
+	}
+}
+
+// stop makes the worker goroutine exit once it has run the functions already
+// queued, as they are invocations the Debouncer counts as running. Functions
+// passed to Execute afterwards run on goroutines of their own.
+func (w *worker) stop() {
+	w.once.Do(func() {
+		w.mux.Lock()
+		w.stopped = true
+		w.mux.Unlock()
+
+		close(w.done)
+	})
+}
@@ -0,0 +1,216 @@
+package debounce
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type chanExecutor chan func()
+
+func (ce chanExecutor) Execute(f func()) {
+	ce <- f
+}
+
+func TestWithExecutor(t *testing.T) {
+	t.Parallel()
+
+	exec := make(chanExecutor, 10)
+	var calls []int
+	n := 0
+
+	// The callback is only ever run from the test goroutine, so it needs no
+	// synchronization.
+	d := NewDebouncer(10*time.Millisecond, func() {
+		n++
+		calls = append(calls, n)
+	}, WithExecutor(exec), WithBurstPassThrough(2))
+
+	d.Debounce() // passed through
+	d.Debounce() // passed through
+	d.Debounce() // trailing invocation after 10ms
+
+	for i := 0; i < 3; i++ {
+		select {
+		case f := <-exec:
+			f()
+		case <-time.After(time.Second):
+			t.Fatalf("invocation %d was not delivered", i)
+		}
+	}
+
+	assert.Equal(t, []int{1, 2, 3}, calls)
+
+	select {
+	case <-exec:
+		t.Fatal("unexpected invocation")
+	case <-time.After(30 * time.Millisecond):
+	}
+}
+
+func TestExecutorFunc(t *testing.T) {
+	t.Parallel()
+
+	var got func()
+	ef := ExecutorFunc(func(f func()) { got = f })
+
+	called := false
+	ef.Execute(func() { called = true })
+	got()
+
+	assert.True(t, called)
+}
+
+func TestWithWorker(t *testing.T) {
+	t.Parallel()
+
+	t.Run("runs invocations in order", func(t *testing.T) {
+		t.Parallel()
+
+		calls := make(chan int, 10)
+		n := 0
+		d := NewDebouncer(time.Hour, func() {
+			// Only ever run on the worker goroutine, so needs no
+			// synchronization.
+			n++
+			calls <- n
+		}, WithWorker(), WithBurstPassThrough(3))
+		defer d.Close()
+
+		d.Debounce()
+		d.Debounce()
+		d.Debounce()
+
+		for i := 1; i <= 3; i++ {
+			assert.Equal(t, i, <-calls)
+		}
+	})
+
+	t.Run("callback calls back into debouncer", func(t *testing.T) {
+		t.Parallel()
+
+		calls := make(chan struct{}, 10)
+		var d *Debouncer
+		d = NewDebouncer(5*time.Millisecond, func() {
+			calls <- struct{}{}
+			if len(calls) < 3 {
+				d.Debounce()
+			}
+		}, WithWorker(), WithBurstPassThrough(1))
+		defer d.Close()
+
+		d.Debounce()
+		for i := 0; i < 3; i++ {
+			select {
+			case <-calls:
+			case <-time.After(time.Second):
+				t.Fatal("worker deadlocked")
+			}
+		}
+	})
+
+	t.Run("close while busy", func(t *testing.T) {
+		t.Parallel()
+
+		started := make(chan struct{})
+		release := make(chan struct{})
+		d := NewDebouncer(5*time.Millisecond, func() {
+			close(started)
+			<-release
+		}, WithWorker())
+
+		d.Debounce()
+		<-started
+
+		closed := make(chan struct{})
+		go func() {
+			d.Close()
+			close(closed)
+		}()
+
+		select {
+		case <-closed:
+		case <-time.After(time.Second):
+			t.Fatal("Close blocked on busy worker")
+		}
+
+		select {
+		case <-d.worker.exited:
+			t.Fatal("worker exited while callback was running")
+		default:
+		}
+
+		close(release)
+		select {
+		case <-d.worker.exited:
+		case <-time.After(time.Second):
+			t.Fatal("worker did not exit after Close")
+		}
+	})
+
+	t.Run("close with queued invocation", func(t *testing.T) {
+		t.Parallel()
+
+		started := make(chan struct{}, 2)
+		release := make(chan struct{})
+		d := NewDebouncer(time.Hour, func() {
+			started <- struct{}{}
+			<-release
+		}, WithWorker())
+
+		d.Debounce()
+		assert.NoError(t, d.Flush())
+		<-started
+
+		// Queued behind the running invocation on the worker.
+		p := d.DebounceDone()
+		assert.NoError(t, d.Flush())
+		assert.Equal(t, 2, d.InFlight())
+
+		assert.NoError(t, d.Close())
+		close(release)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		assert.NoError(t, d.Wait(ctx))
+		assert.Equal(t, 0, d.InFlight())
+
+		select {
+		case <-p.Done():
+			assert.NoError(t, p.Err())
+		case <-time.After(time.Second):
+			t.Fatal("promise of queued invocation did not complete")
+		}
+		select {
+		case <-d.worker.exited:
+		case <-time.After(time.Second):
+			t.Fatal("worker did not exit after Close")
+		}
+	})
+}
+
+func BenchmarkWithWorker(b *testing.B) {
+	for name, opts := range map[string][]Option{
+		"goroutine": nil,
+		"worker":    {WithWorker()},
+	} {
+		opts := opts
+		b.Run(name, func(b *testing.B) {
+			wg := sync.WaitGroup{}
+			d := NewDebouncer(time.Hour, wg.Done,
+				append(opts, WithBurstPassThrough(b.N))...,
+			)
+			defer d.Close()
+
+			wg.Add(b.N)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				d.Debounce()
+			}
+			wg.Wait()
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package debounce
+
+import "time"
+
+// Factory creates debounced functions and Debouncers sharing a preset wait
+// time and options, like those configured once per service, saving passing
+// the same options around to each constructor.
+//
+// A Factory is immutable, and safe for concurrent use in goroutines.
+type Factory struct {
+	wait time.Duration
+	opts []Option
+}
+
+// NewFactory returns a new Factory, which creates debounced functions with the
+// given wait time and options.
+func NewFactory(wait time.Duration, opts ...Option) *Factory {
+	// Copy the options, so the caller can't change the preset later on.
+	return &Factory{wait: wait, opts: append([]Option(nil), opts...)}
+}
+
+// New returns a debounced function like the package level New, with the
+// Factory's preset. Any options given are applied after the preset, so they
+// take precedence over it. The wait time can be overridden with WithWait.
+func (fa *Factory) New(
+	f func(),
+	opts ...Option,
+) (debounced func(), cancel func()) {
+	return New(fa.wait, f, fa.options(opts)...)
+}
+
+// NewDebouncer returns a new Debouncer like the package level NewDebouncer,
+// with the Factory's preset, and any options given taking precedence over it.
+func (fa *Factory) NewDebouncer(f func(), opts ...Option) *Debouncer {
+	return NewDebouncer(fa.wait, f, fa.options(opts)...)
+}
+
+// NewMutable returns a debounced function like the package level NewMutable,
+// with the Factory's preset, and any options given taking precedence over it.
+func (fa *Factory) NewMutable(
+	opts ...Option,
+) (debounced func(f func()), cancel func()) {
+	return NewMutable(fa.wait, fa.options(opts)...)
+}
+
+// Group returns a new Group with string keys like NewGroup, with the Factory's
+// preset applying to each key's Debouncer, and any options given taking
+// precedence over it.
+func (fa *Factory) Group(f func(key string), opts ...Option) *Group[string] {
+	return NewGroup(fa.wait, f, fa.options(opts)...)
+}
+
+// options returns the preset options followed by opts, leaving the preset
+// untouched.
+func (fa *Factory) options(opts []Option) []Option {
+	return append(fa.opts[:len(fa.opts):len(fa.opts)], opts...)
+}
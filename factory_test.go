@@ -0,0 +1,96 @@
+package debounce
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFactory(t *testing.T) {
+	t.Parallel()
+
+	t.Run("preset", func(t *testing.T) {
+		t.Parallel()
+
+		fa := NewFactory(20*time.Millisecond,
+			WithMaxWait(time.Second),
+			WithBurstPassThrough(1),
+		)
+
+		d := fa.NewDebouncer(func() {})
+		defer d.Close()
+
+		assert.Equal(t, 20*time.Millisecond, d.wait)
+		assert.Equal(t, time.Second, d.maxWait)
+		assert.Equal(t, 1, d.opts.burstPassThrough)
+	})
+
+	t.Run("overrides", func(t *testing.T) {
+		t.Parallel()
+
+		fa := NewFactory(20*time.Millisecond, WithMaxWait(time.Second))
+
+		d := fa.NewDebouncer(func() {},
+			WithWait(5*time.Millisecond),
+			WithMaxWait(time.Minute),
+		)
+		defer d.Close()
+
+		assert.Equal(t, 5*time.Millisecond, d.wait)
+		assert.Equal(t, time.Minute, d.maxWait)
+
+		// Overrides for one instance do not affect the preset.
+		other := fa.NewDebouncer(func() {})
+		defer other.Close()
+
+		assert.Equal(t, 20*time.Millisecond, other.wait)
+		assert.Equal(t, time.Second, other.maxWait)
+	})
+
+	t.Run("preset options copied", func(t *testing.T) {
+		t.Parallel()
+
+		opts := []Option{WithMaxWait(time.Second)}
+		fa := NewFactory(20*time.Millisecond, opts...)
+		opts[0] = WithMaxWait(time.Minute)
+
+		d := fa.NewDebouncer(func() {})
+		defer d.Close()
+
+		assert.Equal(t, time.Second, d.maxWait)
+	})
+
+	t.Run("constructors", func(t *testing.T) {
+		t.Parallel()
+
+		mux := sync.Mutex{}
+		var calls []string
+		record := func(name string) {
+			mux.Lock()
+			defer mux.Unlock()
+			calls = append(calls, name)
+		}
+
+		fa := NewFactory(time.Hour, WithBurstPassThrough(1))
+
+		debounced, cancel := fa.New(func() { record("new") })
+		defer cancel()
+		mutable, cancelMutable := fa.NewMutable()
+		defer cancelMutable()
+		g := fa.Group(func(key string) { record(key) })
+		defer g.CancelAll()
+
+		// With the preset, the first call of each burst invokes right away
+		// despite the long wait time.
+		debounced()
+		mutable(func() { record("mutable") })
+		g.Debounce("group")
+		time.Sleep(20 * time.Millisecond)
+
+		mux.Lock()
+		defer mux.Unlock()
+		assert.ElementsMatch(t, []string{"new", "mutable", "group"}, calls)
+	})
+}
@@ -0,0 +1,186 @@
+package debounce
+
+import (
+	"sync"
+	"time"
+)
+
+// Flusher debounces calls to the Flush method of a buffered writer, like a
+// *bufio.Writer or *gzip.Writer, so that a stream of writes is followed by a
+// single flush once the stream goes quiet, rather than a flush after every
+// write. A maximum wait time set with WithMaxWait bounds how long writes stay
+// buffered while the stream keeps going, and a threshold set with
+// WithFlushThreshold bounds how many bytes do.
+//
+// Errors returned by flushes started by the Flusher itself are passed to the
+// hook set with WithOnError, and the first of them is returned by Close.
+//
+// All methods are safe for concurrent use in goroutines. Flushes never run
+// concurrently.
+type Flusher struct {
+	f interface{ Flush() error }
+	d *Debouncer
+
+	// threshold is the threshold set with WithFlushThreshold.
+	threshold int
+
+	// mux guards written, err and closed.
+	mux     sync.Mutex
+	written int
+	err     error
+	closed  bool
+
+	// flushMux serializes flushes of f.
+	flushMux sync.Mutex
+}
+
+// NewFlusher returns a new Flusher which flushes f once Wrote has not been
+// called for the wait time.
+//
+// Optional behavior can be configured by passing one or more FlusherOption
+// values, which include all Option values.
+func NewFlusher(
+	f interface{ Flush() error },
+	wait time.Duration,
+	opts ...FlusherOption,
+) *Flusher {
+	o := flusherOptions{}
+	for _, opt := range opts {
+		opt.applyFlusher(&o)
+	}
+
+	fl := &Flusher{f: f, threshold: o.threshold}
+	fl.d = NewDebouncer(wait, fl.invoke, o.opts...)
+
+	return fl
+}
+
+// FlusherOption configures optional behavior of a Flusher. Any Option is a
+// FlusherOption, and so is WithFlushThreshold.
+type FlusherOption interface {
+	applyFlusher(o *flusherOptions)
+}
+
+type flusherOptions struct {
+	opts      []Option
+	threshold int
+}
+
+type flusherOption func(o *flusherOptions)
+
+func (f flusherOption) applyFlusher(o *flusherOptions) {
+	f(o)
+}
+
+func (f Option) applyFlusher(o *flusherOptions) {
+	o.opts = append(o.opts, f)
+}
+
+// WithFlushThreshold makes a Flusher flush right away once at least bytes
+// bytes have been written since the last flush, rather than waiting for writes
+// to stop. A threshold of 0 or less disables it, which is the default.
+func WithFlushThreshold(bytes int) FlusherOption {
+	return flusherOption(func(o *flusherOptions) {
+		o.threshold = bytes
+	})
+}
+
+// Wrote schedules a flush, and should be called after each write of n bytes.
+// If the threshold set with WithFlushThreshold has been reached, the flush
+// starts right away. Calls made after Close have no effect.
+func (fl *Flusher) Wrote(n int) {
+	fl.mux.Lock()
+	if fl.closed {
+		fl.mux.Unlock()
+
+		return
+	}
+	fl.written += n
+	full := fl.threshold > 0 && fl.written >= fl.threshold
+	fl.mux.Unlock()
+
+	fl.d.Debounce()
+	if full {
+		// The flush may have started already, or the Flusher may have been
+		// closed in the meantime, which leaves nothing to flush.
+		_ = fl.d.Flush()
+	}
+}
+
+// FlushNow flushes right away, and returns the error of the flush. Any pending
+// flush is canceled, as it is covered by this one. FlushNow returns ErrClosed
+// if the Flusher has been closed.
+func (fl *Flusher) FlushNow() error {
+	fl.mux.Lock()
+	closed := fl.closed
+	fl.mux.Unlock()
+
+	if closed {
+		return ErrClosed
+	}
+
+	fl.d.Cancel()
+
+	return fl.flush(true)
+}
+
+// Close flushes any bytes written since the last flush, and stops the
+// Flusher. It returns the error of the flush, or if it succeeded, the first
+// error of an earlier flush started by the Flusher, or ErrTimeout if running
+// flushes did not complete within the grace period set with WithCloseTimeout.
+// Close does not close the underlying writer.
+func (fl *Flusher) Close() error {
+	fl.mux.Lock()
+	fl.closed = true
+	fl.mux.Unlock()
+
+	closeErr := fl.d.Close()
+
+	if err := fl.flush(false); err != nil {
+		return err
+	}
+
+	fl.mux.Lock()
+	defer fl.mux.Unlock()
+
+	if fl.err != nil {
+		return fl.err
+	}
+
+	return closeErr
+}
+
+func (fl *Flusher) invoke() {
+	err := fl.flush(false)
+	if err == nil {
+		return
+	}
+
+	fl.mux.Lock()
+	if fl.err == nil {
+		fl.err = err
+	}
+	fl.mux.Unlock()
+
+	if fl.d.opts.onError != nil {
+		fl.d.opts.onError(err)
+	}
+}
+
+// flush flushes f, and returns the error of the flush. Unless force is true, f
+// is only flushed if bytes have been written since the last flush.
+func (fl *Flusher) flush(force bool) error {
+	fl.flushMux.Lock()
+	defer fl.flushMux.Unlock()
+
+	fl.mux.Lock()
+	written := fl.written
+	fl.written = 0
+	fl.mux.Unlock()
+
+	if written == 0 && !force {
+		return nil
+	}
+
+	return fl.f.Flush()
+}
@@ -0,0 +1,144 @@
+package debounce
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countFlusher counts the flushes made to it.
+type countFlusher struct {
+	mux     sync.Mutex
+	flushes int
+	err     error
+}
+
+func (f *countFlusher) Flush() error {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
+	f.flushes++
+
+	return f.err
+}
+
+func (f *countFlusher) Flushes() int {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
+	return f.flushes
+}
+
+func TestFlusher(t *testing.T) {
+	t.Parallel()
+
+	t.Run("time triggered", func(t *testing.T) {
+		t.Parallel()
+
+		cf := &countFlusher{}
+		fl := NewFlusher(cf, 20*time.Millisecond)
+
+		for i := 0; i < 5; i++ {
+			fl.Wrote(10)
+			time.Sleep(5 * time.Millisecond)
+		}
+		assert.Equal(t, 0, cf.Flushes())
+
+		time.Sleep(40 * time.Millisecond)
+		assert.Equal(t, 1, cf.Flushes())
+
+		fl.Wrote(10)
+		time.Sleep(40 * time.Millisecond)
+		assert.Equal(t, 2, cf.Flushes())
+	})
+
+	t.Run("size triggered", func(t *testing.T) {
+		t.Parallel()
+
+		cf := &countFlusher{}
+		fl := NewFlusher(cf, time.Minute, WithFlushThreshold(100))
+
+		for i := 0; i < 9; i++ {
+			fl.Wrote(10)
+		}
+		time.Sleep(10 * time.Millisecond)
+		assert.Equal(t, 0, cf.Flushes())
+
+		fl.Wrote(10)
+		time.Sleep(10 * time.Millisecond)
+		assert.Equal(t, 1, cf.Flushes())
+
+		fl.Wrote(150)
+		time.Sleep(10 * time.Millisecond)
+		assert.Equal(t, 2, cf.Flushes())
+
+		fl.Wrote(10)
+		time.Sleep(10 * time.Millisecond)
+		assert.Equal(t, 2, cf.Flushes())
+	})
+
+	t.Run("close triggered", func(t *testing.T) {
+		t.Parallel()
+
+		cf := &countFlusher{}
+		fl := NewFlusher(cf, time.Minute)
+
+		fl.Wrote(10)
+		require.NoError(t, fl.Close())
+		assert.Equal(t, 1, cf.Flushes())
+
+		fl.Wrote(10)
+		require.NoError(t, fl.Close())
+		assert.ErrorIs(t, fl.FlushNow(), ErrClosed)
+		time.Sleep(10 * time.Millisecond)
+		assert.Equal(t, 1, cf.Flushes())
+	})
+
+	t.Run("flush now", func(t *testing.T) {
+		t.Parallel()
+
+		cf := &countFlusher{}
+		fl := NewFlusher(cf, 20*time.Millisecond)
+
+		fl.Wrote(10)
+		require.NoError(t, fl.FlushNow())
+		assert.Equal(t, 1, cf.Flushes())
+
+		// The pending flush was covered by FlushNow.
+		time.Sleep(40 * time.Millisecond)
+		assert.Equal(t, 1, cf.Flushes())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+
+		errFlush := errors.New("flush failed")
+		cf := &countFlusher{err: errFlush}
+
+		mux := sync.Mutex{}
+		var hookErrs []error
+		fl := NewFlusher(cf, 10*time.Millisecond,
+			WithOnError(func(err error) {
+				mux.Lock()
+				defer mux.Unlock()
+				hookErrs = append(hookErrs, err)
+			}),
+		)
+
+		fl.Wrote(10)
+		time.Sleep(30 * time.Millisecond)
+		assert.Equal(t, 1, cf.Flushes())
+
+		mux.Lock()
+		assert.Equal(t, []error{errFlush}, hookErrs)
+		mux.Unlock()
+
+		assert.ErrorIs(t, fl.FlushNow(), errFlush)
+		assert.ErrorIs(t, fl.Close(), errFlush)
+		assert.Equal(t, 2, cf.Flushes())
+	})
+}
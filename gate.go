@@ -0,0 +1,107 @@
+package debounce
+
+import (
+	"context"
+	"time"
+)
+
+// Gate blocks until the Debouncer allows another caller through, for pacing
+// producers rather than dropping or deferring their work. Callers are let
+// through at least wait time apart, and as allowed by WithQuota and
+// WithRateLimiter, which count each caller let through as an invocation of the
+// callback function. The first caller is let through right away.
+//
+// Concurrent callers are let through one at a time, in the order they called
+// Gate. Gate returns nil once the caller is let through, the context's error if
+// ctx is done first, or ErrClosed if the Debouncer is closed.
+func (d *Debouncer) Gate(ctx context.Context) error {
+	ready := make(chan struct{})
+
+	d.mux.Lock()
+	if d.closed {
+		d.mux.Unlock()
+
+		return ErrClosed
+	}
+	d.gateQueue = append(d.gateQueue, ready)
+	if len(d.gateQueue) == 1 {
+		close(ready)
+	}
+	d.mux.Unlock()
+
+	select {
+	case <-ready:
+	case <-ctx.Done():
+		d.leaveGate(ready)
+
+		return ctx.Err()
+	case <-d.ctx.Done():
+		d.leaveGate(ready)
+
+		return ErrClosed
+	}
+
+	// The caller is first in line, so wait for its slot.
+	for {
+		d.mux.Lock()
+		delay := d.gateDelay(d.now())
+		if delay <= 0 {
+			d.gateAt = d.now()
+			d.recordQuota()
+		}
+		d.mux.Unlock()
+
+		if delay <= 0 {
+			d.leaveGate(ready)
+
+			return nil
+		}
+
+		t := time.NewTimer(delay)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			d.leaveGate(ready)
+
+			return ctx.Err()
+		case <-d.ctx.Done():
+			t.Stop()
+			d.leaveGate(ready)
+
+			return ErrClosed
+		}
+	}
+}
+
+// gateDelay returns how long until Gate may let the next caller through, or
+// zero if it may do so right away. Must be called while holding the lock.
+func (d *Debouncer) gateDelay(now time.Time) time.Duration {
+	if !d.gateAt.IsZero() {
+		if delay := d.wait - elapsed(d.gateAt, now); delay > 0 {
+			return delay
+		}
+	}
+
+	return d.deferral(now)
+}
+
+// leaveGate removes the caller waiting on ready from the Gate queue, and lets
+// the next caller in line know it is first, if ready was first.
+func (d *Debouncer) leaveGate(ready chan struct{}) {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	for i, c := range d.gateQueue {
+		if c != ready {
+			continue
+		}
+
+		d.gateQueue = append(d.gateQueue[:i], d.gateQueue[i+1:]...)
+		if i == 0 && len(d.gateQueue) > 0 {
+			close(d.gateQueue[0])
+		}
+
+		return
+	}
+}
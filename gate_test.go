@@ -0,0 +1,135 @@
+package debounce
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebouncer_Gate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("spacing", func(t *testing.T) {
+		t.Parallel()
+
+		d := NewDebouncer(20*time.Millisecond, func() {})
+
+		start := time.Now()
+		var times []time.Duration
+		for i := 0; i < 4; i++ {
+			require.NoError(t, d.Gate(context.Background()))
+			times = append(times, time.Since(start))
+		}
+
+		assert.Less(t, times[0], 5*time.Millisecond)
+		for i := 1; i < len(times); i++ {
+			assert.InDelta(t, 20*time.Millisecond, times[i]-times[i-1],
+				float64(8*time.Millisecond))
+		}
+
+		// Callers arriving after a quiet period are let through right away.
+		time.Sleep(30 * time.Millisecond)
+		start = time.Now()
+		require.NoError(t, d.Gate(context.Background()))
+		assert.Less(t, time.Since(start), 5*time.Millisecond)
+	})
+
+	t.Run("quota", func(t *testing.T) {
+		t.Parallel()
+
+		d := NewDebouncer(0, func() {}, WithQuota(2, 40*time.Millisecond))
+
+		start := time.Now()
+		var times []time.Duration
+		for i := 0; i < 3; i++ {
+			require.NoError(t, d.Gate(context.Background()))
+			times = append(times, time.Since(start))
+		}
+
+		assert.Less(t, times[1], 5*time.Millisecond)
+		assert.InDelta(t, 40*time.Millisecond, times[2],
+			float64(8*time.Millisecond))
+	})
+
+	t.Run("fifo", func(t *testing.T) {
+		t.Parallel()
+
+		d := NewDebouncer(10*time.Millisecond, func() {})
+		require.NoError(t, d.Gate(context.Background()))
+
+		mux := sync.Mutex{}
+		var order []int
+		wg := sync.WaitGroup{}
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				assert.NoError(t, d.Gate(context.Background()))
+
+				mux.Lock()
+				defer mux.Unlock()
+				order = append(order, i)
+			}(i)
+			// Make sure callers queue up in order.
+			time.Sleep(time.Millisecond)
+		}
+		wg.Wait()
+
+		assert.Equal(t, []int{0, 1, 2, 3, 4}, order)
+	})
+
+	t.Run("cancellation", func(t *testing.T) {
+		t.Parallel()
+
+		d := NewDebouncer(40*time.Millisecond, func() {})
+		require.NoError(t, d.Gate(context.Background()))
+
+		// The first caller in line gives up, handing its place on.
+		ctx, cancel := context.WithTimeout(
+			context.Background(), 10*time.Millisecond,
+		)
+		defer cancel()
+
+		errs := make(chan error, 1)
+		go func() { errs <- d.Gate(ctx) }()
+		time.Sleep(time.Millisecond)
+
+		start := time.Now()
+		require.NoError(t, d.Gate(context.Background()))
+		assert.ErrorIs(t, <-errs, context.DeadlineExceeded)
+		assert.InDelta(t, 40*time.Millisecond, time.Since(start),
+			float64(10*time.Millisecond))
+
+		// A caller giving up before it is first in line.
+		ctx, cancel = context.WithCancel(context.Background())
+		go func() { errs <- d.Gate(context.Background()) }()
+		time.Sleep(time.Millisecond)
+		go func() { errs <- d.Gate(ctx) }()
+		time.Sleep(time.Millisecond)
+		cancel()
+		assert.ErrorIs(t, <-errs, context.Canceled)
+		assert.NoError(t, <-errs)
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		t.Parallel()
+
+		d := NewDebouncer(time.Minute, func() {})
+		require.NoError(t, d.Gate(context.Background()))
+
+		errs := make(chan error, 2)
+		for i := 0; i < 2; i++ {
+			go func() { errs <- d.Gate(context.Background()) }()
+		}
+		time.Sleep(5 * time.Millisecond)
+		require.NoError(t, d.Close())
+
+		assert.ErrorIs(t, <-errs, ErrClosed)
+		assert.ErrorIs(t, <-errs, ErrClosed)
+		assert.ErrorIs(t, d.Gate(context.Background()), ErrClosed)
+	})
+}
@@ -0,0 +1,368 @@
+package debounce
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Group debounces calls per key, as if each key had a Debouncer of its own. It
+// saves keeping track of a Debouncer per key by hand, for example when work is
+// debounced per user or per file.
+//
+// Keys are kept around until they are evicted due to WithGroupMaxIdle or
+// WithGroupMaxEntries, so a long-lived Group with an unbounded set of keys
+// should use either option.
+//
+// All methods of a Group are safe for concurrent use in goroutines. Calls for
+// different keys do not contend on a shared lock.
+type Group[K comparable] struct {
+	// size is the number of keys, and is accessed atomically.
+	size int64
+
+	wait       time.Duration
+	f          func(key K)
+	opts       []Option
+	keyOptions func(key K) []Option
+	maxEntries int
+	// limit is the Allower shared by all keys due to WithGroupGlobalLimit,
+	// and onLimit the hook set with WithGroupOnLimit.
+	limit   Allower
+	onLimit func(key K, delay time.Duration)
+
+	// debouncers maps keys to their *Debouncer.
+	debouncers sync.Map
+	// evictMux makes sure only one call evicts least recently called keys at
+	// a time.
+	evictMux sync.Mutex
+}
+
+// NewGroup returns a new Group, which creates a Debouncer like NewDebouncer
+// for each key on its first call, with f receiving the key. The f function may
+// be nil if all calls are made through Do.
+//
+// Optional behavior can be configured by passing one or more Option values,
+// which apply to each key's Debouncer.
+//
+// NewGroup panics if the key type of a function passed to WithGroupKeyOptions
+// or WithGroupOnLimit is not K.
+func NewGroup[K comparable](
+	wait time.Duration,
+	f func(key K),
+	opts ...Option,
+) *Group[K] {
+	o := newOptions(opts)
+
+	g := &Group[K]{
+		wait:       wait,
+		f:          f,
+		opts:       opts,
+		maxEntries: o.groupMaxEntries,
+	}
+	if o.groupKeyOptions != nil {
+		var ok bool
+		if g.keyOptions, ok = o.groupKeyOptions.(func(key K) []Option); !ok {
+			panic("debounce: WithGroupKeyOptions key type does not match Group")
+		}
+	}
+	if o.groupOnLimit != nil {
+		onLimit, ok := o.groupOnLimit.(func(key K, delay time.Duration))
+		if !ok {
+			panic("debounce: WithGroupOnLimit key type does not match Group")
+		}
+		g.onLimit = onLimit
+	}
+	if o.groupLimit > 0 {
+		g.limit = &windowAllower{n: o.groupLimit, window: o.groupLimitWindow}
+	}
+
+	return g
+}
+
+// Debounce schedules an invocation of the callback function for key,
+// postponing any already pending invocation for key until wait time has
+// elapsed since this call.
+func (g *Group[K]) Debounce(key K) {
+	for {
+		if g.debouncer(key).add(nil) {
+			return
+		}
+		// The key was evicted in the meantime, so retry with a new Debouncer.
+	}
+}
+
+// Do schedules an invocation of f for key, like Debounce. The pending
+// invocation calls the function passed to the last call for key, which is
+// either f, or the callback function given to NewGroup when the last call was
+// made with Debounce.
+func (g *Group[K]) Do(key K, f func()) {
+	for {
+		if g.debouncer(key).add(callback{f: f}) {
+			return
+		}
+		// The key was evicted in the meantime, so retry with a new Debouncer.
+	}
+}
+
+// Func returns debounced and cancel functions bound to key, like those
+// returned by New, saving call sites which always use the same key from
+// passing it on every call. Calling debounced is the same as calling Debounce
+// for key, and calling cancel is the same as calling Cancel for key.
+//
+// The Debouncer for key is only created on the first call to debounced, and
+// both functions stay valid when the key is evicted, with debounced creating a
+// new Debouncer for the key as needed.
+func (g *Group[K]) Func(key K) (debounced func(), cancel func()) {
+	debounced = func() {
+		for {
+			if g.debouncer(key).add(nil) {
+				return
+			}
+			// The key was evicted in the meantime, so retry with a new
+			// Debouncer.
+		}
+	}
+	cancel = func() { g.Cancel(key) }
+
+	return debounced, cancel
+}
+
+// Debouncer returns the Debouncer for key, creating it if needed, giving access
+// to methods like Flush and Stats. Calling Debounce on it is the same as
+// calling Debounce on the Group.
+//
+// Once the key is evicted due to WithGroupMaxIdle or WithGroupMaxEntries, the
+// returned Debouncer behaves as if closed, and a new Debouncer is created for
+// the key on its next call.
+func (g *Group[K]) Debouncer(key K) *Debouncer {
+	return g.debouncer(key)
+}
+
+// Cancel cancels any pending invocation of the callback function for key.
+func (g *Group[K]) Cancel(key K) {
+	if d, ok := g.debouncers.Load(key); ok {
+		d.(*Debouncer).Cancel()
+	}
+}
+
+// FlushAll invokes the callback function right away for all keys with a
+// pending invocation, like Debouncer.Flush, and waits for the invocations to
+// complete. It returns the context's error if the context is done before then.
+//
+// Invocations queued due to WithSerializedExecution or WithDropIfRunning are
+// not waited for. Calls made while FlushAll runs may or may not be included.
+func (g *Group[K]) FlushAll(ctx context.Context) error {
+	wg := sync.WaitGroup{}
+	g.debouncers.Range(func(_, v interface{}) bool {
+		d := v.(*Debouncer)
+		if info, ok, _ := d.flushNow(); ok {
+			wg.Add(1)
+			d.opts.executor.Execute(func() {
+				defer wg.Done()
+				d.run(info)
+			})
+		}
+
+		return true
+	})
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// CancelAll cancels the pending invocations of the callback function for all
+// keys. Calls made while CancelAll runs may or may not be canceled.
+func (g *Group[K]) CancelAll() {
+	g.debouncers.Range(func(_, d interface{}) bool {
+		d.(*Debouncer).Cancel()
+
+		return true
+	})
+}
+
+// Len returns the number of keys held by the Group.
+func (g *Group[K]) Len() int {
+	return int(atomic.LoadInt64(&g.size))
+}
+
+// Keys returns the keys held by the Group, in no particular order.
+//
+// Keys does not block calls to the Group, so keys added or evicted while it
+// runs may or may not be included.
+func (g *Group[K]) Keys() []K {
+	keys := make([]K, 0, g.Len())
+	g.debouncers.Range(func(k, _ interface{}) bool {
+		keys = append(keys, k.(K))
+
+		return true
+	})
+
+	return keys
+}
+
+// Pending reports if an invocation of the callback function is pending for
+// key.
+func (g *Group[K]) Pending(key K) bool {
+	d, ok := g.debouncers.Load(key)
+
+	return ok && d.(*Debouncer).Pending()
+}
+
+// PendingKeys returns the keys with a pending invocation of the callback
+// function, in no particular order.
+//
+// Like Keys, PendingKeys does not block calls to the Group, and only blocks
+// calls for each key while checking if it is pending.
+func (g *Group[K]) PendingKeys() []K {
+	var keys []K
+	g.debouncers.Range(func(k, d interface{}) bool {
+		if d.(*Debouncer).Pending() {
+			keys = append(keys, k.(K))
+		}
+
+		return true
+	})
+
+	return keys
+}
+
+// debouncer returns the Debouncer for key, creating it if needed.
+func (g *Group[K]) debouncer(key K) *Debouncer {
+	if d, ok := g.debouncers.Load(key); ok {
+		return d.(*Debouncer)
+	}
+
+	opts := g.opts
+	if g.keyOptions != nil {
+		keyOpts := g.keyOptions(key)
+		opts = make([]Option, 0, len(g.opts)+len(keyOpts))
+		opts = append(opts, g.opts...)
+		opts = append(opts, keyOpts...)
+	}
+	if g.limit != nil {
+		opts = append(opts[:len(opts):len(opts)], WithRateLimiter(
+			AllowerFunc(func() time.Duration {
+				delay := g.limit.Reserve()
+				if delay > 0 && g.onLimit != nil {
+					g.onLimit(key, delay)
+				}
+
+				return delay
+			}),
+		))
+	}
+
+	d := newDebouncer(g.wait, func(context.Context, InvokeInfo) {
+		g.f(key)
+	}, opts)
+	d.onEvict = func() {
+		g.debouncers.Delete(key)
+		atomic.AddInt64(&g.size, -1)
+	}
+	if d.opts.groupMaxIdle > 0 {
+		d.evictTimer = d.newTimer(d.expire)
+	}
+
+	if actual, loaded := g.debouncers.LoadOrStore(key, d); loaded {
+		// Another call created the Debouncer first. This one has never been
+		// called, so closing it can not fail.
+		_ = d.Close()
+
+		return actual.(*Debouncer)
+	}
+
+	size := atomic.AddInt64(&g.size, 1)
+	if g.maxEntries > 0 && size > int64(g.maxEntries) {
+		g.evictOldest(d)
+	}
+
+	return d
+}
+
+// evictOldest evicts the least recently called keys which are not busy, until
+// the Group is back at its maximum number of keys. The Debouncer of the key
+// which is just being added is passed as keep, so it is not evicted before its
+// first call.
+func (g *Group[K]) evictOldest(keep *Debouncer) {
+	g.evictMux.Lock()
+	defer g.evictMux.Unlock()
+
+	type candidate struct {
+		d        *Debouncer
+		lastCall time.Time
+	}
+
+	var candidates []candidate
+	g.debouncers.Range(func(_, v interface{}) bool {
+		d := v.(*Debouncer)
+		if d == keep {
+			return true
+		}
+
+		d.mux.Lock()
+		if d.evictable() {
+			candidates = append(candidates, candidate{d, d.lastCall})
+		}
+		d.mux.Unlock()
+
+		return true
+	})
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].lastCall.Before(candidates[j].lastCall)
+	})
+
+	for _, c := range candidates {
+		if atomic.LoadInt64(&g.size) <= int64(g.maxEntries) {
+			return
+		}
+
+		c.d.mux.Lock()
+		c.d.evict()
+		c.d.mux.Unlock()
+	}
+}
+
+// expire is called by the evict timer, and evicts the Debouncer from its
+// Group, unless it is busy, in which case it checks again after the time set
+// with WithGroupMaxIdle.
+func (d *Debouncer) expire() {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	if !d.evict() && !d.closed {
+		d.evictTimer.Reset(d.opts.groupMaxIdle)
+	}
+}
+
+// evictable reports if the Debouncer can be evicted from its Group, i.e., it
+// has no pending or running invocation. Must be called while holding the lock.
+func (d *Debouncer) evictable() bool {
+	return !d.closed && !d.dirty && d.running == 0
+}
+
+// evict closes the Debouncer and removes it from its Group if it is
+// evictable, and reports if it did. Must be called while holding the lock.
+func (d *Debouncer) evict() bool {
+	if !d.evictable() {
+		return false
+	}
+
+	d.close()
+	d.evicted = true
+	d.onEvict()
+
+	return true
+}
@@ -0,0 +1,665 @@
+package debounce
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroup(t *testing.T) {
+	t.Parallel()
+
+	type groupOp struct {
+		delay  time.Duration
+		key    string
+		cancel bool
+	}
+
+	tests := []struct {
+		name         string
+		wait         time.Duration
+		calls        []groupOp
+		wantTriggers map[time.Duration]map[string]int
+	}{
+		{
+			name: "one call per key",
+			wait: 20 * time.Millisecond,
+			calls: []groupOp{
+				{delay: 10 * time.Millisecond, key: "a"},
+				{delay: 20 * time.Millisecond, key: "b"},
+			},
+			wantTriggers: map[time.Duration]map[string]int{
+				25 * time.Millisecond: {},
+				// from call for a at 10ms (+20ms wait = 30ms)
+				35 * time.Millisecond: {"a": 1},
+				// from call for b at 20ms (+20ms wait = 40ms)
+				45 * time.Millisecond:  {"a": 1, "b": 1},
+				150 * time.Millisecond: {"a": 1, "b": 1},
+			},
+		},
+		{
+			name: "interleaved bursts",
+			wait: 20 * time.Millisecond,
+			calls: []groupOp{
+				{delay: 0 * time.Millisecond, key: "a"},
+				{delay: 5 * time.Millisecond, key: "b"},
+				{delay: 10 * time.Millisecond, key: "a"},
+				{delay: 15 * time.Millisecond, key: "b"},
+				{delay: 20 * time.Millisecond, key: "a"}, // trigger a
+				{delay: 30 * time.Millisecond, key: "b"}, // trigger b
+				{delay: 60 * time.Millisecond, key: "a"}, // trigger a
+			},
+			wantTriggers: map[time.Duration]map[string]int{
+				35 * time.Millisecond: {},
+				// from call for a at 20ms (+20ms wait = 40ms)
+				45 * time.Millisecond: {"a": 1},
+				// from call for b at 30ms (+20ms wait = 50ms)
+				55 * time.Millisecond: {"a": 1, "b": 1},
+				// from call for a at 60ms (+20ms wait = 80ms)
+				85 * time.Millisecond:  {"a": 2, "b": 1},
+				150 * time.Millisecond: {"a": 2, "b": 1},
+			},
+		},
+		{
+			name: "cancel only affects its key",
+			wait: 20 * time.Millisecond,
+			calls: []groupOp{
+				{delay: 0 * time.Millisecond, key: "a"},
+				{delay: 5 * time.Millisecond, key: "b"},
+				{delay: 10 * time.Millisecond, key: "a", cancel: true},
+			},
+			wantTriggers: map[time.Duration]map[string]int{
+				// from call for b at 5ms (+20ms wait = 25ms)
+				30 * time.Millisecond:  {"b": 1},
+				150 * time.Millisecond: {"b": 1},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mux := sync.RWMutex{}
+
+			n := map[string]int{}
+			g := NewGroup(tt.wait, func(key string) {
+				mux.Lock()
+				defer mux.Unlock()
+				n[key]++
+			})
+
+			wg := sync.WaitGroup{}
+			for _, op := range tt.calls {
+				wg.Add(1)
+				go func(op groupOp) {
+					defer wg.Done()
+					time.Sleep(op.delay)
+					if op.cancel {
+						g.Cancel(op.key)
+					} else {
+						g.Debounce(op.key)
+					}
+				}(op)
+			}
+
+			for delay, counts := range tt.wantTriggers {
+				wg.Add(1)
+				go func(interval time.Duration, counts map[string]int) {
+					defer wg.Done()
+					time.Sleep(interval)
+
+					mux.RLock()
+					defer mux.RUnlock()
+					assert.Equal(t, counts, n, "at %s", interval)
+				}(delay, counts)
+			}
+
+			wg.Wait()
+		})
+	}
+}
+
+func TestGroup_concurrentFirstCalls(t *testing.T) {
+	t.Parallel()
+
+	mux := sync.Mutex{}
+	n := 0
+	g := NewGroup(10*time.Millisecond, func(string) {
+		mux.Lock()
+		defer mux.Unlock()
+		n++
+	})
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			g.Debounce("key")
+		}()
+	}
+	wg.Wait()
+
+	time.Sleep(50 * time.Millisecond)
+
+	mux.Lock()
+	defer mux.Unlock()
+	assert.Equal(t, 1, n)
+}
+
+// groupKeys returns the sorted keys held by g.
+func groupKeys(g *Group[string]) []string {
+	keys := g.Keys()
+	sort.Strings(keys)
+
+	return keys
+}
+
+func TestWithGroupMaxIdle(t *testing.T) {
+	t.Parallel()
+
+	t.Run("evicts idle keys", func(t *testing.T) {
+		t.Parallel()
+
+		mux := sync.Mutex{}
+		n := map[string]int{}
+		g := NewGroup(5*time.Millisecond, func(key string) {
+			mux.Lock()
+			defer mux.Unlock()
+			n[key]++
+		}, WithGroupMaxIdle(30*time.Millisecond))
+
+		g.Debounce("a")
+		g.Debounce("b")
+		time.Sleep(20 * time.Millisecond)
+		g.Debounce("b")
+
+		assert.Equal(t, []string{"a", "b"}, groupKeys(g))
+		time.Sleep(20 * time.Millisecond)
+		assert.Equal(t, []string{"b"}, groupKeys(g))
+		time.Sleep(30 * time.Millisecond)
+		assert.Equal(t, []string{}, groupKeys(g))
+		assert.Equal(t, 0, g.Len())
+
+		// An evicted key starts afresh.
+		g.Debounce("a")
+		assert.Equal(t, []string{"a"}, groupKeys(g))
+		time.Sleep(20 * time.Millisecond)
+
+		mux.Lock()
+		defer mux.Unlock()
+		assert.Equal(t, map[string]int{"a": 2, "b": 2}, n)
+	})
+
+	t.Run("does not evict busy keys", func(t *testing.T) {
+		t.Parallel()
+
+		invoked := make(chan struct{}, 1)
+		g := NewGroup(50*time.Millisecond, func(string) {
+			invoked <- struct{}{}
+		}, WithGroupMaxIdle(10*time.Millisecond))
+
+		g.Debounce("a")
+		time.Sleep(30 * time.Millisecond)
+		assert.Equal(t, []string{"a"}, groupKeys(g))
+
+		<-invoked
+		time.Sleep(30 * time.Millisecond)
+		assert.Equal(t, []string{}, groupKeys(g))
+	})
+
+	t.Run("releases timers", func(t *testing.T) {
+		t.Parallel()
+
+		g := NewGroup(5*time.Millisecond, func(string) {},
+			WithGroupMaxIdle(10*time.Millisecond),
+		)
+
+		g.Debounce("a")
+		d, _ := g.debouncers.Load("a")
+		time.Sleep(50 * time.Millisecond)
+
+		debouncer := d.(*Debouncer)
+		debouncer.mux.Lock()
+		defer debouncer.mux.Unlock()
+		assert.True(t, debouncer.evicted)
+		assert.False(t, debouncer.timer.Stop())
+		assert.False(t, debouncer.evictTimer.Stop())
+	})
+}
+
+func TestWithGroupMaxEntries(t *testing.T) {
+	t.Parallel()
+
+	t.Run("evicts least recently called keys", func(t *testing.T) {
+		t.Parallel()
+
+		g := NewGroup(5*time.Millisecond, func(string) {},
+			WithGroupMaxEntries(2),
+		)
+
+		g.Debounce("a")
+		time.Sleep(10 * time.Millisecond)
+		g.Debounce("b")
+		time.Sleep(10 * time.Millisecond)
+		g.Debounce("a")
+		time.Sleep(10 * time.Millisecond)
+		g.Debounce("c")
+
+		assert.Equal(t, []string{"a", "c"}, groupKeys(g))
+		assert.Equal(t, 2, g.Len())
+	})
+
+	t.Run("does not evict busy keys", func(t *testing.T) {
+		t.Parallel()
+
+		mux := sync.Mutex{}
+		n := 0
+		g := NewGroup(20*time.Millisecond, func(string) {
+			mux.Lock()
+			defer mux.Unlock()
+			n++
+		}, WithGroupMaxEntries(2))
+
+		g.Debounce("a")
+		g.Debounce("b")
+		g.Debounce("c")
+		assert.Equal(t, []string{"a", "b", "c"}, groupKeys(g))
+
+		time.Sleep(40 * time.Millisecond)
+		g.Debounce("d")
+		assert.Len(t, groupKeys(g), 2)
+		assert.Contains(t, groupKeys(g), "d")
+
+		time.Sleep(40 * time.Millisecond)
+
+		mux.Lock()
+		defer mux.Unlock()
+		assert.Equal(t, 4, n)
+	})
+}
+
+func TestGroup_introspection(t *testing.T) {
+	t.Parallel()
+
+	g := NewGroup(20*time.Millisecond, func(string) {})
+
+	assert.Equal(t, 0, g.Len())
+	assert.Empty(t, g.Keys())
+	assert.Empty(t, g.PendingKeys())
+	assert.False(t, g.Pending("a"))
+
+	g.Debounce("a")
+	g.Debounce("b")
+	g.Debounce("c")
+	g.Cancel("c")
+
+	pending := g.PendingKeys()
+	sort.Strings(pending)
+	assert.Equal(t, 3, g.Len())
+	assert.Equal(t, []string{"a", "b", "c"}, groupKeys(g))
+	assert.Equal(t, []string{"a", "b"}, pending)
+	assert.True(t, g.Pending("a"))
+	assert.False(t, g.Pending("c"))
+	assert.False(t, g.Pending("d"))
+
+	time.Sleep(40 * time.Millisecond)
+
+	assert.Equal(t, 3, g.Len())
+	assert.Equal(t, []string{"a", "b", "c"}, groupKeys(g))
+	assert.Empty(t, g.PendingKeys())
+	assert.False(t, g.Pending("a"))
+}
+
+func TestGroup_FlushAll(t *testing.T) {
+	t.Parallel()
+
+	t.Run("flushes pending keys once", func(t *testing.T) {
+		t.Parallel()
+
+		mux := sync.Mutex{}
+		n := map[string]int{}
+		g := NewGroup(50*time.Millisecond, func(key string) {
+			time.Sleep(10 * time.Millisecond)
+
+			mux.Lock()
+			defer mux.Unlock()
+			n[key]++
+		})
+
+		g.Debounce("a")
+		g.Debounce("b")
+		g.Debounce("c")
+		g.Cancel("c")
+
+		err := g.FlushAll(context.Background())
+		require.NoError(t, err)
+
+		// Invocations have completed once FlushAll returns.
+		mux.Lock()
+		assert.Equal(t, map[string]int{"a": 1, "b": 1}, n)
+		mux.Unlock()
+		assert.Empty(t, g.PendingKeys())
+
+		time.Sleep(70 * time.Millisecond)
+
+		mux.Lock()
+		defer mux.Unlock()
+		assert.Equal(t, map[string]int{"a": 1, "b": 1}, n)
+	})
+
+	t.Run("context done", func(t *testing.T) {
+		t.Parallel()
+
+		release := make(chan struct{})
+		g := NewGroup(50*time.Millisecond, func(string) { <-release })
+		defer close(release)
+
+		g.Debounce("a")
+
+		ctx, cancel := context.WithTimeout(
+			context.Background(), 10*time.Millisecond,
+		)
+		defer cancel()
+
+		err := g.FlushAll(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestGroup_CancelAll(t *testing.T) {
+	t.Parallel()
+
+	mux := sync.Mutex{}
+	n := 0
+	g := NewGroup(20*time.Millisecond, func(string) {
+		mux.Lock()
+		defer mux.Unlock()
+		n++
+	})
+
+	g.Debounce("a")
+	g.Debounce("b")
+	g.CancelAll()
+	assert.Empty(t, g.PendingKeys())
+
+	g.Debounce("c")
+	time.Sleep(40 * time.Millisecond)
+
+	mux.Lock()
+	defer mux.Unlock()
+	assert.Equal(t, 1, n)
+}
+
+func TestWithGroupKeyOptions(t *testing.T) {
+	t.Parallel()
+
+	mux := sync.Mutex{}
+	n := map[string]int{}
+	calls := map[string]int{}
+	g := NewGroup(10*time.Millisecond, func(key string) {
+		mux.Lock()
+		defer mux.Unlock()
+		n[key]++
+	}, WithGroupMaxIdle(100*time.Millisecond), WithGroupKeyOptions(
+		func(key string) []Option {
+			mux.Lock()
+			defer mux.Unlock()
+			calls[key]++
+
+			if key == "hot" {
+				return []Option{
+					WithWait(40 * time.Millisecond),
+					WithMaxWait(60 * time.Millisecond),
+				}
+			}
+
+			return nil
+		},
+	))
+
+	counts := func() map[string]int {
+		mux.Lock()
+		defer mux.Unlock()
+
+		return map[string]int{"cold": n["cold"], "hot": n["hot"]}
+	}
+
+	g.Debounce("cold")
+	g.Debounce("hot")
+	time.Sleep(25 * time.Millisecond)
+	assert.Equal(t, map[string]int{"cold": 1, "hot": 0}, counts())
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, map[string]int{"cold": 1, "hot": 1}, counts())
+
+	// The hot key's maximum wait time applies to continuous calls.
+	for i := 0; i < 4; i++ {
+		g.Debounce("hot")
+		time.Sleep(20 * time.Millisecond)
+	}
+	assert.Equal(t, map[string]int{"cold": 1, "hot": 2}, counts())
+
+	// Options are evaluated again once an evicted key is recreated.
+	time.Sleep(200 * time.Millisecond)
+	assert.Empty(t, g.Keys())
+	g.Debounce("hot")
+
+	mux.Lock()
+	defer mux.Unlock()
+	assert.Equal(t, map[string]int{"cold": 1, "hot": 2}, calls)
+}
+
+func TestWithGroupKeyOptions_keyTypeMismatch(t *testing.T) {
+	t.Parallel()
+
+	assert.Panics(t, func() {
+		NewGroup(time.Millisecond, func(string) {}, WithGroupKeyOptions(
+			func(int) []Option { return nil },
+		))
+	})
+}
+
+func TestWithGroupOnLimit_keyTypeMismatch(t *testing.T) {
+	t.Parallel()
+
+	assert.Panics(t, func() {
+		NewGroup(time.Millisecond, func(string) {}, WithGroupOnLimit(
+			func(int, time.Duration) {},
+		))
+	})
+	assert.Panics(t, func() {
+		NewGroup(time.Millisecond, func(string) {},
+			WithGroupGlobalLimit(10, time.Second),
+			WithGroupOnLimit(func(int, time.Duration) {}),
+		)
+	})
+}
+
+func TestGroup_structKey(t *testing.T) {
+	t.Parallel()
+
+	type key struct {
+		tenant string
+		id     int
+	}
+
+	mux := sync.Mutex{}
+	var got []key
+	g := NewGroup(10*time.Millisecond, func(k key) {
+		mux.Lock()
+		defer mux.Unlock()
+		got = append(got, k)
+	})
+
+	g.Debounce(key{"a", 1})
+	g.Debounce(key{"a", 1})
+	time.Sleep(5 * time.Millisecond)
+	g.Debounce(key{"b", 1})
+
+	assert.Equal(t, 2, g.Len())
+	assert.True(t, g.Pending(key{"a", 1}))
+	assert.False(t, g.Pending(key{"a", 2}))
+
+	time.Sleep(30 * time.Millisecond)
+
+	mux.Lock()
+	defer mux.Unlock()
+	assert.Equal(t, []key{{"a", 1}, {"b", 1}}, got)
+}
+
+func TestGroup_Do(t *testing.T) {
+	t.Parallel()
+
+	type key struct{ id int }
+
+	mux := sync.Mutex{}
+	var got []string
+	record := func(s string) func() {
+		return func() {
+			mux.Lock()
+			defer mux.Unlock()
+			got = append(got, s)
+		}
+	}
+	g := NewGroup(10*time.Millisecond, func(k key) {
+		record(fmt.Sprintf("shared %d", k.id))()
+	})
+
+	// The last function for a key wins.
+	g.Do(key{1}, record("first 1"))
+	g.Do(key{1}, record("last 1"))
+	g.Do(key{2}, record("2"))
+	// Debounce falls back to the shared callback.
+	g.Do(key{3}, record("3"))
+	g.Debounce(key{3})
+
+	time.Sleep(30 * time.Millisecond)
+
+	mux.Lock()
+	sort.Strings(got)
+	assert.Equal(t, []string{"2", "last 1", "shared 3"}, got)
+	got = nil
+	mux.Unlock()
+
+	// The Debouncer of a key is the one used by the Group.
+	d := g.Debouncer(key{1})
+	g.Do(key{1}, record("flushed"))
+	assert.True(t, d.Pending())
+	d.Flush()
+	time.Sleep(5 * time.Millisecond)
+
+	mux.Lock()
+	defer mux.Unlock()
+	assert.Equal(t, []string{"flushed"}, got)
+	assert.Equal(t, 2, d.Stats().Invocations)
+}
+
+func TestGroup_Func(t *testing.T) {
+	t.Parallel()
+
+	calls := make(chan string, 10)
+	g := NewGroup(time.Minute, func(k string) {
+		calls <- k
+	}, WithGroupMaxIdle(time.Minute))
+
+	debounced, cancel := g.Func("a")
+	assert.Equal(t, 0, g.Len())
+
+	// Bound and direct calls share a single timeline.
+	wg := sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				if i%2 == 0 {
+					debounced()
+				} else {
+					g.Debounce("a")
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, g.Len())
+	d := g.Debouncer("a")
+	require.NoError(t, d.Flush())
+	assert.Equal(t, "a", <-calls)
+	require.NoError(t, d.Wait(context.Background()))
+	assert.Len(t, calls, 0)
+	assert.ErrorIs(t, d.Flush(), ErrNothingPending)
+
+	// The key is evicted as its idle timer expires, but the bound functions
+	// stay valid.
+	d.expire()
+	assert.Equal(t, 0, g.Len())
+	debounced()
+	assert.Equal(t, 1, g.Len())
+	assert.True(t, g.Pending("a"))
+	cancel()
+	assert.False(t, g.Pending("a"))
+
+	debounced()
+	require.NoError(t, g.Debouncer("a").Flush())
+	assert.Equal(t, "a", <-calls)
+	assert.Len(t, calls, 0)
+}
+
+func TestWithGroupGlobalLimit(t *testing.T) {
+	t.Parallel()
+
+	const keys = 100
+	mux := sync.Mutex{}
+	var order []int
+	var times []time.Time
+	var limited int32
+	g := NewGroup(10*time.Millisecond, func(k int) {
+		mux.Lock()
+		defer mux.Unlock()
+		order = append(order, k)
+		times = append(times, time.Now())
+	},
+		WithGroupGlobalLimit(10, 100*time.Millisecond),
+		WithGroupOnLimit(func(_ int, delay time.Duration) {
+			assert.Positive(t, delay)
+			atomic.AddInt32(&limited, 1)
+		}),
+	)
+
+	start := time.Now()
+	for k := 0; k < keys; k++ {
+		g.Debounce(k)
+		time.Sleep(100 * time.Microsecond)
+	}
+	// Calls for a deferred key are coalesced into its pending invocation.
+	time.Sleep(50 * time.Millisecond)
+	g.Debounce(keys - 1)
+
+	time.Sleep(1200 * time.Millisecond)
+
+	mux.Lock()
+	defer mux.Unlock()
+	require.Len(t, order, keys)
+	assert.Equal(t, int32(keys-10), atomic.LoadInt32(&limited))
+
+	// At most 10 invocations within any 100ms.
+	for i := 10; i < len(times); i++ {
+		assert.GreaterOrEqual(t, times[i].Sub(times[i-10]),
+			95*time.Millisecond, "invocation %d", i)
+	}
+	assert.GreaterOrEqual(t, times[keys-1].Sub(start), 900*time.Millisecond)
+
+	// Keys are let through in the order they came due.
+	for i, k := range order {
+		assert.InDelta(t, k/10, i/10, 1, "key %d invoked as %d", k, i)
+	}
+}
@@ -0,0 +1,124 @@
+package debounce
+
+import (
+	"sync"
+	"time"
+)
+
+// NewHopping returns a function which collects the values passed to it, and
+// passes them to f as a batch every interval for as long as values keep being
+// added. Unlike NewBatchWithMaxWait, batches are passed to f on a fixed grid of
+// intervals, which starts with the first value added while idle. Once an
+// interval passes without any values being added, the grid stops, so no timer
+// keeps running while idle, and the next value added starts a new grid.
+//
+// Batches are passed to f one at a time, in order. Each invocation of f
+// receives a new slice, which f is free to keep or modify.
+//
+// The returned stop function passes any values not yet passed to f to it right
+// away, like the stop function of NewSample, and makes further calls to add
+// have no effect. It is not required to be called, so can be ignored if not
+// needed.
+//
+// Of the Option values, only WithScheduler has an effect.
+//
+// Both add and stop functions are safe for concurrent use in goroutines, and
+// can both be called multiple times.
+func NewHopping[T any](
+	interval time.Duration,
+	f func(batch []T),
+	opts ...Option,
+) (add func(value T), stop func()) {
+	h := &hopping[T]{f: f, interval: interval}
+	if s := newOptions(opts).scheduler; s != nil {
+		h.timer = s.newTimer(h.tick)
+	} else {
+		h.timer = stoppedTimer(h.tick)
+	}
+
+	return h.add, h.stop
+}
+
+// hopping holds the values collected by a function returned by NewHopping,
+// along with its grid of intervals.
+type hopping[T any] struct {
+	f        func(batch []T)
+	interval time.Duration
+	timer    timer
+
+	mux     sync.Mutex
+	batch   []T
+	active  bool
+	next    time.Time
+	stopped bool
+
+	// callMux makes calls to f one at a time, in order.
+	callMux sync.Mutex
+}
+
+func (h *hopping[T]) add(value T) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	if h.stopped {
+		return
+	}
+
+	h.batch = append(h.batch, value)
+	if !h.active {
+		h.active = true
+		h.next = time.Now().Add(h.interval)
+		h.timer.Reset(h.interval)
+	}
+}
+
+func (h *hopping[T]) stop() {
+	h.mux.Lock()
+	if h.stopped {
+		h.mux.Unlock()
+
+		return
+	}
+	h.stopped = true
+	h.active = false
+	h.timer.Stop()
+
+	batch := h.batch
+	h.batch = nil
+	if len(batch) == 0 {
+		h.mux.Unlock()
+
+		return
+	}
+
+	h.callMux.Lock()
+	defer h.callMux.Unlock()
+	h.mux.Unlock()
+
+	h.f(batch)
+}
+
+// tick is called by the timer at the end of each interval, and passes the
+// values collected during it to f, or stops the grid if there are none.
+func (h *hopping[T]) tick() {
+	h.mux.Lock()
+	batch := h.batch
+	h.batch = nil
+	if !h.active || len(batch) == 0 {
+		h.active = false
+		h.mux.Unlock()
+
+		return
+	}
+
+	// Schedule the next tick relative to the grid rather than to now, so
+	// delays in running ticks do not add up.
+	h.next = h.next.Add(h.interval)
+	h.timer.Reset(time.Until(h.next))
+
+	h.callMux.Lock()
+	defer h.callMux.Unlock()
+	h.mux.Unlock()
+
+	h.f(batch)
+}
@@ -0,0 +1,87 @@
+package debounce
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHopping(t *testing.T) {
+	t.Parallel()
+
+	type batch struct {
+		at     time.Duration
+		values []int
+	}
+
+	for _, tb := range timerBackends {
+		tb := tb
+		t.Run(tb.name, func(t *testing.T) {
+			t.Parallel()
+
+			mux := sync.Mutex{}
+			var got []batch
+			start := time.Now()
+			add, _ := NewHopping(20*time.Millisecond, func(values []int) {
+				mux.Lock()
+				defer mux.Unlock()
+				got = append(got, batch{time.Since(start), values})
+			}, tb.opts...)
+
+			for _, op := range []struct {
+				delay time.Duration
+				value int
+			}{
+				{delay: 0, value: 1},
+				{delay: 5 * time.Millisecond, value: 2},
+				{delay: 25 * time.Millisecond, value: 3},
+				{delay: 30 * time.Millisecond, value: 4},
+				{delay: 45 * time.Millisecond, value: 5},
+				// the tick at 80ms is empty, stopping the grid, so this
+				// starts a new one
+				{delay: 90 * time.Millisecond, value: 6},
+			} {
+				time.Sleep(time.Until(start.Add(op.delay)))
+				add(op.value)
+			}
+			time.Sleep(80 * time.Millisecond)
+
+			mux.Lock()
+			defer mux.Unlock()
+			want := []batch{
+				{at: 20 * time.Millisecond, values: []int{1, 2}},
+				{at: 40 * time.Millisecond, values: []int{3, 4}},
+				{at: 60 * time.Millisecond, values: []int{5}},
+				{at: 110 * time.Millisecond, values: []int{6}},
+			}
+			require.Len(t, got, len(want))
+			for i, w := range want {
+				assert.Equal(t, w.values, got[i].values)
+				assert.InDelta(t, w.at, got[i].at, float64(5*time.Millisecond))
+			}
+		})
+	}
+}
+
+func TestNewHopping_stop(t *testing.T) {
+	t.Parallel()
+
+	got := make(chan []int, 2)
+	add, stop := NewHopping(10*time.Millisecond, func(values []int) {
+		got <- values
+	})
+
+	add(1)
+	add(2)
+	stop()
+	assert.Equal(t, []int{1, 2}, <-got)
+
+	// Nothing is pending, and further values are ignored.
+	stop()
+	add(3)
+	time.Sleep(30 * time.Millisecond)
+	assert.Empty(t, got)
+}
@@ -0,0 +1,196 @@
+package debounce
+
+import (
+	"context"
+	"time"
+)
+
+// maxCallers is the maximum number of call sites recorded per invocation by
+// WithCallSiteCapture.
+const maxCallers = 32
+
+// InvokeInfo describes the calls which led to an invocation of a callback
+// function.
+type InvokeInfo struct {
+	// Calls is the number of calls coalesced into the invocation.
+	Calls int
+	// Callers holds the program counters of the most recent call sites
+	// coalesced into the invocation, up to 32 of them, when
+	// WithCallSiteCapture is used. They can be resolved with
+	// runtime.CallersFrames.
+	Callers []uintptr
+	// Reason describes what triggered the invocation.
+	Reason InvokeReason
+
+	// value holds the combined value passed to the calls, for the generic
+	// debounced functions, like NewTyped.
+	value interface{}
+	// promises holds the promises of calls made with DebounceDone.
+	promises []*Promise
+	// ctxs holds the contexts of calls made with DebounceCtx, except for
+	// contexts which can never be done, when WithDropCanceledCalls or
+	// WithIgnoreExpiredDeadlines need to check them once the invocation
+	// starts.
+	ctxs []context.Context
+	// earliest is the earliest deadline of the contexts of calls made with
+	// DebounceCtx which are not held in ctxs, if any.
+	earliest time.Time
+}
+
+// merge returns the combination of info followed by other, combining their
+// values with combine, if any.
+func (info InvokeInfo) merge(
+	other InvokeInfo,
+	combine func(acc, next interface{}) interface{},
+) InvokeInfo {
+	switch {
+	case other.Calls == 0:
+	case info.Calls == 0:
+		info.value = other.value
+	case combine != nil:
+		info.value = combine(info.value, other.value)
+	}
+	info.Calls += other.Calls
+	if other.Reason != 0 {
+		info.Reason = other.Reason
+	}
+
+	if len(other.Callers) > 0 {
+		callers := make([]uintptr, 0, len(info.Callers)+len(other.Callers))
+		callers = append(callers, info.Callers...)
+		callers = append(callers, other.Callers...)
+		if len(callers) > maxCallers {
+			callers = callers[len(callers)-maxCallers:]
+		}
+		info.Callers = callers
+	}
+
+	if len(other.promises) > 0 {
+		promises := make([]*Promise, 0, len(info.promises)+len(other.promises))
+		promises = append(promises, info.promises...)
+		info.promises = append(promises, other.promises...)
+	}
+
+	if len(other.ctxs) > 0 {
+		ctxs := make([]context.Context, 0, len(info.ctxs)+len(other.ctxs))
+		ctxs = append(ctxs, info.ctxs...)
+		info.ctxs = append(ctxs, other.ctxs...)
+	}
+	info.earliest = earliestTime(info.earliest, other.earliest)
+
+	return info
+}
+
+// deadline returns the earliest deadline of the contexts of the calls
+// described by info, and reports if there is one. Contexts which are done are
+// skipped if skipDone is true.
+func (info InvokeInfo) deadline(skipDone bool) (time.Time, bool) {
+	earliest := info.earliest
+	for _, ctx := range info.ctxs {
+		if skipDone && ctx.Err() != nil {
+			continue
+		}
+
+		if deadline, ok := ctx.Deadline(); ok {
+			earliest = earliestTime(earliest, deadline)
+		}
+	}
+
+	return earliest, !earliest.IsZero()
+}
+
+// earliestTime returns the earlier of a and b, where a zero time stands for no
+// time at all.
+func earliestTime(a, b time.Time) time.Time {
+	if a.IsZero() || (!b.IsZero() && b.Before(a)) {
+		return b
+	}
+
+	return a
+}
+
+// dropCanceled returns info without the calls whose context is done, along
+// with the number of calls dropped.
+func (info InvokeInfo) dropCanceled() (InvokeInfo, int) {
+	var ctxs []context.Context
+	for _, ctx := range info.ctxs {
+		if ctx.Err() == nil {
+			ctxs = append(ctxs, ctx)
+		}
+	}
+
+	dropped := len(info.ctxs) - len(ctxs)
+	info.ctxs = ctxs
+	info.Calls -= dropped
+
+	return info, dropped
+}
+
+// complete completes the promises of the calls described by info with err.
+func (info InvokeInfo) complete(err error) {
+	for _, p := range info.promises {
+		p.complete(err)
+	}
+}
+
+// callback is the value of calls made with DebounceWith and
+// DebounceWithPriority.
+type callback struct {
+	f        func()
+	priority int
+}
+
+// combineCallbacks keeps the callback with the highest priority, preferring
+// next over acc when their priorities are equal. Values which are not a
+// callback, like those of calls made with Debounce, have a priority of 0.
+func combineCallbacks(acc, next interface{}) interface{} {
+	a, _ := acc.(callback)
+	n, _ := next.(callback)
+	if n.priority >= a.priority {
+		return next
+	}
+
+	return acc
+}
+
+// InvokeReason describes what triggered an invocation of a callback function.
+type InvokeReason int
+
+const (
+	// InvokeWait indicates the wait time elapsed since the last call.
+	InvokeWait InvokeReason = iota + 1
+	// InvokeMaxWait indicates the maximum wait time elapsed since the first
+	// call of a burst.
+	InvokeMaxWait
+	// InvokeDeadline indicates the deadline set with WithDeadline or
+	// SetDeadline was reached.
+	InvokeDeadline
+	// InvokeImmediate indicates a call invoked the callback function right
+	// away, due to WithBurstPassThrough or a wait time of zero or less.
+	InvokeImmediate
+	// InvokeFlush indicates the invocation was requested with Flush.
+	InvokeFlush
+	// InvokeMaxBatchSize indicates a batch reached the size set with
+	// WithMaxBatchSize.
+	InvokeMaxBatchSize
+)
+
+// String returns a human readable name of the reason.
+func (r InvokeReason) String() string {
+	switch r {
+	case InvokeWait:
+		return "wait"
+	case InvokeMaxWait:
+		return "max wait"
+	case InvokeDeadline:
+		return "deadline"
+	case InvokeImmediate:
+		return "immediate"
+	case InvokeFlush:
+		return "flush"
+	case InvokeMaxBatchSize:
+		return "max batch size"
+	default:
+		return "unknown"
+	}
+}
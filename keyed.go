@@ -0,0 +1,211 @@
+package debounce
+
+import (
+	"context"
+	"time"
+)
+
+// NewKeyedBatch returns a debounced function like NewBatch, but which collects
+// the value of each call under its key, and passes f a map holding the values
+// of each key in the order they were added. Each invocation of f receives a
+// new map, which f is free to keep or modify, while calls made afterwards
+// start a new map.
+//
+// The maximum number of values per map can be limited with WithMaxBatchSize,
+// and the maximum number of values per key with WithMaxKeyBatchSize. Either
+// limit being reached invokes f right away.
+//
+// The returned cancel function can be used to cancel any pending invocation of
+// f, discarding the values collected for it, but is not required to be called,
+// so can be ignored if not needed.
+//
+// Both add and cancel functions are safe for concurrent use in goroutines, and
+// can both be called multiple times.
+//
+// Optional behavior can be configured by passing one or more KeyedBatchOption
+// values, which include all Option values.
+func NewKeyedBatch[K comparable, V any](
+	wait time.Duration,
+	f func(batches map[K][]V),
+	opts ...KeyedBatchOption,
+) (add func(key K, value V), cancel func()) {
+	d := newKeyedBatch(wait, f, opts)
+
+	return func(key K, value V) {
+		d.add(mapEntry[K, V]{key: key, value: value})
+	}, d.cancelFunc()
+}
+
+// KeyedBatchDebouncer collects the values passed to its Add method under their
+// keys, like the debounced function returned by NewKeyedBatch, and gives
+// access to the pending values, for example to report on them, or to flush
+// them on demand.
+//
+// All methods are safe for concurrent use in goroutines. Each value added is
+// passed to the callback function exactly once, unless it is discarded by
+// Cancel or Close, whatever triggers the invocation.
+type KeyedBatchDebouncer[K comparable, V any] struct {
+	d *Debouncer
+}
+
+// NewKeyedBatchDebouncer returns a new KeyedBatchDebouncer, which passes the
+// values added to it to f under their keys like NewKeyedBatch.
+//
+// Optional behavior can be configured by passing one or more KeyedBatchOption
+// values, which include all Option values.
+func NewKeyedBatchDebouncer[K comparable, V any](
+	wait time.Duration,
+	f func(batches map[K][]V),
+	opts ...KeyedBatchOption,
+) *KeyedBatchDebouncer[K, V] {
+	return &KeyedBatchDebouncer[K, V]{d: newKeyedBatch(wait, f, opts)}
+}
+
+// Add adds value to the pending values of key.
+func (b *KeyedBatchDebouncer[K, V]) Add(key K, value V) {
+	b.d.add(mapEntry[K, V]{key: key, value: value})
+}
+
+// Len returns the number of pending values, across all keys.
+func (b *KeyedBatchDebouncer[K, V]) Len() int {
+	n := 0
+	b.d.peek(func(value interface{}) {
+		n = toKeyedBatch[K, V](value).total
+	})
+
+	return n
+}
+
+// Peek returns a copy of the pending values of each key, in the order they
+// were added, or nil if there are none.
+func (b *KeyedBatchDebouncer[K, V]) Peek() map[K][]V {
+	var batches map[K][]V
+	b.d.peek(func(value interface{}) {
+		pending := toKeyedBatch[K, V](value).batches
+		batches = make(map[K][]V, len(pending))
+		for k, values := range pending {
+			batches[k] = append([]V(nil), values...)
+		}
+	})
+
+	return batches
+}
+
+// Flush passes the pending values to the callback function right away, like
+// Debouncer.Flush. It has no effect if no values are pending, in which case it
+// returns ErrNothingPending.
+func (b *KeyedBatchDebouncer[K, V]) Flush() error {
+	return b.d.Flush()
+}
+
+// Cancel discards the pending values.
+func (b *KeyedBatchDebouncer[K, V]) Cancel() {
+	b.d.Cancel()
+}
+
+// Close stops the KeyedBatchDebouncer like Debouncer.Close, discarding the
+// pending values unless WithCloseTimeout is used. Further calls to Add have no
+// effect.
+func (b *KeyedBatchDebouncer[K, V]) Close() error {
+	return b.d.Close()
+}
+
+func newKeyedBatch[K comparable, V any](
+	wait time.Duration,
+	f func(batches map[K][]V),
+	opts []KeyedBatchOption,
+) *Debouncer {
+	o := keyedBatchOptions{}
+	for _, opt := range opts {
+		opt.applyKeyedBatch(&o)
+	}
+
+	d := newDebouncer(wait, func(_ context.Context, info InvokeInfo) {
+		f(toKeyedBatch[K, V](info.value).batches)
+	}, o.opts)
+	d.combine = func(acc, next interface{}) interface{} {
+		b := toKeyedBatch[K, V](acc)
+		n := toKeyedBatch[K, V](next)
+		for k, values := range n.batches {
+			b.add(k, values...)
+		}
+
+		return b
+	}
+	maxTotal, maxKey := d.opts.maxBatchSize, o.maxKeyBatchSize
+	if maxTotal > 0 || maxKey > 0 {
+		d.full = func(value interface{}) bool {
+			b := toKeyedBatch[K, V](value)
+
+			return (maxTotal > 0 && b.total >= maxTotal) ||
+				(maxKey > 0 && b.longest >= maxKey)
+		}
+	}
+
+	return d
+}
+
+// KeyedBatchOption configures optional behavior of a debounced function
+// returned by NewKeyedBatch, or of a KeyedBatchDebouncer. Any Option is a
+// KeyedBatchOption, and so is WithMaxKeyBatchSize.
+type KeyedBatchOption interface {
+	applyKeyedBatch(o *keyedBatchOptions)
+}
+
+type keyedBatchOptions struct {
+	opts            []Option
+	maxKeyBatchSize int
+}
+
+type keyedBatchOption func(o *keyedBatchOptions)
+
+func (f keyedBatchOption) applyKeyedBatch(o *keyedBatchOptions) {
+	f(o)
+}
+
+func (f Option) applyKeyedBatch(o *keyedBatchOptions) {
+	o.opts = append(o.opts, f)
+}
+
+// WithMaxKeyBatchSize makes a debounced function returned by NewKeyedBatch, or
+// a KeyedBatchDebouncer, invoke its callback function right away once n values
+// have been collected for any one key, like WithMaxBatchSize does for the
+// values of all keys.
+func WithMaxKeyBatchSize(n int) KeyedBatchOption {
+	return keyedBatchOption(func(o *keyedBatchOptions) {
+		o.maxKeyBatchSize = n
+	})
+}
+
+// keyedBatch is the map of values collected by a function returned by
+// NewKeyedBatch, along with the counts its size limits are checked against.
+type keyedBatch[K comparable, V any] struct {
+	batches map[K][]V
+	total   int
+	longest int
+}
+
+func (b *keyedBatch[K, V]) add(key K, values ...V) {
+	batch := append(b.batches[key], values...)
+	b.batches[key] = batch
+	b.total += len(values)
+	if len(batch) > b.longest {
+		b.longest = len(batch)
+	}
+}
+
+// toKeyedBatch returns value as a keyedBatch, which is either value itself, or
+// a new keyedBatch holding value if it is a single mapEntry.
+func toKeyedBatch[K comparable, V any](value interface{}) *keyedBatch[K, V] {
+	switch value := value.(type) {
+	case *keyedBatch[K, V]:
+		return value
+	case mapEntry[K, V]:
+		b := &keyedBatch[K, V]{batches: map[K][]V{}}
+		b.add(value.key, value.value)
+
+		return b
+	default:
+		return &keyedBatch[K, V]{batches: map[K][]V{}}
+	}
+}
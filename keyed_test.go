@@ -0,0 +1,146 @@
+package debounce
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewKeyedBatch(t *testing.T) {
+	t.Parallel()
+
+	t.Run("groups values by key", func(t *testing.T) {
+		t.Parallel()
+
+		got := make(chan map[string][]int, 2)
+		add, _ := NewKeyedBatch(20*time.Millisecond,
+			func(m map[string][]int) { got <- m },
+		)
+
+		add("a", 1)
+		add("b", 2)
+		add("a", 3)
+		add("c", 4)
+		add("b", 5)
+		add("a", 6)
+
+		m := <-got
+		assert.Equal(t, map[string][]int{
+			"a": {1, 3, 6},
+			"b": {2, 5},
+			"c": {4},
+		}, m)
+
+		add("a", 7)
+		assert.Equal(t, map[string][]int{"a": {7}}, <-got)
+		// The map passed to the first invocation is left alone.
+		assert.Equal(t, []int{1, 3, 6}, m["a"])
+	})
+
+	t.Run("max values", func(t *testing.T) {
+		t.Parallel()
+
+		got := make(chan map[string][]int, 3)
+		add, _ := NewKeyedBatch(time.Minute,
+			func(m map[string][]int) { got <- m },
+			WithMaxBatchSize(3),
+		)
+
+		add("a", 1)
+		add("b", 2)
+		add("a", 3)
+		add("b", 4)
+		add("b", 5)
+		add("c", 6)
+
+		assert.ElementsMatch(t, []map[string][]int{
+			{"a": {1, 3}, "b": {2}},
+			{"b": {4, 5}, "c": {6}},
+		}, []map[string][]int{<-got, <-got})
+	})
+
+	t.Run("max values per key", func(t *testing.T) {
+		t.Parallel()
+
+		mux := sync.Mutex{}
+		var got []map[string][]int
+		add, _ := NewKeyedBatch(20*time.Millisecond,
+			func(m map[string][]int) {
+				mux.Lock()
+				defer mux.Unlock()
+				got = append(got, m)
+			},
+			WithMaxKeyBatchSize(2),
+			WithWorker(),
+		)
+
+		add("a", 1)
+		add("b", 2)
+		add("b", 3)
+		add("a", 4)
+		add("a", 5)
+		time.Sleep(40 * time.Millisecond)
+
+		mux.Lock()
+		defer mux.Unlock()
+		assert.Equal(t, []map[string][]int{
+			{"a": {1}, "b": {2, 3}},
+			{"a": {4, 5}},
+		}, got)
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		t.Parallel()
+
+		got := make(chan map[string][]int, 2)
+		add, cancel := NewKeyedBatch(10*time.Millisecond,
+			func(m map[string][]int) { got <- m },
+		)
+
+		add("a", 1)
+		cancel()
+		add("a", 2)
+		assert.Equal(t, map[string][]int{"a": {2}}, <-got)
+	})
+}
+
+func TestKeyedBatchDebouncer(t *testing.T) {
+	t.Parallel()
+
+	batches := make(chan map[string][]int, 4)
+	b := NewKeyedBatchDebouncer(time.Minute, func(m map[string][]int) {
+		batches <- m
+	})
+
+	assert.Equal(t, 0, b.Len())
+	assert.Nil(t, b.Peek())
+	assert.ErrorIs(t, b.Flush(), ErrNothingPending)
+
+	b.Add("a", 1)
+	assert.Equal(t, map[string][]int{"a": {1}}, b.Peek())
+	b.Add("b", 2)
+	b.Add("a", 3)
+	peeked := b.Peek()
+	assert.Equal(t, map[string][]int{"a": {1, 3}, "b": {2}}, peeked)
+	assert.Equal(t, 3, b.Len())
+
+	// Peek returns a copy.
+	peeked["a"][0] = 10
+	peeked["c"] = []int{4}
+	assert.Equal(t, map[string][]int{"a": {1, 3}, "b": {2}}, b.Peek())
+
+	assert.NoError(t, b.Flush())
+	assert.Equal(t, map[string][]int{"a": {1, 3}, "b": {2}}, <-batches)
+	assert.Equal(t, 0, b.Len())
+
+	b.Add("a", 5)
+	b.Cancel()
+	assert.Nil(t, b.Peek())
+
+	assert.NoError(t, b.Close())
+	b.Add("a", 6)
+	assert.ErrorIs(t, b.Flush(), ErrClosed)
+	assert.Len(t, batches, 0)
+}
@@ -0,0 +1,128 @@
+package debounce
+
+import (
+	"sync"
+	"time"
+)
+
+// Allower gates invocations of a callback function, typically by means of a
+// rate limiter which may be shared between multiple debouncers.
+//
+// A *rate.Limiter from golang.org/x/time/rate can be used with AllowerFunc:
+//
+//	debounce.AllowerFunc(func() time.Duration {
+//		return limiter.Reserve().Delay()
+//	})
+type Allower interface {
+	// Reserve reserves permission for one invocation, and returns how long
+	// to wait before the invocation is allowed to happen. It is called while
+	// holding the debouncer's internal lock, and must not block.
+	Reserve() time.Duration
+}
+
+// AllowerFunc is an adapter to allow the use of ordinary functions as
+// Allowers.
+type AllowerFunc func() time.Duration
+
+// Reserve calls af().
+func (af AllowerFunc) Reserve() time.Duration {
+	return af()
+}
+
+// windowAllower is an Allower which allows at most n invocations within any
+// rolling window of time, handing out slots in the order they are reserved.
+type windowAllower struct {
+	n      int
+	window time.Duration
+
+	mux sync.Mutex
+	// slots holds the times of the last n reservations, oldest first.
+	slots []time.Time
+}
+
+func (wa *windowAllower) Reserve() time.Duration {
+	wa.mux.Lock()
+	defer wa.mux.Unlock()
+
+	now := time.Now()
+	at := now
+	if len(wa.slots) == wa.n {
+		if next := wa.slots[0].Add(wa.window); next.After(at) {
+			at = next
+		}
+		wa.slots = append(wa.slots[:0], wa.slots[1:]...)
+	}
+	wa.slots = append(wa.slots, at)
+
+	return at.Sub(now)
+}
+
+// Limiter answers whether something should be done right away, like emitting
+// a warning at most once per quiet period, rather than invoking a callback
+// function. Allow reports true for the calls which would invoke a callback
+// function on the leading edge of a burst, as with WithBurstPassThrough(1).
+//
+// All methods are safe for concurrent use in goroutines.
+type Limiter struct {
+	wait    time.Duration
+	maxWait time.Duration
+	// now returns the current time, and is only replaced in tests.
+	now func() time.Time
+
+	mux sync.Mutex
+	// lastCall is the time of the last call to Allow, and allowedAt the time
+	// of the last call which returned true.
+	lastCall   time.Time
+	allowedAt  time.Time
+	suppressed int
+}
+
+// NewLimiter returns a new Limiter, which allows a call once wait time has
+// elapsed since the last call, so only the first call of each burst of calls
+// is allowed.
+//
+// Under constant pressure, where calls never pause for the wait time, a
+// maximum wait time set with WithMaxWait allows a call once the maximum wait
+// time has elapsed since the last allowed call. Other options have no effect.
+func NewLimiter(wait time.Duration, opts ...Option) *Limiter {
+	o := newOptions(opts)
+
+	return &Limiter{wait: wait, maxWait: o.maxWait, now: time.Now}
+}
+
+// Allow reports if the call should go ahead, as it is the first call of a
+// burst, or the maximum wait time has elapsed since the last allowed call.
+// Either way, the call extends the current burst.
+func (l *Limiter) Allow() bool {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	now := l.now()
+	allowed := l.lastCall.IsZero() || elapsed(l.lastCall, now) >= l.wait ||
+		(l.maxWait > 0 && elapsed(l.allowedAt, now) >= l.maxWait)
+	l.lastCall = now
+
+	if !allowed {
+		l.suppressed++
+
+		return false
+	}
+
+	l.allowedAt = now
+
+	return true
+}
+
+// Suppressed returns the number of calls to Allow which returned false since
+// the last call to Suppressed, and resets the count. Calling it after each call
+// to Allow which returned true reports how many calls were suppressed before
+// it, like for a "suppressed N similar warnings" note.
+func (l *Limiter) Suppressed() int {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	n := l.suppressed
+	l.suppressed = 0
+
+	return n
+}
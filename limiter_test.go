@@ -0,0 +1,176 @@
+package debounce
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type scriptedAllower struct {
+	mux    sync.Mutex
+	delays []time.Duration
+	calls  int
+}
+
+func (sa *scriptedAllower) Reserve() time.Duration {
+	sa.mux.Lock()
+	defer sa.mux.Unlock()
+
+	sa.calls++
+	if len(sa.delays) == 0 {
+		return 0
+	}
+
+	delay := sa.delays[0]
+	sa.delays = sa.delays[1:]
+
+	return delay
+}
+
+func (sa *scriptedAllower) Calls() int {
+	sa.mux.Lock()
+	defer sa.mux.Unlock()
+
+	return sa.calls
+}
+
+func TestWithRateLimiter(t *testing.T) {
+	t.Parallel()
+
+	limiter := &scriptedAllower{
+		delays: []time.Duration{0, 40 * time.Millisecond, 0},
+	}
+	fired := make(chan time.Time, 10)
+	d := NewDebouncer(5*time.Millisecond, func() {
+		fired <- time.Now()
+	}, WithRateLimiter(limiter))
+
+	// Allowed right away.
+	start := time.Now()
+	d.Debounce()
+	at := <-fired
+	assert.Less(t, at.Sub(start), 20*time.Millisecond)
+
+	// Deferred by 40ms from when the wait expires, with further calls being
+	// coalesced into the deferred invocation.
+	start = time.Now()
+	d.Debounce()
+	time.Sleep(15 * time.Millisecond)
+	d.Debounce()
+	d.Debounce()
+	at = <-fired
+	assert.GreaterOrEqual(t, at.Sub(start), 45*time.Millisecond)
+
+	time.Sleep(30 * time.Millisecond)
+	assert.Len(t, fired, 0)
+
+	// Each reservation is used exactly once, without polling the limiter.
+	assert.Equal(t, 2, limiter.Calls())
+	assert.Equal(t, 2, d.Stats().Invocations)
+}
+
+func TestAllowerFunc(t *testing.T) {
+	t.Parallel()
+
+	af := AllowerFunc(func() time.Duration { return time.Second })
+
+	assert.Equal(t, time.Second, af.Reserve())
+}
+
+func TestLimiter(t *testing.T) {
+	t.Parallel()
+
+	// step is a call to Allow made at offset from the start, and the result
+	// it is expected to return.
+	type step struct {
+		offset time.Duration
+		want   bool
+	}
+
+	ms := time.Millisecond
+	tests := []struct {
+		name    string
+		maxWait time.Duration
+		steps   []step
+	}{
+		{
+			name: "bursts",
+			steps: []step{
+				{0, true}, {5 * ms, false}, {10 * ms, false},
+				{20 * ms, true}, {25 * ms, false},
+				{34 * ms, false}, {44 * ms, true},
+			},
+		},
+		{
+			name: "steady stream",
+			steps: []step{
+				{0, true}, {5 * ms, false}, {10 * ms, false},
+				{15 * ms, false}, {20 * ms, false}, {25 * ms, false},
+			},
+		},
+		{
+			name:    "steady stream with max wait",
+			maxWait: 12 * ms,
+			steps: []step{
+				{0, true}, {5 * ms, false}, {10 * ms, false},
+				{15 * ms, true}, {20 * ms, false}, {25 * ms, false},
+				{30 * ms, true},
+			},
+		},
+		{
+			name: "clock stepping backwards",
+			steps: []step{
+				{0, true}, {-time.Hour, false}, {-time.Hour + 5*ms, false},
+				{-time.Hour + 20*ms, true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			start := time.Now()
+			now := start
+			l := NewLimiter(10*time.Millisecond, WithMaxWait(tt.maxWait))
+			l.now = func() time.Time { return now }
+
+			suppressed := 0
+			for _, s := range tt.steps {
+				now = start.Add(s.offset)
+				assert.Equal(t, s.want, l.Allow(), "call at %s", s.offset)
+
+				if !s.want {
+					suppressed++
+
+					continue
+				}
+
+				// The calls suppressed before an allowed one are still
+				// reported after it, until read.
+				assert.Equal(t, suppressed, l.Suppressed(), "call at %s",
+					s.offset)
+				assert.Equal(t, 0, l.Suppressed())
+				suppressed = 0
+			}
+		})
+	}
+
+	t.Run("real clock", func(t *testing.T) {
+		t.Parallel()
+
+		l := NewLimiter(20 * time.Millisecond)
+		assert.True(t, l.Allow())
+		assert.False(t, l.Allow())
+		assert.False(t, l.Allow())
+		assert.Equal(t, 2, l.Suppressed())
+		assert.Equal(t, 0, l.Suppressed())
+
+		time.Sleep(30 * time.Millisecond)
+		assert.True(t, l.Allow())
+		assert.False(t, l.Allow())
+		assert.Equal(t, 1, l.Suppressed())
+	})
+}
@@ -0,0 +1,76 @@
+package debounce
+
+import (
+	"context"
+	"time"
+)
+
+// NewMap returns a debounced function like NewBatch, but which collects the
+// key and value of each call into a map, keeping the last value set for each
+// key, and passes the map to f. Each invocation of f receives a new map, which
+// f is free to keep or modify, while calls made afterwards start a new map.
+//
+// The maximum number of keys per map can be limited with WithMaxBatchSize.
+//
+// The returned cancel function can be used to cancel any pending invocation of
+// f, discarding the values collected for it, but is not required to be called,
+// so can be ignored if not needed.
+//
+// Both set and cancel functions are safe for concurrent use in goroutines, and
+// can both be called multiple times.
+func NewMap[K comparable, V any](
+	wait time.Duration,
+	f func(m map[K]V),
+	opts ...Option,
+) (set func(key K, value V), cancel func()) {
+	d := newDebouncer(wait, func(_ context.Context, info InvokeInfo) {
+		f(toMap[K, V](info.value))
+	}, opts)
+	d.combine = func(acc, next interface{}) interface{} {
+		m := toMap[K, V](acc)
+		switch next := next.(type) {
+		case mapEntry[K, V]:
+			m[next.key] = next.value
+		case map[K]V:
+			for k, v := range next {
+				m[k] = v
+			}
+		}
+
+		return m
+	}
+	if n := d.opts.maxBatchSize; n > 0 {
+		d.full = func(value interface{}) bool {
+			size := 1
+			if m, ok := value.(map[K]V); ok {
+				size = len(m)
+			}
+
+			return size >= n
+		}
+	}
+
+	return func(key K, value V) {
+		d.add(mapEntry[K, V]{key: key, value: value})
+	}, d.cancelFunc()
+}
+
+// mapEntry is the value of a single call to a function returned by NewMap,
+// which saves allocating a map for calls which are not coalesced.
+type mapEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// toMap returns value as a map, which is either value itself, or a new map
+// holding value if it is a single mapEntry.
+func toMap[K comparable, V any](value interface{}) map[K]V {
+	switch value := value.(type) {
+	case map[K]V:
+		return value
+	case mapEntry[K, V]:
+		return map[K]V{value.key: value.value}
+	default:
+		return map[K]V{}
+	}
+}
@@ -0,0 +1,95 @@
+package debounce
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMap(t *testing.T) {
+	t.Parallel()
+
+	t.Run("merge", func(t *testing.T) {
+		t.Parallel()
+
+		got := make(chan map[string]int, 2)
+		set, _ := NewMap(20*time.Millisecond, func(m map[string]int) {
+			got <- m
+		})
+
+		start := time.Now()
+		set("a", 1)
+		set("b", 2)
+		time.Sleep(10 * time.Millisecond)
+		set("a", 3)
+
+		m := <-got
+		// from call at 10ms (+20ms wait = 30ms)
+		assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+		assert.Equal(t, map[string]int{"a": 3, "b": 2}, m)
+
+		set("c", 4)
+		assert.Equal(t, map[string]int{"c": 4}, <-got)
+		// The map passed to the first invocation is left alone.
+		assert.Equal(t, map[string]int{"a": 3, "b": 2}, m)
+	})
+
+	t.Run("max wait", func(t *testing.T) {
+		t.Parallel()
+
+		mux := sync.Mutex{}
+		var got []map[int]int
+		set, _ := NewMap(20*time.Millisecond, func(m map[int]int) {
+			mux.Lock()
+			defer mux.Unlock()
+			got = append(got, m)
+		}, WithMaxWait(45*time.Millisecond))
+
+		for i := 0; i < 6; i++ {
+			set(i%2, i)
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		mux.Lock()
+		defer mux.Unlock()
+		require.Len(t, got, 1)
+		assert.Equal(t, map[int]int{0: 4, 1: 3}, got[0])
+	})
+
+	t.Run("max keys", func(t *testing.T) {
+		t.Parallel()
+
+		got := make(chan map[int]bool, 3)
+		set, _ := NewMap(time.Minute, func(m map[int]bool) {
+			got <- m
+		}, WithMaxBatchSize(2))
+
+		set(1, true)
+		set(1, false)
+		set(2, true)
+		set(3, true)
+		set(4, true)
+
+		assert.ElementsMatch(t, []map[int]bool{
+			{1: false, 2: true},
+			{3: true, 4: true},
+		}, []map[int]bool{<-got, <-got})
+	})
+
+	t.Run("single key", func(t *testing.T) {
+		t.Parallel()
+
+		got := make(chan map[int]int, 1)
+		set, _ := NewMap(5*time.Millisecond, func(m map[int]int) {
+			got <- m
+		})
+
+		set(1, 1)
+		m := <-got
+		m[2] = 2
+		assert.Equal(t, map[int]int{1: 1, 2: 2}, m)
+	})
+}
@@ -1,7 +1,6 @@
 package debounce
 
 import (
-	"sync"
 	"time"
 )
 
@@ -14,37 +13,23 @@ import (
 //
 // Only the very last f passed to the debounced function is called when the
 // delay expires and the callback function is invoked. Previous f values are
-// discarded.
+// discarded, as is the called f, so an invocation only ever calls an f passed
+// since the previous invocation. Passing a nil f schedules an invocation which
+// does nothing, unless a later call passes another f.
 //
 // Both debounced and cancel functions are safe for concurrent use in
 // goroutines, and can both be called multiple times.
-func NewMutable(wait time.Duration) (debounced func(f func()), cancel func()) {
-	var mux sync.Mutex
-	var fn func()
-
-	timer := stoppedTimer(func() {
-		mux.Lock()
-		defer mux.Unlock()
-
-		go fn()
-	})
-
-	debounced = func(f func()) {
-		mux.Lock()
-		defer mux.Unlock()
-
-		fn = f
-		timer.Reset(wait)
-	}
-
-	cancel = func() {
-		mux.Lock()
-		defer mux.Unlock()
-
-		timer.Stop()
-	}
+//
+// Optional behavior can be configured by passing one or more Option values,
+// which work as they do for New. For example WithBurstPassThrough(1) calls the
+// f passed to the first call of a burst right away.
+func NewMutable(
+	wait time.Duration,
+	opts ...Option,
+) (debounced func(f func()), cancel func()) {
+	d := NewDebouncer(wait, func() {}, opts...)
 
-	return debounced, cancel
+	return d.DebounceWith, d.cancelFunc()
 }
 
 // NewMutableWithMaxWait is a combination of NewMutable and NewWithMaxWait.
@@ -59,50 +44,38 @@ func NewMutable(wait time.Duration) (debounced func(f func()), cancel func()) {
 // goroutines, and can both be called multiple times.
 func NewMutableWithMaxWait(
 	wait, maxWait time.Duration,
+	opts ...Option,
 ) (debounced func(f func()), cancel func()) {
-	var mux sync.Mutex
-	var fn func()
-	var timer *time.Timer
-	var maxTimer *time.Timer
-
-	cb := func() {
-		mux.Lock()
-		defer mux.Unlock()
-
-		if fn == nil {
-			return
-		}
-
-		go fn()
-		timer.Stop()
-		maxTimer.Stop()
-		fn = nil
-	}
+	d := NewDebouncer(wait, func() {}, opts...).withMaxWait(maxWait)
 
-	timer = stoppedTimer(cb)
-	maxTimer = stoppedTimer(cb)
-
-	debounced = func(f func()) {
-		mux.Lock()
-		defer mux.Unlock()
-
-		timer.Reset(wait)
+	return d.DebounceWith, d.cancelFunc()
+}
 
-		if fn == nil {
-			maxTimer.Reset(maxWait)
+// NewAccumulatingMutable returns a debounced function like NewMutable, but
+// rather than only calling the last f passed to the debounced function, each
+// invocation calls all functions passed since the previous invocation, one at a
+// time in the order they were passed, on a single goroutine. A nil f is
+// skipped.
+//
+// The number of functions per invocation can be limited with WithMaxBatchSize.
+//
+// The returned cancel function can be used to cancel any pending invocation,
+// discarding the functions collected for it, but is not required to be called,
+// so can be ignored if not needed.
+//
+// Both debounced and cancel functions are safe for concurrent use in
+// goroutines, and can both be called multiple times.
+func NewAccumulatingMutable(
+	wait time.Duration,
+	opts ...Option,
+) (debounced func(f func()), cancel func()) {
+	d := newBatch(wait, func(fs []func()) {
+		for _, f := range fs {
+			if f != nil {
+				f()
+			}
 		}
+	}, opts)
 
-		fn = f
-	}
-
-	cancel = func() {
-		mux.Lock()
-		defer mux.Unlock()
-
-		timer.Stop()
-		maxTimer.Stop()
-		fn = nil
-	}
-
-	return debounced, cancel
+	return func(f func()) { d.add([]func(){f}) }, d.cancelFunc()
 }
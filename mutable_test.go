@@ -1,7 +1,9 @@
 package debounce
 
 import (
+	"runtime"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -152,6 +154,102 @@ func TestNewMutable(t *testing.T) {
 	}
 }
 
+func TestNewMutable_cancelRace(t *testing.T) {
+	t.Parallel()
+
+	t.Run("cancel at timer expiry", func(t *testing.T) {
+		t.Parallel()
+
+		for i := 0; i < 200; i++ {
+			var n int32
+			debounced, cancel := NewMutable(time.Millisecond)
+
+			debounced(func() { atomic.AddInt32(&n, 1) })
+			// Vary the timing, so cancel lands before, during and after the
+			// timer expiring.
+			time.Sleep(time.Duration(i%20) * 100 * time.Microsecond)
+			cancel()
+			time.Sleep(3 * time.Millisecond)
+
+			assert.LessOrEqual(t, atomic.LoadInt32(&n), int32(1))
+		}
+	})
+
+	t.Run("nil functions", func(t *testing.T) {
+		t.Parallel()
+
+		for i := 0; i < 200; i++ {
+			debounced, cancel := NewMutable(time.Millisecond)
+
+			// Cancel before any call, and race nil functions with cancel.
+			cancel()
+			debounced(nil)
+			time.Sleep(time.Duration(i%20) * 100 * time.Microsecond)
+			cancel()
+			debounced(nil)
+		}
+		time.Sleep(3 * time.Millisecond)
+	})
+}
+
+func TestNewMutable_releasesCallback(t *testing.T) {
+	t.Parallel()
+
+	// watch returns a function capturing a value, and a channel which is
+	// closed once the value has been garbage collected.
+	watch := func(f func()) (func(), <-chan struct{}) {
+		released := make(chan struct{})
+		payload := new([1024]byte)
+		runtime.SetFinalizer(payload, func(*[1024]byte) { close(released) })
+
+		return func() {
+			payload[0]++
+			f()
+		}, released
+	}
+	waitReleased := func(t *testing.T, released <-chan struct{}) {
+		t.Helper()
+
+		timeout := time.After(time.Second)
+		for {
+			runtime.GC()
+			select {
+			case <-released:
+				return
+			case <-timeout:
+				t.Fatal("callback function retained")
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	}
+
+	t.Run("after invocation", func(t *testing.T) {
+		t.Parallel()
+
+		debounced, cancel := NewMutable(time.Millisecond)
+		defer cancel()
+
+		called := make(chan struct{})
+		f, released := watch(func() { close(called) })
+		debounced(f)
+		<-called
+
+		waitReleased(t, released)
+	})
+
+	t.Run("after cancel", func(t *testing.T) {
+		t.Parallel()
+
+		debounced, cancel := NewMutable(time.Hour)
+
+		f, released := watch(func() {})
+		debounced(f)
+		cancel()
+
+		waitReleased(t, released)
+	})
+}
+
 func TestNewMutableAndMaxWait(t *testing.T) {
 	t.Parallel()
 
@@ -159,10 +257,55 @@ func TestNewMutableAndMaxWait(t *testing.T) {
 		name         string
 		wait         time.Duration
 		maxwait      time.Duration
+		opts         []Option
 		calls        []testOp
 		wantTriggers map[time.Duration]int
 		wantFuncs    []int
 	}{
+		{
+			name:    "leading and maxWait",
+			wait:    20 * time.Millisecond,
+			maxwait: 50 * time.Millisecond,
+			opts:    []Option{WithBurstPassThrough(1)},
+			calls: []testOp{
+				{delay: 0 * time.Millisecond}, // leading
+				{delay: 10 * time.Millisecond},
+				{delay: 20 * time.Millisecond},
+				{delay: 30 * time.Millisecond},
+				{delay: 40 * time.Millisecond},
+				{delay: 50 * time.Millisecond},
+				// maxWait triggers at 60ms (10ms + 50ms)
+				{delay: 100 * time.Millisecond}, // leading
+			},
+			wantTriggers: map[time.Duration]int{
+				5 * time.Millisecond:  1,
+				55 * time.Millisecond: 1,
+				// tick over at 60ms via maxWait
+				65 * time.Millisecond: 2,
+				95 * time.Millisecond: 2,
+				// idle for wait time, so the next call leads again
+				105 * time.Millisecond: 3,
+				150 * time.Millisecond: 3,
+			},
+			wantFuncs: []int{0, 5, 6},
+		},
+		{
+			name:    "leading only",
+			wait:    20 * time.Millisecond,
+			maxwait: 50 * time.Millisecond,
+			opts:    []Option{WithBurstPassThrough(1)},
+			calls: []testOp{
+				{delay: 10 * time.Millisecond}, // leading
+				{delay: 40 * time.Millisecond}, // leading
+			},
+			wantTriggers: map[time.Duration]int{
+				5 * time.Millisecond:   0,
+				15 * time.Millisecond:  1,
+				45 * time.Millisecond:  2,
+				150 * time.Millisecond: 2,
+			},
+			wantFuncs: []int{0, 1},
+		},
 		{
 			name:    "all within wait time",
 			wait:    20 * time.Millisecond,
@@ -204,6 +347,27 @@ func TestNewMutableAndMaxWait(t *testing.T) {
 			},
 			wantFuncs: []int{4},
 		},
+		{
+			name:    "until right before maxWait, wait much longer",
+			wait:    500 * time.Millisecond,
+			maxwait: 50 * time.Millisecond,
+			calls: []testOp{
+				{delay: 0 * time.Millisecond},
+				{delay: 10 * time.Millisecond},
+				{delay: 20 * time.Millisecond},
+				{delay: 30 * time.Millisecond},
+				{delay: 40 * time.Millisecond},
+			},
+			wantTriggers: map[time.Duration]int{
+				45 * time.Millisecond: 0,
+				// tick over at 50ms via maxWait, without waiting for the
+				// wait time to elapse after the last call at 40ms
+				55 * time.Millisecond: 1,
+				// still 1 at at the end
+				100 * time.Millisecond: 1,
+			},
+			wantFuncs: []int{4},
+		},
 		{
 			name:    "until right after maxWait",
 			wait:    20 * time.Millisecond,
@@ -320,7 +484,7 @@ func TestNewMutableAndMaxWait(t *testing.T) {
 			n := 0
 			got := []int{}
 
-			d, c := NewMutableWithMaxWait(tt.wait, tt.maxwait)
+			d, c := NewMutableWithMaxWait(tt.wait, tt.maxwait, tt.opts...)
 
 			wg := sync.WaitGroup{}
 			for i, op := range tt.calls {
@@ -361,3 +525,64 @@ func TestNewMutableAndMaxWait(t *testing.T) {
 		})
 	}
 }
+
+func TestNewAccumulatingMutable(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		opts      []Option
+		calls     int
+		cancelAt  int
+		wantFuncs []int
+	}{
+		{
+			name:      "all in order",
+			calls:     10,
+			cancelAt:  -1,
+			wantFuncs: []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		},
+		{
+			name:      "max batch size",
+			opts:      []Option{WithMaxBatchSize(4), WithSerializedExecution()},
+			calls:     10,
+			cancelAt:  -1,
+			wantFuncs: []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		},
+		{
+			name:      "cancel",
+			calls:     10,
+			cancelAt:  5,
+			wantFuncs: []int{5, 6, 7, 8, 9},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mux := sync.Mutex{}
+			got := []int{}
+
+			d, c := NewAccumulatingMutable(20*time.Millisecond, tt.opts...)
+			for i := 0; i < tt.calls; i++ {
+				if i == tt.cancelAt {
+					c()
+				}
+				i := i
+				d(func() {
+					mux.Lock()
+					defer mux.Unlock()
+					got = append(got, i)
+				})
+			}
+			d(nil)
+
+			time.Sleep(50 * time.Millisecond)
+
+			mux.Lock()
+			defer mux.Unlock()
+			assert.Equal(t, tt.wantFuncs, got)
+		})
+	}
+}
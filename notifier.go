@@ -0,0 +1,105 @@
+package debounce
+
+import (
+	"sync"
+	"time"
+)
+
+// Notifier debounces calls to its Notify method like a Debouncer, and notifies
+// all of its subscribers once the wait time has elapsed.
+//
+// Each subscriber receives notifications on a channel with a buffer of one. A
+// notification which is due while the subscriber's previous one has not been
+// received yet is coalesced into it, so a slow subscriber never holds up the
+// others.
+//
+// All methods are safe for concurrent use in goroutines.
+type Notifier struct {
+	d *Debouncer
+
+	mux    sync.Mutex
+	subs   map[chan struct{}]struct{}
+	closed bool
+}
+
+// NewNotifier returns a new Notifier which notifies its subscribers once wait
+// time has elapsed since the last call to Notify.
+//
+// Optional behavior can be configured by passing one or more Option values.
+func NewNotifier(wait time.Duration, opts ...Option) *Notifier {
+	n := &Notifier{subs: map[chan struct{}]struct{}{}}
+	n.d = NewDebouncer(wait, n.notify, opts...)
+
+	return n
+}
+
+// Notify schedules a notification of all subscribers, postponing any already
+// pending notification until wait time has elapsed since this call.
+func (n *Notifier) Notify() {
+	n.d.add(nil)
+}
+
+// Subscribe returns a channel which receives a value for each notification,
+// and a function which unsubscribes the channel and closes it. The
+// unsubscribe function can be called multiple times.
+//
+// Subscribing to a closed Notifier returns a closed channel.
+func (n *Notifier) Subscribe() (
+	notifications <-chan struct{},
+	unsubscribe func(),
+) {
+	ch := make(chan struct{}, 1)
+
+	n.mux.Lock()
+	defer n.mux.Unlock()
+
+	if n.closed {
+		close(ch)
+
+		return ch, func() {}
+	}
+	n.subs[ch] = struct{}{}
+
+	return ch, func() {
+		n.mux.Lock()
+		defer n.mux.Unlock()
+
+		if _, ok := n.subs[ch]; ok {
+			delete(n.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// Close cancels any pending notification, and closes the channels of all
+// subscribers. It returns ErrTimeout if a running notification did not
+// complete within the grace period set with WithCloseTimeout. Calling Close
+// more than once has no further effect.
+func (n *Notifier) Close() error {
+	err := n.d.Close()
+
+	n.mux.Lock()
+	defer n.mux.Unlock()
+
+	n.closed = true
+	for ch := range n.subs {
+		delete(n.subs, ch)
+		close(ch)
+	}
+
+	return err
+}
+
+// notify sends a notification to all subscribers which do not have one
+// pending already.
+func (n *Notifier) notify() {
+	n.mux.Lock()
+	defer n.mux.Unlock()
+
+	for ch := range n.subs {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package debounce
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotifier(t *testing.T) {
+	t.Parallel()
+
+	t.Run("multiple subscribers", func(t *testing.T) {
+		t.Parallel()
+
+		n := NewNotifier(10 * time.Millisecond)
+		defer n.Close()
+
+		a, _ := n.Subscribe()
+		b, _ := n.Subscribe()
+
+		n.Notify()
+		n.Notify()
+		time.Sleep(30 * time.Millisecond)
+
+		assert.Len(t, a, 1)
+		<-b
+
+		// A subscriber which has not received its previous notification gets
+		// the next one coalesced into it, without holding up others.
+		n.Notify()
+		time.Sleep(30 * time.Millisecond)
+
+		assert.Len(t, a, 1)
+		assert.Len(t, b, 1)
+	})
+
+	t.Run("unsubscribe mid-burst", func(t *testing.T) {
+		t.Parallel()
+
+		n := NewNotifier(20 * time.Millisecond)
+		defer n.Close()
+
+		a, unsubscribe := n.Subscribe()
+		b, _ := n.Subscribe()
+
+		n.Notify()
+		time.Sleep(5 * time.Millisecond)
+		unsubscribe()
+		unsubscribe()
+		time.Sleep(30 * time.Millisecond)
+
+		_, ok := <-a
+		assert.False(t, ok)
+		assert.Len(t, b, 1)
+	})
+
+	t.Run("close", func(t *testing.T) {
+		t.Parallel()
+
+		n := NewNotifier(10 * time.Millisecond)
+
+		a, unsubscribe := n.Subscribe()
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for {
+				if _, ok := <-a; !ok {
+					return
+				}
+			}
+		}()
+
+		n.Notify()
+		assert.NoError(t, n.Close())
+		assert.NoError(t, n.Close())
+		unsubscribe()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			assert.Fail(t, "subscriber was not released by Close")
+		}
+
+		b, _ := n.Subscribe()
+		_, ok := <-b
+		assert.False(t, ok)
+
+		// Notifying after Close has no effect.
+		n.Notify()
+		time.Sleep(20 * time.Millisecond)
+	})
+}
@@ -0,0 +1,728 @@
+package debounce
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// Option configures optional behavior of a debounced function.
+type Option func(*options)
+
+type options struct {
+	hasWait    bool
+	wait       time.Duration
+	maxWait    time.Duration
+	waitRange  bool
+	waitMin    time.Duration
+	waitMax    time.Duration
+	randSource rand.Source
+
+	backoff           bool
+	backoffFactor     float64
+	backoffMax        time.Duration
+	backoffResetAfter time.Duration
+
+	burstPassThrough int
+	serialized       bool
+	dropIfRunning    bool
+	invokeTimeout    time.Duration
+	deadline         time.Time
+	executor         Executor
+	firstWait        time.Duration
+	slack            time.Duration
+	maxWaitJitter    time.Duration
+	predicate        func() bool
+	invokeCondition  func() bool
+	onSuppressed     func(reason SuppressReason)
+	quota            int
+	quotaWindow      time.Duration
+	limiter          Allower
+	worker           bool
+	autoStop         int
+	onAutoStop       func()
+	onInvoke         func(info InvokeInfo)
+	reschedule       func(r Rescheduler)
+	captureCallers   bool
+	callerSkip       int
+	zeroWaitSync     bool
+	timerGranularity time.Duration
+	idleAfter        time.Duration
+	onIdle           func()
+	maxBatchSize     int
+	groupMaxIdle     time.Duration
+	groupMaxEntries  int
+	groupKeyOptions  interface{}
+	onError          func(err error)
+	scheduler        *Scheduler
+	maxPending       int
+	dropPolicy       DropPolicy
+	onDrop           interface{}
+	confirmWindow    time.Duration
+	activationRate   int
+	activationWindow time.Duration
+	registry         *Registry
+	closeTimeout     time.Duration
+	discardOnClose   bool
+	groupLimit       int
+	groupLimitWindow time.Duration
+	groupOnLimit     interface{}
+	schedule         Schedule
+	ignoreExpired    bool
+	dropCanceled     bool
+}
+
+func newOptions(opts []Option) *options {
+	o := applyOptions(opts)
+	if err := o.validate(); err != nil {
+		panic(err)
+	}
+
+	return o
+}
+
+// applyOptions is like newOptions, but does not validate the result.
+func applyOptions(opts []Option) *options {
+	o := &options{executor: goExecutor{}}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// validate returns an error wrapping ErrInvalidOption if any option was given
+// invalid values.
+func (o *options) validate() error {
+	if o.waitRange && (o.waitMin < 0 || o.waitMin > o.waitMax) {
+		return fmt.Errorf("%w: wait range %s to %s",
+			ErrInvalidOption, o.waitMin, o.waitMax)
+	}
+	if o.backoff && (o.backoffFactor <= 1 || o.backoffMax <= 0 ||
+		o.backoffResetAfter <= 0) {
+		return fmt.Errorf("%w: backoff factor %g, max %s, reset after %s",
+			ErrInvalidOption, o.backoffFactor, o.backoffMax,
+			o.backoffResetAfter)
+	}
+
+	return nil
+}
+
+// WithWait sets the wait time, taking precedence over the wait time given to
+// the constructor. It is mostly useful with WithGroupKeyOptions, to use a
+// different wait time for some keys of a Group.
+func WithWait(wait time.Duration) Option {
+	return func(o *options) {
+		o.hasWait = true
+		o.wait = wait
+	}
+}
+
+// WithMaxWait sets a maximum wait time, which is the maximum time the callback
+// function is allowed to be delayed before it is invoked. It takes precedence
+// over the maximum wait time given to constructors like NewWithMaxWait, and
+// adds one to those which do not take one, like New.
+func WithMaxWait(maxWait time.Duration) Option {
+	return func(o *options) {
+		o.maxWait = maxWait
+	}
+}
+
+// WithWaitRange makes the debounced function draw a new wait time uniformly
+// from [low, high] at the start of each burst of calls, i.e., on the first call
+// after the debounced function has been idle or has just invoked its callback.
+// The drawn wait is used for the whole burst, and takes precedence over the
+// fixed wait time given to the constructor.
+//
+// Randomizing the wait avoids many debounced functions which are triggered at
+// the same time from also invoking their callbacks at the same time.
+//
+// A low which is negative or greater than high is invalid, and makes
+// constructors panic with an error wrapping ErrInvalidOption, or Builder.Build
+// return it.
+func WithWaitRange(low, high time.Duration) Option {
+	return func(o *options) {
+		o.waitRange = true
+		o.waitMin = low
+		o.waitMax = high
+	}
+}
+
+// WithBackoff makes the wait time grow after each invocation of the callback
+// function, spacing out invocations when calls keep arriving in bursts. The
+// wait of each new burst is the previous one multiplied by factor, capped at
+// max. Once no calls have arrived for resetAfter, the wait returns to its
+// initial value. As each burst ends with no calls for its wait time, resetAfter
+// must be longer than the wait time for the wait to grow at all.
+//
+// When combined with a maximum wait time, the maximum wait time still caps the
+// total time the callback function can be delayed.
+//
+// A factor which is not greater than 1, or a max or resetAfter which is not
+// positive is invalid, and makes constructors panic with an error wrapping
+// ErrInvalidOption, or Builder.Build return it.
+func WithBackoff(factor float64, max, resetAfter time.Duration) Option {
+	return func(o *options) {
+		o.backoff = true
+		o.backoffFactor = factor
+		o.backoffMax = max
+		o.backoffResetAfter = resetAfter
+	}
+}
+
+// WithBurstPassThrough makes the first n calls of a burst invoke the callback
+// function immediately, with any further calls in the burst being debounced as
+// usual. The pass-through budget is replenished once the debounced function has
+// not been called for its wait time.
+func WithBurstPassThrough(n int) Option {
+	return func(o *options) {
+		o.burstPassThrough = n
+	}
+}
+
+// WithSerializedExecution makes sure invocations of the callback function
+// never overlap, and run in the order they were triggered.
+//
+// Invocations triggered while the callback function is running are queued.
+// The queue holds at most one invocation, as invocations of the same function
+// are interchangeable, so further invocations triggered while one is already
+// queued are coalesced into the queued one.
+func WithSerializedExecution() Option {
+	return func(o *options) {
+		o.serialized = true
+	}
+}
+
+// WithDropIfRunning makes invocations of the callback function which are
+// triggered while it is still running be dropped. Instead, a new wait period is
+// started once the running invocation completes, resulting in a single
+// follow-up invocation for all calls made while the callback was running.
+//
+// WithDropIfRunning takes precedence over WithSerializedExecution.
+func WithDropIfRunning() Option {
+	return func(o *options) {
+		o.dropIfRunning = true
+	}
+}
+
+// WithInvokeTimeout sets a timeout for each invocation of the callback
+// function. The context passed to callback functions of a Debouncer created
+// with NewDebouncerCtx is canceled once the timeout expires.
+func WithInvokeTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.invokeTimeout = timeout
+	}
+}
+
+// WithDeadline sets an absolute time by which any pending invocation of the
+// callback function is invoked, regardless of the wait and maximum wait times.
+// If no invocation is pending when the deadline is reached, nothing happens.
+//
+// The deadline only fires once, after which it can be set again with
+// SetDeadline on a Debouncer. It is not affected by cancellation of pending
+// invocations.
+func WithDeadline(t time.Time) Option {
+	return func(o *options) {
+		o.deadline = t
+	}
+}
+
+// WithExecutor sets the Executor used to run invocations of the callback
+// function. By default each invocation runs in a new goroutine.
+//
+// The executor's Execute method is called from the goroutine which triggered
+// the invocation, so an executor which blocks delays that goroutine, and with
+// it any subsequent invocations triggered from it.
+func WithExecutor(e Executor) Option {
+	return func(o *options) {
+		o.executor = e
+	}
+}
+
+// WithFirstWait sets the wait time used after the first call of a burst,
+// i.e., a call made while no invocation is pending. Subsequent calls within the
+// burst use the regular wait time.
+//
+// The maximum wait time, if any, is still measured from the first call of the
+// burst.
+func WithFirstWait(wait time.Duration) Option {
+	return func(o *options) {
+		o.firstWait = wait
+	}
+}
+
+// WithSlack allows the callback function to be invoked up to slack earlier than
+// the wait time dictates. Calls which would postpone the pending invocation by
+// less than slack leave the wait timer untouched, which reduces the overhead of
+// very frequent calls.
+//
+// The default slack of zero restarts the wait timer on every call.
+func WithSlack(slack time.Duration) Option {
+	return func(o *options) {
+		o.slack = slack
+	}
+}
+
+// WithMaxWaitJitter adds a random duration in [0, jitter) to the maximum wait
+// time, drawn at the start of each burst. This avoids many debounced functions
+// which were triggered at the same time from all being forced to invoke their
+// callbacks at the same time.
+//
+// As the jitter is only ever added, it never makes the maximum wait time expire
+// earlier than it otherwise would.
+func WithMaxWaitJitter(jitter time.Duration) Option {
+	return func(o *options) {
+		o.maxWaitJitter = jitter
+	}
+}
+
+// WithPredicate sets a function which is called on each call to the debounced
+// function, before anything else happens. When it returns false, the call is
+// ignored entirely and counted as suppressed in Stats, i.e., it neither invokes
+// the callback function nor starts or postpones a pending invocation.
+//
+// The predicate is called without holding any internal lock, but it is called
+// synchronously by the debounced function, so it should return quickly.
+func WithPredicate(predicate func() bool) Option {
+	return func(o *options) {
+		o.predicate = predicate
+	}
+}
+
+// WithInvokeCondition sets a function which is called right before each
+// invocation of the callback function. When it returns false, the invocation
+// is skipped, counted as such in Stats, and the pending burst is discarded as
+// if the callback function had been invoked.
+//
+// Unlike WithPredicate, which decides if a call schedules an invocation, the
+// condition decides if a scheduled invocation should still go ahead, allowing
+// work which has become unnecessary in the meantime to be skipped.
+func WithInvokeCondition(condition func() bool) Option {
+	return func(o *options) {
+		o.invokeCondition = condition
+	}
+}
+
+// WithOnSuppressed sets a hook which is called whenever a call is ignored due
+// to WithPredicate, or an invocation is skipped due to WithInvokeCondition.
+//
+// The hook is called synchronously without holding any internal lock.
+func WithOnSuppressed(hook func(reason SuppressReason)) Option {
+	return func(o *options) {
+		o.onSuppressed = hook
+	}
+}
+
+// WithQuota limits invocations of the callback function to at most n within
+// any rolling window of time. Once the quota is exhausted, an invocation which
+// is due is deferred until the quota allows it, while further calls are
+// coalesced into it, so at most one invocation is ever pending.
+//
+// The quota takes precedence over the maximum wait time.
+func WithQuota(n int, window time.Duration) Option {
+	return func(o *options) {
+		o.quota = n
+		o.quotaWindow = window
+	}
+}
+
+// WithRateLimiter makes invocations of the callback function subject to the
+// given rate limiter. Before each invocation a reservation is made with the
+// limiter, and if it requires a delay, the invocation is deferred until the
+// delay has elapsed, while further calls are coalesced into it.
+//
+// Like WithQuota, the rate limiter takes precedence over the maximum wait time.
+func WithRateLimiter(l Allower) Option {
+	return func(o *options) {
+		o.limiter = l
+	}
+}
+
+// WithWorker makes a Debouncer run all invocations of its callback function on
+// a single long-lived goroutine, rather than starting a new goroutine for each
+// invocation. The goroutine exits when the Debouncer is closed, once it has run
+// the invocations which were triggered before, like a Flush racing with Close.
+//
+// Invocations are run one at a time in the order they were triggered. The
+// callback function may safely call back into the Debouncer.
+//
+// WithWorker takes precedence over WithExecutor.
+func WithWorker() Option {
+	return func(o *options) {
+		o.worker = true
+	}
+}
+
+// WithAutoStop makes a Debouncer stop after invoking its callback function n
+// times. Once stopped, it behaves as if closed: timers are stopped, further
+// calls have no effect, and it never reports an invocation as pending.
+// Canceling pending invocations does not revive it.
+//
+// The optional onStop hook is called once the nth invocation has completed.
+// It may be nil.
+func WithAutoStop(n int, onStop func()) Option {
+	return func(o *options) {
+		o.autoStop = n
+		o.onAutoStop = onStop
+	}
+}
+
+// WithOnInvoke sets a hook which is called right before each invocation of the
+// callback function, with information about the calls which led to it.
+//
+// The hook is called synchronously on the goroutine running the invocation,
+// without holding any internal lock.
+func WithOnInvoke(hook func(info InvokeInfo)) Option {
+	return func(o *options) {
+		o.onInvoke = hook
+	}
+}
+
+// WithReschedule makes a Debouncer invoke f in place of the callback function
+// given to the constructor, passing it a Rescheduler, which f can use to
+// request another invocation of itself, for example when it could only process
+// part of the pending work. The callback function given to the constructor is
+// never invoked, and may be nil.
+//
+// As f takes no values, the option is meant for debounced functions whose
+// callback function takes none either, like those returned by New and
+// NewDebouncer.
+func WithReschedule(f func(r Rescheduler)) Option {
+	return func(o *options) {
+		o.reschedule = f
+	}
+}
+
+// WithCallSiteCapture records the call site of each call to the debounced
+// function, making them available through InvokeInfo and
+// Debouncer.LastBurstCallers. The most recent 32 call sites are kept per
+// invocation.
+//
+// The depth is the number of stack frames to ascend above the direct caller
+// of the debounced function, which is useful when it is called through helper
+// functions. A depth of 0 records the direct caller.
+//
+// Capturing call sites has a small cost per call, so it is disabled by default.
+func WithCallSiteCapture(depth int) Option {
+	return func(o *options) {
+		o.captureCallers = true
+		o.callerSkip = depth
+	}
+}
+
+// WithZeroWaitSync makes the debounced function invoke the callback function
+// synchronously when the wait time is zero or negative, as if it had been
+// called directly, so the call returns once the callback function has.
+//
+// By default each call invokes the callback function right away, but
+// asynchronously like any other invocation, honoring WithExecutor and
+// WithWorker.
+func WithZeroWaitSync() Option {
+	return func(o *options) {
+		o.zeroWaitSync = true
+	}
+}
+
+// WithTimerGranularity rounds the expiry times of the wait and maximum wait
+// timers up to the next multiple of granularity. Calls which fall within the
+// same granularity bucket then leave the timers untouched, which reduces the
+// overhead of many debouncers with long wait times.
+//
+// The callback function is then invoked up to granularity later than the wait
+// and maximum wait times dictate.
+func WithTimerGranularity(granularity time.Duration) Option {
+	return func(o *options) {
+		o.timerGranularity = granularity
+	}
+}
+
+// WithOnIdle sets a hook which is called once the debounced function has not
+// been called for the given duration, for example to release resources once
+// activity has stopped. It is called once per period of activity, and only
+// once no invocation of the callback function is pending anymore.
+//
+// The hook is called on its own goroutine, and never after the Debouncer has
+// been closed.
+func WithOnIdle(after time.Duration, hook func()) Option {
+	return func(o *options) {
+		o.idleAfter = after
+		o.onIdle = hook
+	}
+}
+
+// WithMaxBatchSize makes a debounced function returned by NewBatch invoke its
+// callback function right away once n values have been collected, rather than
+// waiting for the wait time to elapse. Values passed afterwards start a new
+// batch, with a wait time of its own. For NewMap, n is the maximum number of
+// keys, for NewSet the maximum number of distinct values, and for
+// NewKeyedBatch the maximum number of values of all keys.
+//
+// Batches can still grow beyond n when invocations are coalesced due to
+// WithSerializedExecution or WithDropIfRunning, or deferred due to WithQuota or
+// WithRateLimiter. The option has no effect on other debounced functions.
+func WithMaxBatchSize(n int) Option {
+	return func(o *options) {
+		o.maxBatchSize = n
+	}
+}
+
+// WithGroupMaxIdle makes a Group evict keys once they have not been called for
+// maxIdle, releasing their Debouncer. Keys with a pending or running
+// invocation are never evicted. A call for an evicted key starts afresh, as if
+// it was the first call for the key.
+//
+// The option has no effect on debounced functions other than a Group.
+func WithGroupMaxIdle(maxIdle time.Duration) Option {
+	return func(o *options) {
+		o.groupMaxIdle = maxIdle
+	}
+}
+
+// WithGroupMaxEntries makes a Group evict the least recently called keys once
+// it holds more than n keys, releasing their Debouncer. Keys with a pending or
+// running invocation are never evicted, so a Group can still hold more than n
+// keys while they are busy. A call for an evicted key starts afresh, as if it
+// was the first call for the key.
+//
+// Finding the least recently called keys requires looking at all keys, so the
+// maximum should be a safety net rather than be hit continuously, with
+// WithGroupMaxIdle taking care of evicting keys in normal operation.
+//
+// The option has no effect on debounced functions other than a Group.
+func WithGroupMaxEntries(n int) Option {
+	return func(o *options) {
+		o.groupMaxEntries = n
+	}
+}
+
+// WithGroupKeyOptions sets a function returning options for a key of a Group,
+// which are applied after the options given to NewGroup when the key's
+// Debouncer is created. This allows some keys to be configured differently,
+// for example with a longer wait time set with WithWait.
+//
+// The function is called once for each key, when the first call for the key is
+// made, and again once the key is called after being evicted. It may be called
+// concurrently for different keys, and should return quickly. K must be the
+// key type of the Group, otherwise NewGroup panics.
+//
+// The option has no effect on debounced functions other than a Group.
+func WithGroupKeyOptions[K comparable](f func(key K) []Option) Option {
+	return func(o *options) {
+		o.groupKeyOptions = f
+	}
+}
+
+// WithGroupGlobalLimit caps the invocations of a Group's callback function to
+// at most n within any rolling window of time across all keys, so a burst of
+// keys coming due at once does not overwhelm a shared downstream. An
+// invocation held back by the limit is deferred like with WithRateLimiter,
+// with further calls for its key coalesced into it.
+//
+// Keys are let through oldest pending first, in the order their invocations
+// came due. The maximum wait time of each key is best-effort, as the limit
+// takes precedence over it. The limit also takes precedence over any rate
+// limiter set with WithRateLimiter.
+//
+// The option has no effect on debounced functions other than a Group.
+func WithGroupGlobalLimit(n int, window time.Duration) Option {
+	return func(o *options) {
+		o.groupLimit = n
+		o.groupLimitWindow = window
+	}
+}
+
+// WithGroupOnLimit sets a hook which is called with the key and the delay of
+// each invocation deferred by the limit set with WithGroupGlobalLimit, for
+// example to report on keys held back beyond their maximum wait time. K must
+// be the key type of the Group, otherwise NewGroup panics.
+//
+// Like Allower.Reserve, the hook is called while holding the key's internal
+// lock, and must not block, or call the Group for the key.
+//
+// The option has no effect on debounced functions other than a Group.
+func WithGroupOnLimit[K comparable](
+	hook func(key K, delay time.Duration),
+) Option {
+	return func(o *options) {
+		o.groupOnLimit = hook
+	}
+}
+
+// WithOnError sets a hook which is called with each non-nil error returned by
+// the callback function of a debounced function returned by NewWithError.
+// Without it, such errors are discarded.
+//
+// The hook is called synchronously after the callback function returns, on
+// the goroutine the invocation ran on, so invocations are never held up by it
+// unless it blocks while WithSerializedExecution or WithWorker is used. It may
+// be called concurrently by overlapping invocations.
+func WithOnError(hook func(err error)) Option {
+	return func(o *options) {
+		o.onError = hook
+	}
+}
+
+// WithScheduler makes a Debouncer run its timers on the given Scheduler, which
+// can be shared by many Debouncers, rather than owning runtime timers of its
+// own. Used with NewGroup, all keys share the Scheduler.
+func WithScheduler(s *Scheduler) Option {
+	return func(o *options) {
+		o.scheduler = s
+	}
+}
+
+// DropPolicy decides which values are dropped once the limit set with
+// WithMaxPending is reached.
+type DropPolicy int
+
+const (
+	// DropOldest drops the oldest pending value to make room for a new one.
+	DropOldest DropPolicy = iota
+	// DropNewest drops new values, keeping the pending ones.
+	DropNewest
+)
+
+// WithMaxPending limits a debounced function collecting values into batches,
+// like one returned by NewBatch, to holding n pending values, dropping values
+// as decided by policy once the limit is reached. Dropped values are counted in
+// Stats, and passed to the hook set with WithOnDrop. A call whose value is
+// dropped with DropNewest does not postpone the pending invocation.
+//
+// The option has no effect on debounced functions other than NewBatch,
+// NewBatchWithMaxWait, NewBatchDebouncer, NewAccumulatingMutable, Pipe,
+// NewBatcher, NewAckBatcher and NewErrorCollector.
+func WithMaxPending(n int, policy DropPolicy) Option {
+	return func(o *options) {
+		o.maxPending = n
+		o.dropPolicy = policy
+	}
+}
+
+// WithOnDrop sets a hook which is called with each value dropped due to
+// WithMaxPending, for example to count or spill them. T must be the value type
+// of the debounced function, otherwise its constructor panics.
+//
+// The hook is called from the goroutine making the call which caused the drop,
+// before the call returns.
+func WithOnDrop[T any](hook func(dropped T)) Option {
+	return func(o *options) {
+		o.onDrop = hook
+	}
+}
+
+// WithConfirmWindow adds a confirmation window of c after the wait time, for
+// example before destructive actions. Once the wait time elapses, the callback
+// function is only invoked if no calls are made for another c. A call made
+// during the confirmation window cancels the pending invocation entirely,
+// discarding the calls made before it, and debouncing carries on from that
+// call, with the wait time followed by a new confirmation window.
+//
+// The maximum wait time, if any, is still measured from the first call of the
+// burst, including calls whose invocation was canceled, and invokes the
+// callback function regardless of the confirmation window.
+func WithConfirmWindow(c time.Duration) Option {
+	return func(o *options) {
+		o.confirmWindow = c
+	}
+}
+
+// WithActivationRate makes debouncing kick in only under load. While fewer
+// than n calls are made within window, each call invokes the callback function
+// right away. Once n calls have been made within window, calls are debounced
+// as usual, until the rate drops again.
+//
+// A call is only let through while no invocation is pending, so calls
+// debounced during a spike are invoked first, and never lost.
+func WithActivationRate(n int, window time.Duration) Option {
+	return func(o *options) {
+		o.activationRate = n
+		o.activationWindow = window
+	}
+}
+
+// WithRegistry adds the Debouncer to r, so it is flushed by r.FlushAll and
+// closed by r.Shutdown. The Debouncer is removed from r once it is closed,
+// evicted from its Group, or for a debounced function returned by a constructor
+// like New, once the returned cancel function is called. Passing
+// DefaultRegistry makes the Debouncer subject to the package level FlushAll and
+// Shutdown functions.
+//
+// A Debouncer created with a Registry which has been shut down is closed right
+// away.
+func WithRegistry(r *Registry) Option {
+	return func(o *options) {
+		o.registry = r
+	}
+}
+
+// WithCloseTimeout gives Close a grace period of d. Rather than discarding a
+// pending invocation, Close invokes it right away, and waits up to d for it and
+// any other running invocations to complete, returning ErrTimeout if they do
+// not. A grace period of 0 or less disables it, which is the default.
+func WithCloseTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.closeTimeout = d
+	}
+}
+
+// WithDiscardOnClose makes Chan and Pipe discard any pending value or batch
+// once their input channel is closed, rather than sending it before closing
+// the returned channel. It makes a Consumer complete outstanding messages with
+// ErrClosed once it stops, rather than processing them.
+//
+// The option has no effect on debounced functions other than Chan, Pipe and
+// NewConsumer.
+func WithDiscardOnClose() Option {
+	return func(o *options) {
+		o.discardOnClose = true
+	}
+}
+
+// WithSchedule restricts invocations of the callback function to the periods
+// of time allowed by s, like outside business hours with a WeeklySchedule. An
+// invocation which is due outside of them is deferred until the next allowed
+// time, while further calls are coalesced into it, so work accumulates and is
+// handled once allowed. Cancel discards the deferred invocation as usual.
+//
+// Like WithQuota, the schedule takes precedence over the maximum wait time. It
+// is checked before WithQuota and WithRateLimiter, so the quota is not used up
+// by invocations deferred by the schedule.
+func WithSchedule(s Schedule) Option {
+	return func(o *options) {
+		o.schedule = s
+	}
+}
+
+// WithIgnoreExpiredDeadlines makes the context passed to the callback function
+// ignore the deadlines of calls made with DebounceCtx whose context is already
+// done when the invocation starts, rather than carrying a deadline which has
+// already passed.
+func WithIgnoreExpiredDeadlines() Option {
+	return func(o *options) {
+		o.ignoreExpired = true
+	}
+}
+
+// WithDropCanceledCalls drops calls made with DebounceCtx whose context is done
+// when the invocation covering them starts, so they no longer count towards
+// InvokeInfo.Calls, and their deadlines no longer apply. If only such calls
+// are left, the invocation is skipped, and the hook set with WithOnSuppressed
+// is called with SuppressCanceled.
+func WithDropCanceledCalls() Option {
+	return func(o *options) {
+		o.dropCanceled = true
+	}
+}
+
+// WithRandSource sets the source of randomness used by options which draw
+// random values, like WithWaitRange and WithMaxWaitJitter. By default a
+// package-level source seeded at startup is used.
+//
+// The source is only ever used while holding the debounced function's internal
+// lock, so it does not need to be safe for concurrent use, unless it is shared
+// between multiple debounced functions.
+func WithRandSource(src rand.Source) Option {
+	return func(o *options) {
+		o.randSource = src
+	}
+}
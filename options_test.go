@@ -0,0 +1,1534 @@
+package debounce
+
+import (
+	"context"
+	"math/rand"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithWaitRange(t *testing.T) {
+	t.Parallel()
+
+	t.Run("draws wait per burst", func(t *testing.T) {
+		t.Parallel()
+
+		low := 10 * time.Millisecond
+		high := 50 * time.Millisecond
+		want := rand.New(rand.NewSource(42))
+
+		d := NewDebouncer(time.Hour, func() {},
+			WithWaitRange(low, high),
+			WithRandSource(rand.NewSource(42)),
+		)
+
+		for i := 0; i < 5; i++ {
+			wantWait := low + time.Duration(want.Int63n(int64(high-low)+1))
+
+			d.Debounce()
+			d.mux.Lock()
+			assert.Equal(t, wantWait, d.burstWait, "burst %d", i)
+			d.mux.Unlock()
+
+			// Further calls within the burst keep the same wait.
+			d.Debounce()
+			d.mux.Lock()
+			assert.Equal(t, wantWait, d.burstWait, "burst %d", i)
+			d.mux.Unlock()
+
+			d.Cancel()
+		}
+	})
+
+	t.Run("overrides fixed wait", func(t *testing.T) {
+		t.Parallel()
+
+		mux := sync.RWMutex{}
+		n := 0
+		d, _ := New(time.Hour, func() {
+			mux.Lock()
+			defer mux.Unlock()
+			n++
+		}, WithWaitRange(20*time.Millisecond, 20*time.Millisecond))
+
+		d()
+		time.Sleep(10 * time.Millisecond)
+		mux.RLock()
+		assert.Equal(t, 0, n)
+		mux.RUnlock()
+
+		time.Sleep(30 * time.Millisecond)
+		mux.RLock()
+		assert.Equal(t, 1, n)
+		mux.RUnlock()
+	})
+
+	t.Run("stays within range", func(t *testing.T) {
+		t.Parallel()
+
+		low := 5 * time.Millisecond
+		high := 7 * time.Millisecond
+		d := NewDebouncer(time.Hour, func() {},
+			WithWaitRange(low, high),
+		)
+
+		for i := 0; i < 100; i++ {
+			d.Debounce()
+			d.mux.Lock()
+			assert.GreaterOrEqual(t, d.burstWait, low)
+			assert.LessOrEqual(t, d.burstWait, high)
+			d.mux.Unlock()
+			d.Cancel()
+		}
+	})
+
+	t.Run("invalid range", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Panics(t, func() {
+			NewDebouncer(time.Second, func() {},
+				WithWaitRange(20*time.Millisecond, 10*time.Millisecond))
+		})
+		assert.Panics(t, func() {
+			NewDebouncer(time.Second, func() {},
+				WithWaitRange(-1, 10*time.Millisecond))
+		})
+	})
+}
+
+func TestWithBackoff(t *testing.T) {
+	t.Parallel()
+
+	t.Run("grows wait per invocation", func(t *testing.T) {
+		t.Parallel()
+
+		d := NewDebouncer(10*time.Millisecond, func() {},
+			WithBackoff(2, 50*time.Millisecond, time.Hour),
+		)
+
+		want := []time.Duration{
+			10 * time.Millisecond,
+			20 * time.Millisecond,
+			40 * time.Millisecond,
+			50 * time.Millisecond,
+			50 * time.Millisecond,
+		}
+		for i, wantWait := range want {
+			d.Debounce()
+			d.mux.Lock()
+			assert.Equal(t, wantWait, d.burstWait, "burst %d", i)
+			d.mux.Unlock()
+			d.fire(InvokeWait, d.timer)
+		}
+	})
+
+	t.Run("stops growing at max", func(t *testing.T) {
+		t.Parallel()
+
+		d := NewDebouncer(time.Second, func() {},
+			WithBackoff(2, 5*time.Second, time.Hour),
+		)
+
+		for i := 0; i < 10; i++ {
+			d.Debounce()
+			d.fire(InvokeWait, d.timer)
+		}
+
+		d.mux.Lock()
+		defer d.mux.Unlock()
+		assert.Equal(t, 5*time.Second, d.burstWait)
+		assert.Equal(t, float64(8), d.backoffScale)
+	})
+
+	t.Run("resets after idle", func(t *testing.T) {
+		t.Parallel()
+
+		d := NewDebouncer(10*time.Millisecond, func() {},
+			WithBackoff(2, time.Second, time.Minute),
+		)
+
+		d.Debounce()
+		d.fire(InvokeWait, d.timer)
+		d.Debounce()
+		d.fire(InvokeWait, d.timer)
+
+		// Pretend the last call happened longer than resetAfter ago.
+		d.mux.Lock()
+		d.lastCall = d.lastCall.Add(-2 * time.Minute)
+		d.mux.Unlock()
+
+		d.Debounce()
+		d.mux.Lock()
+		assert.Equal(t, 10*time.Millisecond, d.burstWait)
+		d.mux.Unlock()
+		d.Cancel()
+	})
+
+	t.Run("deadlines", func(t *testing.T) {
+		t.Parallel()
+
+		mux := sync.RWMutex{}
+		n := 0
+		d, _ := New(20*time.Millisecond, func() {
+			mux.Lock()
+			defer mux.Unlock()
+			n++
+		}, WithBackoff(2, time.Second, time.Second))
+
+		count := func() int {
+			mux.RLock()
+			defer mux.RUnlock()
+
+			return n
+		}
+
+		d()                               // 0ms, fires at 20ms
+		time.Sleep(30 * time.Millisecond) // 30ms
+		assert.Equal(t, 1, count())
+
+		d()                               // 30ms, fires at 70ms
+		time.Sleep(30 * time.Millisecond) // 60ms
+		assert.Equal(t, 1, count())
+		time.Sleep(20 * time.Millisecond) // 80ms
+		assert.Equal(t, 2, count())
+	})
+
+	t.Run("maxWait still caps delay", func(t *testing.T) {
+		t.Parallel()
+
+		fired := make(chan struct{}, 2)
+		d := NewDebouncer(10*time.Millisecond, func() {
+			fired <- struct{}{}
+		}, WithBackoff(10, time.Hour, time.Hour))
+		d.withMaxWait(30 * time.Millisecond)
+
+		d.Debounce()
+		d.fire(InvokeWait, d.timer)
+		<-fired
+
+		d.Debounce() // burst wait is 100ms, maxWait 30ms
+
+		select {
+		case <-fired:
+		case <-time.After(80 * time.Millisecond):
+			t.Fatal("maxWait did not fire")
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		for _, opt := range []Option{
+			WithBackoff(0.5, time.Second, time.Second),
+			WithBackoff(1, time.Second, time.Second),
+			WithBackoff(2, 0, time.Second),
+			WithBackoff(2, -1, time.Second),
+			WithBackoff(2, time.Second, -1),
+			WithBackoff(2, time.Second, 0),
+		} {
+			assert.Panics(t, func() {
+				NewDebouncer(time.Second, func() {}, opt)
+			})
+		}
+	})
+}
+
+func TestWithBurstPassThrough(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		n            int
+		calls        []testOp
+		wantTriggers map[time.Duration]int
+	}{
+		{
+			name: "burst shorter than n",
+			n:    3,
+			calls: []testOp{
+				{delay: 0 * time.Millisecond},
+				{delay: 5 * time.Millisecond},
+			},
+			wantTriggers: map[time.Duration]int{
+				// both calls invoke immediately
+				10 * time.Millisecond: 2,
+				// no trailing invocation
+				60 * time.Millisecond: 2,
+			},
+		},
+		{
+			name: "burst longer than n",
+			n:    2,
+			calls: []testOp{
+				{delay: 0 * time.Millisecond},
+				{delay: 2 * time.Millisecond},
+				{delay: 4 * time.Millisecond},
+				{delay: 6 * time.Millisecond},
+				{delay: 8 * time.Millisecond},
+			},
+			wantTriggers: map[time.Duration]int{
+				// first two calls invoke immediately
+				15 * time.Millisecond: 2,
+				// trailing invocation at 28ms (8ms + 20ms)
+				40 * time.Millisecond: 3,
+				80 * time.Millisecond: 3,
+			},
+		},
+		{
+			name: "budget replenished after idle",
+			n:    1,
+			calls: []testOp{
+				{delay: 0 * time.Millisecond},
+				{delay: 5 * time.Millisecond},
+				// trailing invocation at 25ms (5ms + 20ms)
+				{delay: 60 * time.Millisecond},
+			},
+			wantTriggers: map[time.Duration]int{
+				3 * time.Millisecond:  1,
+				20 * time.Millisecond: 1,
+				35 * time.Millisecond: 2,
+				// budget is replenished, call invokes immediately
+				70 * time.Millisecond:  3,
+				120 * time.Millisecond: 3,
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mux := sync.RWMutex{}
+			n := 0
+			d, c := New(20*time.Millisecond, func() {
+				mux.Lock()
+				defer mux.Unlock()
+				n++
+			}, WithBurstPassThrough(tt.n))
+
+			wg := sync.WaitGroup{}
+			for _, op := range tt.calls {
+				wg.Add(1)
+				go func(delay time.Duration, cancel bool) {
+					defer wg.Done()
+					time.Sleep(delay)
+					if cancel {
+						c()
+					} else {
+						d()
+					}
+				}(op.delay, op.cancel)
+			}
+
+			for delay, count := range tt.wantTriggers {
+				wg.Add(1)
+				go func(interval time.Duration, count int) {
+					defer wg.Done()
+					time.Sleep(interval)
+
+					mux.RLock()
+					defer mux.RUnlock()
+					assert.Equal(t, count, n, "at %s", interval)
+				}(delay, count)
+			}
+
+			wg.Wait()
+		})
+	}
+}
+
+func TestWithSerializedExecution(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no overlap and coalesced queue", func(t *testing.T) {
+		t.Parallel()
+
+		mux := sync.Mutex{}
+		running := 0
+		overlaps := 0
+		n := 0
+		done := make(chan struct{}, 10)
+
+		d, _ := New(time.Hour, func() {
+			mux.Lock()
+			running++
+			if running > 1 {
+				overlaps++
+			}
+			n++
+			mux.Unlock()
+
+			time.Sleep(20 * time.Millisecond)
+
+			mux.Lock()
+			running--
+			mux.Unlock()
+			done <- struct{}{}
+		}, WithBurstPassThrough(10), WithSerializedExecution())
+
+		// Each call invokes immediately, but only the first runs right away,
+		// and the rest are coalesced into a single queued invocation.
+		for i := 0; i < 5; i++ {
+			d()
+		}
+
+		<-done
+		<-done
+		time.Sleep(30 * time.Millisecond)
+
+		mux.Lock()
+		defer mux.Unlock()
+		assert.Equal(t, 0, overlaps)
+		assert.Equal(t, 2, n)
+	})
+
+	t.Run("sequential order", func(t *testing.T) {
+		t.Parallel()
+
+		mux := sync.Mutex{}
+		var starts, ends []time.Time
+
+		d, _ := New(5*time.Millisecond, func() {
+			mux.Lock()
+			starts = append(starts, time.Now())
+			mux.Unlock()
+
+			time.Sleep(30 * time.Millisecond)
+
+			mux.Lock()
+			ends = append(ends, time.Now())
+			mux.Unlock()
+		}, WithSerializedExecution())
+
+		// Trigger three trailing invocations while the first is still
+		// running.
+		for i := 0; i < 3; i++ {
+			d()
+			time.Sleep(10 * time.Millisecond)
+		}
+		time.Sleep(100 * time.Millisecond)
+
+		mux.Lock()
+		defer mux.Unlock()
+		assert.Len(t, starts, 2)
+		assert.Len(t, ends, 2)
+		for i := 1; i < len(starts); i++ {
+			assert.False(t, starts[i].Before(ends[i-1]),
+				"invocation %d started before %d ended", i, i-1)
+		}
+	})
+}
+
+func TestWithDropIfRunning(t *testing.T) {
+	t.Parallel()
+
+	mux := sync.Mutex{}
+	var starts, ends []time.Duration
+	start := time.Now()
+
+	d, _ := New(10*time.Millisecond, func() {
+		mux.Lock()
+		starts = append(starts, time.Since(start))
+		mux.Unlock()
+
+		time.Sleep(60 * time.Millisecond)
+
+		mux.Lock()
+		ends = append(ends, time.Since(start))
+		mux.Unlock()
+	}, WithDropIfRunning())
+
+	d() // 0ms, invokes at 10ms, which runs until 70ms
+	time.Sleep(20 * time.Millisecond)
+
+	// A stream of calls while the callback is running, which would invoke
+	// at 50ms, but is dropped and rearmed when the callback completes.
+	for i := 0; i < 3; i++ {
+		d()
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(150 * time.Millisecond)
+
+	mux.Lock()
+	defer mux.Unlock()
+	if assert.Len(t, starts, 2) && assert.Len(t, ends, 2) {
+		assert.GreaterOrEqual(t, starts[1], ends[0]+10*time.Millisecond)
+	}
+}
+
+func TestWithDeadline(t *testing.T) {
+	t.Parallel()
+
+	newCounter := func() (func(), func() int) {
+		mux := sync.RWMutex{}
+		n := 0
+
+		return func() {
+				mux.Lock()
+				defer mux.Unlock()
+				n++
+			}, func() int {
+				mux.RLock()
+				defer mux.RUnlock()
+
+				return n
+			}
+	}
+
+	t.Run("pending at deadline", func(t *testing.T) {
+		t.Parallel()
+
+		f, count := newCounter()
+		d, _ := NewWithMaxWait(time.Hour, time.Hour, f,
+			WithDeadline(time.Now().Add(20*time.Millisecond)),
+		)
+
+		d()
+		time.Sleep(10 * time.Millisecond)
+		assert.Equal(t, 0, count())
+		time.Sleep(20 * time.Millisecond)
+		assert.Equal(t, 1, count())
+
+		// The deadline is inert once it has fired.
+		d()
+		time.Sleep(30 * time.Millisecond)
+		assert.Equal(t, 1, count())
+	})
+
+	t.Run("idle at deadline", func(t *testing.T) {
+		t.Parallel()
+
+		f, count := newCounter()
+		d, _ := New(time.Hour, f,
+			WithDeadline(time.Now().Add(10*time.Millisecond)),
+		)
+
+		time.Sleep(20 * time.Millisecond)
+		d()
+		time.Sleep(20 * time.Millisecond)
+		assert.Equal(t, 0, count())
+	})
+
+	t.Run("cancel does not clear deadline", func(t *testing.T) {
+		t.Parallel()
+
+		f, count := newCounter()
+		d, c := New(time.Hour, f,
+			WithDeadline(time.Now().Add(20*time.Millisecond)),
+		)
+
+		d()
+		c()
+		d()
+		time.Sleep(30 * time.Millisecond)
+		assert.Equal(t, 1, count())
+	})
+
+	t.Run("SetDeadline rearms", func(t *testing.T) {
+		t.Parallel()
+
+		f, count := newCounter()
+		d := NewDebouncer(time.Hour, f,
+			WithDeadline(time.Now().Add(10*time.Millisecond)),
+		)
+
+		d.Debounce()
+		time.Sleep(20 * time.Millisecond)
+		assert.Equal(t, 1, count())
+
+		d.SetDeadline(time.Now().Add(10 * time.Millisecond))
+		d.Debounce()
+		time.Sleep(20 * time.Millisecond)
+		assert.Equal(t, 2, count())
+
+		d.SetDeadline(time.Now().Add(10 * time.Millisecond))
+		d.SetDeadline(time.Time{})
+		d.Debounce()
+		time.Sleep(20 * time.Millisecond)
+		assert.Equal(t, 2, count())
+	})
+}
+
+func TestWithFirstWait(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		calls        []testOp
+		wantTriggers map[time.Duration]int
+	}{
+		{
+			name: "single call burst",
+			calls: []testOp{
+				{delay: 0 * time.Millisecond},
+			},
+			wantTriggers: map[time.Duration]int{
+				// would have triggered at 10ms with the regular wait
+				25 * time.Millisecond: 0,
+				// first wait expires at 40ms
+				50 * time.Millisecond:  1,
+				100 * time.Millisecond: 1,
+			},
+		},
+		{
+			name: "multi call burst",
+			calls: []testOp{
+				{delay: 0 * time.Millisecond},
+				{delay: 5 * time.Millisecond},
+				{delay: 10 * time.Millisecond},
+				{delay: 40 * time.Millisecond},
+			},
+			wantTriggers: map[time.Duration]int{
+				5 * time.Millisecond: 0,
+				// regular wait expires at 20ms (10ms + 10ms)
+				30 * time.Millisecond: 1,
+				// next burst uses first wait again, expiring at 80ms
+				70 * time.Millisecond:  1,
+				90 * time.Millisecond:  2,
+				150 * time.Millisecond: 2,
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mux := sync.RWMutex{}
+			n := 0
+			d, _ := New(10*time.Millisecond, func() {
+				mux.Lock()
+				defer mux.Unlock()
+				n++
+			}, WithFirstWait(40*time.Millisecond))
+
+			wg := sync.WaitGroup{}
+			for _, op := range tt.calls {
+				wg.Add(1)
+				go func(delay time.Duration) {
+					defer wg.Done()
+					time.Sleep(delay)
+					d()
+				}(op.delay)
+			}
+
+			for delay, count := range tt.wantTriggers {
+				wg.Add(1)
+				go func(interval time.Duration, count int) {
+					defer wg.Done()
+					time.Sleep(interval)
+
+					mux.RLock()
+					defer mux.RUnlock()
+					assert.Equal(t, count, n, "at %s", interval)
+				}(delay, count)
+			}
+
+			wg.Wait()
+		})
+	}
+}
+
+func TestWithConfirmWindow(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		calls    []testOp
+		wantRuns map[time.Duration][]int
+	}{
+		{
+			name: "clean confirmation window",
+			calls: []testOp{
+				{delay: 0 * time.Millisecond},
+				{delay: 5 * time.Millisecond},
+			},
+			wantRuns: map[time.Duration][]int{
+				// wait expires at 25ms
+				30 * time.Millisecond: nil,
+				// confirmation window ends at 45ms
+				55 * time.Millisecond:  {2},
+				100 * time.Millisecond: {2},
+			},
+		},
+		{
+			name: "call during confirmation window",
+			calls: []testOp{
+				{delay: 0 * time.Millisecond},
+				{delay: 5 * time.Millisecond},
+				// during the confirmation window from 25ms to 45ms
+				{delay: 35 * time.Millisecond},
+			},
+			wantRuns: map[time.Duration][]int{
+				45 * time.Millisecond: nil,
+				// the call at 35ms starts over, with its wait expiring at
+				// 55ms, and its confirmation window ending at 75ms
+				70 * time.Millisecond:  nil,
+				85 * time.Millisecond:  {1},
+				150 * time.Millisecond: {1},
+			},
+		},
+		{
+			name: "maxWait overrides confirmation window",
+			opts: []Option{WithMaxWait(60 * time.Millisecond)},
+			calls: []testOp{
+				{delay: 0 * time.Millisecond},
+				{delay: 35 * time.Millisecond},
+			},
+			wantRuns: map[time.Duration][]int{
+				55 * time.Millisecond: nil,
+				// maxWait from the call at 0ms expires at 60ms, during the
+				// confirmation window from 55ms to 75ms
+				68 * time.Millisecond:  {1},
+				150 * time.Millisecond: {1},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mux := sync.RWMutex{}
+			var runs []int
+			opts := append([]Option{WithConfirmWindow(20 * time.Millisecond)},
+				tt.opts...)
+			d, _ := NewCounted(20*time.Millisecond, func(n int) {
+				mux.Lock()
+				defer mux.Unlock()
+				runs = append(runs, n)
+			}, opts...)
+
+			wg := sync.WaitGroup{}
+			for _, op := range tt.calls {
+				wg.Add(1)
+				go func(delay time.Duration) {
+					defer wg.Done()
+					time.Sleep(delay)
+					d()
+				}(op.delay)
+			}
+
+			for delay, want := range tt.wantRuns {
+				wg.Add(1)
+				go func(interval time.Duration, want []int) {
+					defer wg.Done()
+					time.Sleep(interval)
+
+					mux.RLock()
+					defer mux.RUnlock()
+					assert.Equal(t, want, runs, "at %s", interval)
+				}(delay, want)
+			}
+
+			wg.Wait()
+		})
+	}
+}
+
+func TestWithActivationRate(t *testing.T) {
+	t.Parallel()
+
+	mux := sync.RWMutex{}
+	n := 0
+	d, _ := New(20*time.Millisecond, func() {
+		mux.Lock()
+		defer mux.Unlock()
+		n++
+	}, WithActivationRate(3, 30*time.Millisecond))
+
+	calls := []time.Duration{
+		// trickle, each invoked right away
+		0, 20 * time.Millisecond, 40 * time.Millisecond,
+		// burst, the first let through, the rest debounced
+		60 * time.Millisecond, 62 * time.Millisecond, 64 * time.Millisecond,
+		66 * time.Millisecond, 68 * time.Millisecond,
+		// trickle again
+		150 * time.Millisecond, 180 * time.Millisecond, 210 * time.Millisecond,
+	}
+	wantTriggers := map[time.Duration]int{
+		5 * time.Millisecond:  1,
+		25 * time.Millisecond: 2,
+		45 * time.Millisecond: 3,
+		// from the call at 60ms
+		80 * time.Millisecond: 4,
+		// from the call at 68ms (+20ms wait = 88ms)
+		95 * time.Millisecond:  5,
+		155 * time.Millisecond: 6,
+		185 * time.Millisecond: 7,
+		215 * time.Millisecond: 8,
+		250 * time.Millisecond: 8,
+	}
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		start := time.Now()
+		for _, delay := range calls {
+			time.Sleep(time.Until(start.Add(delay)))
+			d()
+		}
+	}()
+
+	for delay, count := range wantTriggers {
+		wg.Add(1)
+		go func(interval time.Duration, count int) {
+			defer wg.Done()
+			time.Sleep(interval)
+
+			mux.RLock()
+			defer mux.RUnlock()
+			assert.Equal(t, count, n, "at %s", interval)
+		}(delay, count)
+	}
+
+	wg.Wait()
+}
+
+func TestWithSlack(t *testing.T) {
+	t.Parallel()
+
+	t.Run("skips timer resets within slack", func(t *testing.T) {
+		t.Parallel()
+
+		clock := &fakeClock{t: time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)}
+		d := NewDebouncer(time.Hour, func() {}, WithSlack(time.Minute))
+		d.now = clock.Now
+
+		d.Debounce()
+		want := clock.Now().Add(time.Hour)
+		assert.Equal(t, want, d.timerExpiry)
+
+		clock.Add(30 * time.Second)
+		d.Debounce()
+		assert.Equal(t, want, d.timerExpiry)
+
+		clock.Add(30 * time.Second)
+		d.Debounce()
+		assert.Equal(t, clock.Now().Add(time.Hour), d.timerExpiry)
+
+		d.Cancel()
+	})
+
+	t.Run("invokes within slack of ideal time", func(t *testing.T) {
+		t.Parallel()
+
+		wait := 30 * time.Millisecond
+		slack := 10 * time.Millisecond
+		fired := make(chan time.Time, 1)
+		d := NewDebouncer(wait, func() {
+			fired <- time.Now()
+		}, WithSlack(slack))
+
+		var last time.Time
+		for i := 0; i < 10; i++ {
+			last = time.Now()
+			d.Debounce()
+			time.Sleep(2 * time.Millisecond)
+		}
+
+		at := <-fired
+		ideal := last.Add(wait)
+		assert.False(t, at.Before(ideal.Add(-slack)), "invoked too early")
+		assert.False(t, at.After(ideal.Add(20*time.Millisecond)),
+			"invoked too late")
+	})
+}
+
+func BenchmarkWithSlack(b *testing.B) {
+	for _, slack := range []time.Duration{0, time.Millisecond} {
+		b.Run(slack.String(), func(b *testing.B) {
+			d := NewDebouncer(time.Second, func() {}, WithSlack(slack))
+			defer d.Close()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				d.Debounce()
+			}
+		})
+	}
+}
+
+func TestWithMaxWaitJitter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("draws jitter per burst", func(t *testing.T) {
+		t.Parallel()
+
+		maxWait := time.Hour
+		jitter := time.Minute
+		want := rand.New(rand.NewSource(7))
+
+		d := NewDebouncer(time.Hour, func() {},
+			WithMaxWaitJitter(jitter),
+			WithRandSource(rand.NewSource(7)),
+		)
+		d.withMaxWait(maxWait)
+
+		for i := 0; i < 5; i++ {
+			wantMaxWait := maxWait + time.Duration(want.Int63n(int64(jitter)))
+
+			d.Debounce()
+			d.Debounce()
+			d.mux.Lock()
+			assert.Equal(t, wantMaxWait, d.burstMaxWait, "burst %d", i)
+			assert.GreaterOrEqual(t, d.burstMaxWait, maxWait)
+			assert.Less(t, d.burstMaxWait, maxWait+jitter)
+			d.mux.Unlock()
+
+			d.Cancel()
+		}
+	})
+
+	t.Run("forces invocation after jittered maxWait", func(t *testing.T) {
+		t.Parallel()
+
+		fired := make(chan time.Time, 1)
+		d, _ := NewWithMaxWait(time.Hour, 20*time.Millisecond, func() {
+			fired <- time.Now()
+		}, WithMaxWaitJitter(20*time.Millisecond))
+
+		start := time.Now()
+		d()
+
+		select {
+		case at := <-fired:
+			assert.GreaterOrEqual(t, at.Sub(start), 20*time.Millisecond)
+		case <-time.After(time.Second):
+			t.Fatal("maxWait did not fire")
+		}
+	})
+}
+
+func TestWithPredicate(t *testing.T) {
+	t.Parallel()
+
+	var allow int32 = 1
+	fired := make(chan time.Time, 2)
+	d := NewDebouncer(20*time.Millisecond, func() {
+		fired <- time.Now()
+	}, WithPredicate(func() bool {
+		return atomic.LoadInt32(&allow) == 1
+	}))
+
+	start := time.Now()
+	d.Debounce() // 0ms, invokes at 20ms
+	time.Sleep(5 * time.Millisecond)
+
+	// Ignored calls do not postpone the pending invocation.
+	atomic.StoreInt32(&allow, 0)
+	d.Debounce() // 5ms
+	time.Sleep(5 * time.Millisecond)
+	d.Debounce() // 10ms
+
+	at := <-fired
+	assert.Less(t, at.Sub(start), 28*time.Millisecond)
+
+	// Ignored calls do not start a new burst either.
+	d.Debounce()
+	time.Sleep(40 * time.Millisecond)
+	assert.Len(t, fired, 0)
+
+	atomic.StoreInt32(&allow, 1)
+	d.Debounce()
+	<-fired
+
+	assert.Equal(t, Stats{Calls: 5, Suppressed: 3, Invocations: 2}, d.Stats())
+}
+
+func TestWithInvokeCondition(t *testing.T) {
+	t.Parallel()
+
+	var allow int32 = 1
+	calls := make(chan struct{}, 2)
+	reasons := make(chan SuppressReason, 2)
+	d := NewDebouncer(10*time.Millisecond, func() {
+		calls <- struct{}{}
+	},
+		WithInvokeCondition(func() bool {
+			return atomic.LoadInt32(&allow) == 1
+		}),
+		WithOnSuppressed(func(reason SuppressReason) {
+			reasons <- reason
+		}),
+	)
+
+	// The condition turns false after the invocation was scheduled.
+	d.Debounce()
+	atomic.StoreInt32(&allow, 0)
+
+	select {
+	case reason := <-reasons:
+		assert.Equal(t, SuppressInvokeCondition, reason)
+	case <-time.After(time.Second):
+		t.Fatal("invocation was not suppressed")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Len(t, calls, 0)
+
+	// The debouncer keeps working once the condition is true again.
+	atomic.StoreInt32(&allow, 1)
+	d.Debounce()
+
+	select {
+	case <-calls:
+	case <-time.After(time.Second):
+		t.Fatal("callback was not invoked")
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	assert.Equal(t, Stats{Calls: 2, Invocations: 1, Skipped: 1}, d.Stats())
+}
+
+func TestWithOnSuppressed(t *testing.T) {
+	t.Parallel()
+
+	reasons := make(chan SuppressReason, 1)
+	d := NewDebouncer(time.Hour, func() {},
+		WithPredicate(func() bool { return false }),
+		WithOnSuppressed(func(reason SuppressReason) {
+			reasons <- reason
+		}),
+	)
+
+	d.Debounce()
+	assert.Equal(t, SuppressPredicate, <-reasons)
+	assert.Equal(t, "predicate", SuppressPredicate.String())
+	assert.Equal(t, "invoke condition", SuppressInvokeCondition.String())
+	assert.Equal(t, "canceled", SuppressCanceled.String())
+}
+
+func TestWithQuota(t *testing.T) {
+	t.Parallel()
+
+	mux := sync.RWMutex{}
+	n := 0
+	d, _ := NewWithMaxWait(5*time.Millisecond, 10*time.Millisecond, func() {
+		mux.Lock()
+		defer mux.Unlock()
+		n++
+	}, WithQuota(3, 100*time.Millisecond))
+
+	count := func() int {
+		mux.RLock()
+		defer mux.RUnlock()
+
+		return n
+	}
+
+	// Calls every 15ms would naturally invoke 6 times in 90ms, 5ms after each
+	// call, but the quota only allows 3 invocations per 100ms.
+	for i := 0; i < 6; i++ {
+		d()
+		time.Sleep(15 * time.Millisecond)
+	}
+	assert.Equal(t, 3, count()) // 90ms
+
+	// The deferred invocation catches up once the first invocation at ~5ms
+	// leaves the window.
+	time.Sleep(40 * time.Millisecond) // 130ms
+	assert.Equal(t, 4, count())
+
+	time.Sleep(50 * time.Millisecond) // 180ms
+	assert.Equal(t, 4, count())
+}
+
+func TestWithAutoStop(t *testing.T) {
+	t.Parallel()
+
+	mux := sync.RWMutex{}
+	n := 0
+	stopped := make(chan struct{})
+	d := NewDebouncer(5*time.Millisecond, func() {
+		mux.Lock()
+		defer mux.Unlock()
+		n++
+	}, WithAutoStop(2, func() { close(stopped) }))
+
+	// A long stream of calls which would otherwise invoke many times.
+	for i := 0; i < 10; i++ {
+		d.Debounce()
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("stop hook was not called")
+	}
+
+	d.Cancel()
+	d.Debounce()
+	assert.False(t, d.Pending())
+	time.Sleep(20 * time.Millisecond)
+
+	mux.RLock()
+	defer mux.RUnlock()
+	assert.Equal(t, 2, n)
+}
+
+func callerFuncs(pcs []uintptr) []string {
+	var funcs []string
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		funcs = append(funcs, frame.Function)
+		if !more {
+			return funcs
+		}
+	}
+}
+
+func debounceFromHelper(d *Debouncer) {
+	d.Debounce()
+}
+
+func TestWithCallSiteCapture(t *testing.T) {
+	t.Parallel()
+
+	t.Run("records callers", func(t *testing.T) {
+		t.Parallel()
+
+		infos := make(chan InvokeInfo, 1)
+		d := NewDebouncer(10*time.Millisecond, func() {},
+			WithCallSiteCapture(0),
+			WithOnInvoke(func(info InvokeInfo) { infos <- info }),
+		)
+
+		d.Debounce()
+		debounceFromHelper(d)
+
+		info := <-infos
+		assert.Equal(t, 2, info.Calls)
+		assert.Equal(t, []string{
+			"github.com/romdo/go-debounce.TestWithCallSiteCapture.func1",
+			"github.com/romdo/go-debounce.debounceFromHelper",
+		}, callerFuncs(info.Callers))
+
+		time.Sleep(5 * time.Millisecond)
+		assert.Equal(t, info.Callers, d.LastBurstCallers())
+	})
+
+	t.Run("depth", func(t *testing.T) {
+		t.Parallel()
+
+		infos := make(chan InvokeInfo, 1)
+		d := NewDebouncer(10*time.Millisecond, func() {},
+			WithCallSiteCapture(1),
+			WithOnInvoke(func(info InvokeInfo) { infos <- info }),
+		)
+
+		debounceFromHelper(d)
+
+		assert.Equal(t, []string{
+			"github.com/romdo/go-debounce.TestWithCallSiteCapture.func2",
+		}, callerFuncs((<-infos).Callers))
+	})
+
+	t.Run("bounded", func(t *testing.T) {
+		t.Parallel()
+
+		infos := make(chan InvokeInfo, 1)
+		debounced, _ := New(10*time.Millisecond, func() {},
+			WithCallSiteCapture(0),
+			WithOnInvoke(func(info InvokeInfo) { infos <- info }),
+		)
+
+		for i := 0; i < 50; i++ {
+			debounced()
+		}
+
+		info := <-infos
+		assert.Equal(t, 50, info.Calls)
+		assert.Len(t, info.Callers, 32)
+		assert.Equal(t,
+			"github.com/romdo/go-debounce.TestWithCallSiteCapture.func3",
+			callerFuncs(info.Callers[:1])[0],
+		)
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		t.Parallel()
+
+		infos := make(chan InvokeInfo, 1)
+		d := NewDebouncer(5*time.Millisecond, func() {},
+			WithOnInvoke(func(info InvokeInfo) { infos <- info }),
+		)
+
+		d.Debounce()
+
+		info := <-infos
+		assert.Equal(t, 1, info.Calls)
+		assert.Nil(t, info.Callers)
+	})
+}
+
+func TestWithZeroWaitSync(t *testing.T) {
+	t.Parallel()
+
+	t.Run("asynchronous by default", func(t *testing.T) {
+		t.Parallel()
+
+		release := make(chan struct{})
+		calls := make(chan int, 3)
+		n := 0
+		debounced, _ := New(0, func() {
+			<-release
+			n++
+			calls <- n
+		}, WithWorker())
+
+		// Calls return without waiting for the callback.
+		for i := 0; i < 3; i++ {
+			debounced()
+		}
+		close(release)
+
+		for i := 1; i <= 3; i++ {
+			assert.Equal(t, i, <-calls)
+		}
+	})
+
+	t.Run("synchronous", func(t *testing.T) {
+		t.Parallel()
+
+		for name, d := range map[string]func(f func()) func(){
+			"New": func(f func()) func() {
+				d, _ := New(0, f, WithZeroWaitSync())
+
+				return d
+			},
+			"NewDebouncer": func(f func()) func() {
+				return NewDebouncer(0, f, WithZeroWaitSync()).Debounce
+			},
+		} {
+			var calls []int
+			debounced := d(func() { calls = append(calls, len(calls)) })
+
+			// Each call has completed by the time the debounced function
+			// returns, so no synchronization is needed.
+			for i := 0; i < 3; i++ {
+				debounced()
+			}
+			assert.Equal(t, []int{0, 1, 2}, calls, name)
+		}
+	})
+}
+
+func TestWithTimerGranularity(t *testing.T) {
+	t.Parallel()
+
+	t.Run("rounds expiry up to granularity", func(t *testing.T) {
+		t.Parallel()
+
+		clock := &fakeClock{t: time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)}
+		d := NewDebouncer(10*time.Second, func() {},
+			WithTimerGranularity(time.Minute),
+		)
+		d.now = clock.Now
+
+		d.Debounce()
+		want := time.Date(2023, 3, 1, 12, 1, 0, 0, time.UTC)
+		assert.Equal(t, want, d.timerExpiry)
+
+		// Calls within the same bucket leave the timer alone.
+		clock.Add(30 * time.Second)
+		d.Debounce()
+		assert.Equal(t, want, d.timerExpiry)
+
+		// Calls in the next bucket move the timer.
+		clock.Add(30 * time.Second)
+		d.Debounce()
+		assert.Equal(t, want.Add(time.Minute), d.timerExpiry)
+
+		d.Cancel()
+	})
+
+	t.Run("invokes within granularity of ideal time", func(t *testing.T) {
+		t.Parallel()
+
+		wait := 20 * time.Millisecond
+		granularity := 15 * time.Millisecond
+		fired := make(chan time.Time, 1)
+		d := NewDebouncer(wait, func() {
+			fired <- time.Now()
+		}, WithTimerGranularity(granularity))
+
+		var last time.Time
+		for i := 0; i < 10; i++ {
+			last = time.Now()
+			d.Debounce()
+			time.Sleep(2 * time.Millisecond)
+		}
+
+		at := <-fired
+		ideal := last.Add(wait)
+		assert.False(t, at.Before(ideal.Add(-time.Millisecond)),
+			"invoked too early")
+		assert.False(t, at.After(ideal.Add(granularity+10*time.Millisecond)),
+			"invoked too late")
+	})
+}
+
+func BenchmarkWithTimerGranularity(b *testing.B) {
+	for _, g := range []time.Duration{0, time.Second} {
+		b.Run(g.String(), func(b *testing.B) {
+			d := NewDebouncer(10*time.Second, func() {},
+				WithTimerGranularity(g),
+			)
+			defer d.Close()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				d.Debounce()
+			}
+		})
+	}
+}
+
+func TestWithOnIdle(t *testing.T) {
+	t.Parallel()
+
+	t.Run("activity and idle cycles", func(t *testing.T) {
+		t.Parallel()
+
+		idle := make(chan time.Time, 10)
+		d := NewDebouncer(5*time.Millisecond, func() {},
+			WithOnIdle(30*time.Millisecond, func() { idle <- time.Now() }),
+		)
+		defer d.Close()
+
+		for cycle := 0; cycle < 2; cycle++ {
+			var last time.Time
+			for i := 0; i < 3; i++ {
+				last = time.Now()
+				d.Debounce()
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			select {
+			case at := <-idle:
+				assert.GreaterOrEqual(t, at.Sub(last), 30*time.Millisecond,
+					"cycle %d", cycle)
+			case <-time.After(time.Second):
+				t.Fatalf("idle hook not called in cycle %d", cycle)
+			}
+		}
+
+		// Only called once per period of activity.
+		time.Sleep(70 * time.Millisecond)
+		assert.Len(t, idle, 0)
+	})
+
+	t.Run("not while invocation pending", func(t *testing.T) {
+		t.Parallel()
+
+		invoked := make(chan time.Time, 1)
+		idle := make(chan time.Time, 1)
+		d := NewDebouncer(50*time.Millisecond, func() {
+			invoked <- time.Now()
+		}, WithOnIdle(10*time.Millisecond, func() { idle <- time.Now() }))
+		defer d.Close()
+
+		d.Debounce()
+
+		invokedAt := <-invoked
+		idleAt := <-idle
+		assert.True(t, idleAt.After(invokedAt))
+	})
+
+	t.Run("not after close", func(t *testing.T) {
+		t.Parallel()
+
+		idle := make(chan time.Time, 1)
+		d := NewDebouncer(5*time.Millisecond, func() {},
+			WithOnIdle(10*time.Millisecond, func() { idle <- time.Now() }),
+		)
+
+		d.Debounce()
+		d.Close()
+
+		time.Sleep(30 * time.Millisecond)
+		assert.Len(t, idle, 0)
+	})
+}
+
+func TestWithWait(t *testing.T) {
+	t.Parallel()
+
+	fired := make(chan time.Time, 1)
+	start := time.Now()
+	d, _ := New(time.Hour, func() { fired <- time.Now() },
+		WithWait(20*time.Millisecond),
+	)
+
+	d()
+
+	at := <-fired
+	assert.GreaterOrEqual(t, at.Sub(start), 20*time.Millisecond)
+	assert.Less(t, at.Sub(start), time.Second)
+}
+
+func TestWithMaxWait(t *testing.T) {
+	t.Parallel()
+
+	for name, newFunc := range map[string]func(f func()) func(){
+		"New": func(f func()) func() {
+			d, _ := New(
+				20*time.Millisecond, f, WithMaxWait(50*time.Millisecond),
+			)
+
+			return d
+		},
+		"NewWithMaxWait": func(f func()) func() {
+			d, _ := NewWithMaxWait(
+				20*time.Millisecond, time.Hour, f,
+				WithMaxWait(50*time.Millisecond),
+			)
+
+			return d
+		},
+	} {
+		newFunc := newFunc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			fired := make(chan time.Time, 1)
+			start := time.Now()
+			d := newFunc(func() { fired <- time.Now() })
+
+			for i := 0; i < 10; i++ {
+				d()
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			at := <-fired
+			assert.GreaterOrEqual(t, at.Sub(start), 50*time.Millisecond)
+			assert.Less(t, at.Sub(start), 90*time.Millisecond)
+		})
+	}
+}
+
+func TestInvokeInfo_Reason(t *testing.T) {
+	t.Parallel()
+
+	reasons := make(chan InvokeReason, 4)
+	d := NewDebouncer(10*time.Millisecond, func() {},
+		WithOnInvoke(func(info InvokeInfo) { reasons <- info.Reason }),
+		WithQuota(1, 40*time.Millisecond),
+	)
+
+	d.Debounce()
+	assert.Equal(t, InvokeWait, <-reasons)
+
+	// Deferred invocations keep their reason.
+	d.Debounce()
+	assert.Equal(t, InvokeWait, <-reasons)
+
+	d.Debounce()
+	d.Flush()
+	assert.Equal(t, InvokeFlush, <-reasons)
+
+	assert.Equal(t, "wait", InvokeWait.String())
+	assert.Equal(t, "max wait", InvokeMaxWait.String())
+	assert.Equal(t, "deadline", InvokeDeadline.String())
+	assert.Equal(t, "immediate", InvokeImmediate.String())
+	assert.Equal(t, "flush", InvokeFlush.String())
+	assert.Equal(t, "max batch size", InvokeMaxBatchSize.String())
+	assert.Equal(t, "unknown", InvokeReason(0).String())
+}
+
+func TestWithCloseTimeout(t *testing.T) {
+	t.Parallel()
+
+	t.Run("fast callback", func(t *testing.T) {
+		t.Parallel()
+
+		var calls, canceled int32
+		d := NewDebouncerCtx(time.Minute, func(ctx context.Context) {
+			time.Sleep(20 * time.Millisecond)
+			if ctx.Err() != nil {
+				atomic.AddInt32(&canceled, 1)
+			}
+			atomic.AddInt32(&calls, 1)
+		}, WithCloseTimeout(time.Second))
+
+		d.Debounce()
+		start := time.Now()
+		assert.NoError(t, d.Close())
+		assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+		assert.Equal(t, int32(0), atomic.LoadInt32(&canceled))
+
+		// Calls after Close have no effect, and Close is idempotent.
+		d.Debounce()
+		assert.NoError(t, d.Close())
+		time.Sleep(20 * time.Millisecond)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("hung callback", func(t *testing.T) {
+		t.Parallel()
+
+		release := make(chan struct{})
+		canceled := make(chan struct{})
+		d := NewDebouncerCtx(time.Minute, func(ctx context.Context) {
+			select {
+			case <-ctx.Done():
+				close(canceled)
+			case <-release:
+			}
+		}, WithCloseTimeout(30*time.Millisecond))
+		defer close(release)
+
+		d.Debounce()
+		start := time.Now()
+		err := d.Close()
+		elapsed := time.Since(start)
+		assert.ErrorIs(t, err, ErrTimeout)
+		assert.GreaterOrEqual(t, elapsed, 30*time.Millisecond)
+		assert.Less(t, elapsed, 200*time.Millisecond)
+
+		// The context of the hung invocation is canceled once Close gives up.
+		select {
+		case <-canceled:
+		case <-time.After(time.Second):
+			assert.Fail(t, "context not canceled")
+		}
+		assert.NoError(t, d.Close())
+	})
+
+	t.Run("nothing pending", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int32
+		d := NewDebouncer(time.Minute, func() {
+			atomic.AddInt32(&calls, 1)
+		}, WithCloseTimeout(time.Second))
+
+		assert.NoError(t, d.Close())
+		assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("unset", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int32
+		d := NewDebouncer(time.Minute, func() {
+			atomic.AddInt32(&calls, 1)
+		})
+
+		d.Debounce()
+		assert.NoError(t, d.Close())
+		time.Sleep(10 * time.Millisecond)
+		assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+	})
+}
@@ -0,0 +1,42 @@
+package debounce
+
+// Promise represents the completion of the invocation of a callback function
+// covering a call made with DebounceDone, or of the sync covering a call to
+// Syncer.Wrote. Calls coalesced into the same invocation have their promises
+// completed together.
+type Promise struct {
+	done chan struct{}
+	err  error
+}
+
+func newPromise() *Promise {
+	return &Promise{done: make(chan struct{})}
+}
+
+// Done returns a channel which is closed once the promise has completed.
+func (p *Promise) Done() <-chan struct{} {
+	return p.done
+}
+
+// Err returns nil if the promise has not completed yet, or if the invocation
+// covering the call completed. Otherwise it returns ErrCanceled, or for a
+// Syncer, the error returned by the sync.
+func (p *Promise) Err() error {
+	select {
+	case <-p.done:
+		return p.err
+	default:
+		return nil
+	}
+}
+
+// complete completes the promise with err. It has no effect on a nil promise.
+// Each promise must be completed exactly once.
+func (p *Promise) complete(err error) {
+	if p == nil {
+		return
+	}
+
+	p.err = err
+	close(p.done)
+}
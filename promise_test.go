@@ -0,0 +1,139 @@
+package debounce
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func waitPromise(t *testing.T, p *Promise) error {
+	t.Helper()
+
+	select {
+	case <-p.Done():
+		return p.Err()
+	case <-time.After(time.Second):
+		require.FailNow(t, "promise did not complete")
+
+		return nil
+	}
+}
+
+func TestDebouncer_DebounceDone(t *testing.T) {
+	t.Parallel()
+
+	t.Run("burst", func(t *testing.T) {
+		t.Parallel()
+
+		var n int64
+		d := NewDebouncer(20*time.Millisecond, func() {
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt64(&n, 1)
+		})
+
+		p1 := d.DebounceDone()
+		p2 := d.DebounceDone()
+		assert.NoError(t, p1.Err())
+
+		select {
+		case <-p1.Done():
+			assert.Fail(t, "promise completed before invocation")
+		case <-time.After(15 * time.Millisecond):
+		}
+
+		assert.NoError(t, waitPromise(t, p1))
+		assert.NoError(t, waitPromise(t, p2))
+		// Promises complete once the invocation has completed.
+		assert.Equal(t, int64(1), atomic.LoadInt64(&n))
+	})
+
+	t.Run("leading", func(t *testing.T) {
+		t.Parallel()
+
+		var n int64
+		d := NewDebouncer(20*time.Millisecond, func() {
+			atomic.AddInt64(&n, 1)
+		}, WithBurstPassThrough(1))
+
+		p1 := d.DebounceDone()
+		assert.NoError(t, waitPromise(t, p1))
+		assert.Equal(t, int64(1), atomic.LoadInt64(&n))
+
+		p2 := d.DebounceDone()
+		select {
+		case <-p2.Done():
+			assert.Fail(t, "trailing promise completed with leading one")
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		assert.NoError(t, waitPromise(t, p2))
+		assert.Equal(t, int64(2), atomic.LoadInt64(&n))
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		t.Parallel()
+
+		var n int64
+		d := NewDebouncer(20*time.Millisecond, func() {
+			atomic.AddInt64(&n, 1)
+		})
+
+		p1 := d.DebounceDone()
+		p2 := d.DebounceDone()
+		d.Cancel()
+
+		assert.ErrorIs(t, waitPromise(t, p1), ErrCanceled)
+		assert.ErrorIs(t, waitPromise(t, p2), ErrCanceled)
+
+		p3 := d.DebounceDone()
+		assert.NoError(t, waitPromise(t, p3))
+		assert.Equal(t, int64(1), atomic.LoadInt64(&n))
+	})
+
+	t.Run("max wait", func(t *testing.T) {
+		t.Parallel()
+
+		d := NewDebouncer(20*time.Millisecond, func() {},
+			WithMaxWait(50*time.Millisecond),
+		)
+
+		start := time.Now()
+		p := d.DebounceDone()
+		go func() {
+			for i := 0; i < 10; i++ {
+				time.Sleep(10 * time.Millisecond)
+				d.Debounce()
+			}
+			d.Close()
+		}()
+
+		assert.NoError(t, waitPromise(t, p))
+		assert.Less(t, time.Since(start), 80*time.Millisecond)
+	})
+
+	t.Run("close", func(t *testing.T) {
+		t.Parallel()
+
+		d := NewDebouncer(20*time.Millisecond, func() {})
+
+		p1 := d.DebounceDone()
+		d.Close()
+		p2 := d.DebounceDone()
+
+		assert.ErrorIs(t, waitPromise(t, p1), ErrCanceled)
+		assert.ErrorIs(t, waitPromise(t, p2), ErrCanceled)
+	})
+
+	t.Run("predicate", func(t *testing.T) {
+		t.Parallel()
+
+		d := NewDebouncer(20*time.Millisecond, func() {},
+			WithPredicate(func() bool { return false }),
+		)
+
+		assert.ErrorIs(t, waitPromise(t, d.DebounceDone()), ErrCanceled)
+	})
+}
@@ -0,0 +1,142 @@
+package debounce
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Publisher builds immutable snapshots of some state, and publishes them for
+// readers, once the state stops changing. Writers call Invalidate after
+// changing the state, and readers call Load to get the latest snapshot without
+// any locking.
+//
+// All methods are safe for concurrent use in goroutines.
+type Publisher[T any] struct {
+	d        *Debouncer
+	build    func() *T
+	buildMux sync.Mutex
+
+	// buildOnLoad is true if WithBuildOnLoad is used.
+	buildOnLoad bool
+
+	// snapshot holds the latest published *T.
+	snapshot atomic.Value
+}
+
+// NewPublisher returns a new Publisher, which calls build once Invalidate has
+// not been called for the wait time, and publishes the snapshot it returns.
+// Calls to build never run concurrently, and each one starts after the call to
+// Invalidate which triggered it, so the latest snapshot always reflects all
+// changes made before the last call to Invalidate, once it has been built.
+//
+// Optional behavior can be configured by passing one or more PublisherOption
+// values, which include all Option values, like WithMaxWait to publish
+// snapshots regularly while changes keep coming, or WithBuildOnLoad to build
+// the first snapshot on demand.
+func NewPublisher[T any](
+	wait time.Duration,
+	build func() *T,
+	opts ...PublisherOption,
+) *Publisher[T] {
+	o := publisherOptions{}
+	for _, opt := range opts {
+		opt.applyPublisher(&o)
+	}
+
+	p := &Publisher[T]{build: build, buildOnLoad: o.buildOnLoad}
+	p.d = NewDebouncer(wait, func() { p.publish() }, o.opts...)
+
+	return p
+}
+
+// PublisherOption configures optional behavior of a Publisher. Any Option is a
+// PublisherOption, and so is WithBuildOnLoad.
+type PublisherOption interface {
+	applyPublisher(o *publisherOptions)
+}
+
+type publisherOptions struct {
+	opts        []Option
+	buildOnLoad bool
+}
+
+type publisherOption func(o *publisherOptions)
+
+func (f publisherOption) applyPublisher(o *publisherOptions) {
+	f(o)
+}
+
+func (f Option) applyPublisher(o *publisherOptions) {
+	o.opts = append(o.opts, f)
+}
+
+// WithBuildOnLoad makes Publisher.Load build and publish a snapshot right away
+// when none has been published yet, rather than returning nil, so readers never
+// have to handle a missing snapshot.
+func WithBuildOnLoad() PublisherOption {
+	return publisherOption(func(o *publisherOptions) {
+		o.buildOnLoad = true
+	})
+}
+
+// Invalidate schedules a new snapshot to be built and published.
+func (p *Publisher[T]) Invalidate() {
+	p.d.Debounce()
+}
+
+// Load returns the latest published snapshot, or nil if none has been
+// published yet. With WithBuildOnLoad, a snapshot is built and published right
+// away instead if none has been published yet.
+func (p *Publisher[T]) Load() *T {
+	if snapshot := p.load(); snapshot != nil || !p.buildOnLoad {
+		return snapshot
+	}
+
+	p.buildMux.Lock()
+	defer p.buildMux.Unlock()
+
+	// Another caller may have published a snapshot while waiting for the lock.
+	if snapshot := p.load(); snapshot != nil {
+		return snapshot
+	}
+
+	snapshot := p.build()
+	p.snapshot.Store(snapshot)
+
+	return snapshot
+}
+
+// Flush builds and publishes a snapshot right away if one is pending, and
+// waits for it to be published. It returns ErrNothingPending if no snapshot is
+// pending, or ErrClosed if the Publisher has been closed.
+func (p *Publisher[T]) Flush() error {
+	done := p.d.flushDone()
+	if done == nil {
+		// Nothing is pending, so let Flush tell why.
+		return p.d.Flush()
+	}
+	<-done.Done()
+
+	return done.Err()
+}
+
+// Close stops the Publisher like Debouncer.Close, discarding any pending build
+// unless WithCloseTimeout is used. The latest snapshot remains available from
+// Load.
+func (p *Publisher[T]) Close() error {
+	return p.d.Close()
+}
+
+func (p *Publisher[T]) publish() {
+	p.buildMux.Lock()
+	defer p.buildMux.Unlock()
+
+	p.snapshot.Store(p.build())
+}
+
+func (p *Publisher[T]) load() *T {
+	snapshot, _ := p.snapshot.Load().(*T)
+
+	return snapshot
+}
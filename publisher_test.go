@@ -0,0 +1,180 @@
+package debounce
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// snapshot is an immutable snapshot of a list of items, with a sum to tell if
+// it is complete.
+type snapshot struct {
+	items []int
+	sum   int
+}
+
+func (s *snapshot) complete() bool {
+	sum := 0
+	for _, item := range s.items {
+		sum += item
+	}
+
+	return sum == s.sum
+}
+
+// snapshotBuilder is a mutable list of items, which builds snapshots of
+// itself, recording if builds ever run concurrently.
+type snapshotBuilder struct {
+	mux        sync.Mutex
+	items      []int
+	running    int32
+	concurrent int32
+	builds     int32
+}
+
+func (b *snapshotBuilder) Add(item int) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	b.items = append(b.items, item)
+}
+
+func (b *snapshotBuilder) Build() *snapshot {
+	if atomic.AddInt32(&b.running, 1) > 1 {
+		atomic.StoreInt32(&b.concurrent, 1)
+	}
+	defer atomic.AddInt32(&b.running, -1)
+	atomic.AddInt32(&b.builds, 1)
+
+	b.mux.Lock()
+	s := &snapshot{items: append([]int(nil), b.items...)}
+	b.mux.Unlock()
+
+	// Take some time, to give concurrent builds a chance to overlap.
+	time.Sleep(time.Millisecond)
+	for _, item := range s.items {
+		s.sum += item
+	}
+
+	return s
+}
+
+func TestNewPublisher(t *testing.T) {
+	t.Parallel()
+
+	t.Run("concurrent use", func(t *testing.T) {
+		t.Parallel()
+
+		b := &snapshotBuilder{}
+		p := NewPublisher(2*time.Millisecond, b.Build,
+			WithMaxWait(5*time.Millisecond),
+			WithCloseTimeout(time.Second),
+		)
+
+		wg := sync.WaitGroup{}
+		for w := 0; w < 10; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < 100; i++ {
+					b.Add(w*100 + i)
+					p.Invalidate()
+					if i%10 == 0 {
+						time.Sleep(time.Millisecond)
+					}
+				}
+			}(w)
+		}
+
+		done := make(chan struct{})
+		readers := sync.WaitGroup{}
+		for r := 0; r < 10; r++ {
+			readers.Add(1)
+			go func() {
+				defer readers.Done()
+				for {
+					if s := p.Load(); s != nil && !s.complete() {
+						assert.Fail(t, "incomplete snapshot")
+
+						return
+					}
+
+					select {
+					case <-done:
+						return
+					default:
+					}
+				}
+			}()
+		}
+
+		wg.Wait()
+		close(done)
+		readers.Wait()
+
+		// Close publishes any pending snapshot, and waits for running builds.
+		require.NoError(t, p.Close())
+
+		s := p.Load()
+		require.NotNil(t, s)
+		assert.Len(t, s.items, 1000)
+		assert.True(t, s.complete())
+		assert.Equal(t, int32(0), atomic.LoadInt32(&b.concurrent))
+		assert.Greater(t, atomic.LoadInt32(&b.builds), int32(1))
+	})
+
+	t.Run("without build on load", func(t *testing.T) {
+		t.Parallel()
+
+		b := &snapshotBuilder{}
+		p := NewPublisher(10*time.Millisecond, b.Build)
+
+		assert.Nil(t, p.Load())
+
+		b.Add(1)
+		p.Invalidate()
+		assert.Nil(t, p.Load())
+
+		time.Sleep(30 * time.Millisecond)
+		assert.Equal(t, []int{1}, p.Load().items)
+	})
+
+	t.Run("build on load", func(t *testing.T) {
+		t.Parallel()
+
+		b := &snapshotBuilder{}
+		b.Add(1)
+		p := NewPublisher(10*time.Millisecond, b.Build, WithBuildOnLoad())
+
+		wg := sync.WaitGroup{}
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s := p.Load()
+				if assert.NotNil(t, s) {
+					assert.Equal(t, []int{1}, s.items)
+				}
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, int32(1), atomic.LoadInt32(&b.builds))
+
+		// Once published, snapshots are only rebuilt when invalidated.
+		b.Add(2)
+		assert.Equal(t, []int{1}, p.Load().items)
+		p.Invalidate()
+		require.NoError(t, p.Flush())
+		assert.Equal(t, []int{1, 2}, p.Load().items)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&b.builds))
+
+		assert.ErrorIs(t, p.Flush(), ErrNothingPending)
+		require.NoError(t, p.Close())
+		assert.ErrorIs(t, p.Flush(), ErrClosed)
+		assert.Equal(t, []int{1, 2}, p.Load().items)
+	})
+}
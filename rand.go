@@ -0,0 +1,26 @@
+package debounce
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// globalRand is the default source of randomness used when no source has been
+// set with WithRandSource.
+var globalRand = &lockedRand{
+	//nolint:gosec // Jitter does not need a cryptographic source.
+	r: rand.New(rand.NewSource(time.Now().UnixNano())),
+}
+
+type lockedRand struct {
+	mux sync.Mutex
+	r   *rand.Rand
+}
+
+func (lr *lockedRand) Int63n(n int64) int64 {
+	lr.mux.Lock()
+	defer lr.mux.Unlock()
+
+	return lr.r.Int63n(n)
+}
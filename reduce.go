@@ -0,0 +1,69 @@
+package debounce
+
+import (
+	"context"
+	"time"
+)
+
+// NewReduce returns a debounced function like NewTyped, but which folds the
+// values of all calls into a single value with reduce, in the order the calls
+// were made, and passes the result to f. The value of the first call of each
+// invocation is used as is, so reduce is only called from the second call on.
+//
+// When the first calls of a burst are let through with WithBurstPassThrough,
+// each of their invocations is passed the value of the call which triggered
+// it.
+//
+// The returned cancel function can be used to cancel any pending invocation of
+// f, discarding the values folded for it, but is not required to be called, so
+// can be ignored if not needed.
+//
+// Both debounced and cancel functions are safe for concurrent use in
+// goroutines, and can both be called multiple times. The reduce function is
+// called while holding the debounced function's internal lock, so it should
+// return quickly, and must not call the debounced function.
+func NewReduce[T any](
+	wait time.Duration,
+	reduce func(acc, next T) T,
+	f func(value T),
+	opts ...Option,
+) (debounced func(value T), cancel func()) {
+	d := newReduce(wait, reduce, f, opts)
+
+	return func(value T) { d.add(value) }, d.cancelFunc()
+}
+
+// NewReduceWithMaxWait returns a debounced function like NewReduce, but with a
+// maximum wait time of maxWait, which is the maximum time f is allowed to be
+// delayed before it is invoked with the values folded so far.
+func NewReduceWithMaxWait[T any](
+	wait, maxWait time.Duration,
+	reduce func(acc, next T) T,
+	f func(value T),
+	opts ...Option,
+) (debounced func(value T), cancel func()) {
+	d := newReduce(wait, reduce, f, opts).withMaxWait(maxWait)
+
+	return func(value T) { d.add(value) }, d.cancelFunc()
+}
+
+func newReduce[T any](
+	wait time.Duration,
+	reduce func(acc, next T) T,
+	f func(value T),
+	opts []Option,
+) *Debouncer {
+	d := newDebouncer(wait, func(_ context.Context, info InvokeInfo) {
+		// The value is only ever a T, or nil when T is an interface type.
+		v, _ := info.value.(T)
+		f(v)
+	}, opts)
+	d.combine = func(acc, next interface{}) interface{} {
+		a, _ := acc.(T)
+		n, _ := next.(T)
+
+		return reduce(a, n)
+	}
+
+	return d
+}
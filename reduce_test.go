@@ -0,0 +1,173 @@
+package debounce
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewReduce(t *testing.T) {
+	t.Parallel()
+
+	// Appending digits makes the result depend on the order of calls.
+	digits := func(acc, next int) int { return acc*10 + next }
+
+	tests := []struct {
+		name       string
+		wait       time.Duration
+		maxWait    time.Duration
+		opts       []Option
+		calls      []typedOp
+		wantValues map[time.Duration][]int
+	}{
+		{
+			name: "one call one trigger",
+			wait: 20 * time.Millisecond,
+			calls: []typedOp{
+				{delay: 10 * time.Millisecond, value: 1},
+			},
+			wantValues: map[time.Duration][]int{
+				25 * time.Millisecond:  nil,
+				35 * time.Millisecond:  {1},
+				150 * time.Millisecond: {1},
+			},
+		},
+		{
+			name: "many calls two triggers",
+			wait: 20 * time.Millisecond,
+			calls: []typedOp{
+				{delay: 5 * time.Millisecond, value: 1},
+				{delay: 7 * time.Millisecond, value: 2},
+				{delay: 10 * time.Millisecond, value: 3}, // trigger 1
+				{delay: 35 * time.Millisecond, value: 4},
+				{delay: 40 * time.Millisecond, value: 5},
+				{delay: 50 * time.Millisecond, value: 6}, // trigger 2
+			},
+			wantValues: map[time.Duration][]int{
+				25 * time.Millisecond: nil,
+				// from call at 10ms (+20ms wait = 30ms)
+				35 * time.Millisecond: {123},
+				65 * time.Millisecond: {123},
+				// from call at 50ms (+20ms wait = 70ms)
+				75 * time.Millisecond:  {123, 456},
+				150 * time.Millisecond: {123, 456},
+			},
+		},
+		{
+			name: "many calls, one cancel, two triggers",
+			wait: 20 * time.Millisecond,
+			calls: []typedOp{
+				{delay: 5 * time.Millisecond, value: 1},
+				{delay: 10 * time.Millisecond, value: 2}, // trigger 1
+				{delay: 35 * time.Millisecond, value: 3},
+				{delay: 40 * time.Millisecond, value: 4},
+				{delay: 50 * time.Millisecond, cancel: true},
+				{delay: 80 * time.Millisecond, value: 5},
+				{delay: 90 * time.Millisecond, value: 6},
+				{delay: 100 * time.Millisecond, value: 7}, // trigger 2
+			},
+			wantValues: map[time.Duration][]int{
+				// from call at 10ms (+20ms wait = 30ms)
+				35 * time.Millisecond:  {12},
+				115 * time.Millisecond: {12},
+				// call at 100ms (+20ms wait = 120ms)
+				125 * time.Millisecond: {12, 567},
+				150 * time.Millisecond: {12, 567},
+			},
+		},
+		{
+			name: "leading and trailing",
+			wait: 20 * time.Millisecond,
+			opts: []Option{WithBurstPassThrough(1)},
+			calls: []typedOp{
+				{delay: 10 * time.Millisecond, value: 1}, // leading
+				{delay: 15 * time.Millisecond, value: 2},
+				{delay: 20 * time.Millisecond, value: 3}, // trailing
+			},
+			wantValues: map[time.Duration][]int{
+				5 * time.Millisecond:  nil,
+				15 * time.Millisecond: {1},
+				35 * time.Millisecond: {1},
+				// from call at 20ms (+20ms wait = 40ms)
+				45 * time.Millisecond:  {1, 23},
+				150 * time.Millisecond: {1, 23},
+			},
+		},
+		{
+			name:    "until right before maxWait",
+			wait:    20 * time.Millisecond,
+			maxWait: 50 * time.Millisecond,
+			calls: []typedOp{
+				{delay: 0 * time.Millisecond, value: 1},
+				{delay: 10 * time.Millisecond, value: 2},
+				{delay: 20 * time.Millisecond, value: 3},
+				{delay: 30 * time.Millisecond, value: 4},
+				{delay: 40 * time.Millisecond, value: 5},
+				{delay: 60 * time.Millisecond, value: 6},
+			},
+			wantValues: map[time.Duration][]int{
+				45 * time.Millisecond: nil,
+				// tick over at 50ms via maxWait
+				55 * time.Millisecond: {12345},
+				// from call at 60ms (+20ms wait = 80ms)
+				85 * time.Millisecond:  {12345, 6},
+				150 * time.Millisecond: {12345, 6},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mux := sync.RWMutex{}
+
+			var got []int
+			f := func(value int) {
+				mux.Lock()
+				defer mux.Unlock()
+				got = append(got, value)
+			}
+
+			var d func(int)
+			var c func()
+			if tt.maxWait > 0 {
+				d, c = NewReduceWithMaxWait(
+					tt.wait, tt.maxWait, digits, f, tt.opts...,
+				)
+			} else {
+				d, c = NewReduce(tt.wait, digits, f, tt.opts...)
+			}
+
+			wg := sync.WaitGroup{}
+			for _, op := range tt.calls {
+				wg.Add(1)
+				go func(op typedOp) {
+					defer wg.Done()
+					time.Sleep(op.delay)
+					if op.cancel {
+						c()
+					} else {
+						d(op.value)
+					}
+				}(op)
+			}
+
+			for delay, values := range tt.wantValues {
+				wg.Add(1)
+				go func(interval time.Duration, values []int) {
+					defer wg.Done()
+					time.Sleep(interval)
+
+					mux.RLock()
+					defer mux.RUnlock()
+					assert.Equal(t, values, got, "at %s", interval)
+				}(delay, values)
+			}
+
+			wg.Wait()
+		})
+	}
+}
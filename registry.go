@@ -0,0 +1,166 @@
+package debounce
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// Registry keeps track of Debouncers, so they can all be flushed or closed at
+// once, for example on graceful shutdown, without having to hunt down each of
+// them. Debouncers are added to a Registry when created with WithRegistry, and
+// removed from it once closed.
+//
+// All methods are safe for concurrent use in goroutines.
+type Registry struct {
+	concurrency int
+
+	mux        sync.Mutex
+	debouncers map[*Debouncer]struct{}
+	shutdown   bool
+}
+
+// DefaultRegistry is the Registry used by the package level FlushAll and
+// Shutdown functions. Debouncers are only added to it when created with
+// WithRegistry(DefaultRegistry).
+var DefaultRegistry = NewRegistry(0)
+
+// NewRegistry returns a new Registry which flushes at most concurrency
+// Debouncers at a time. A concurrency of 0 or less uses runtime.GOMAXPROCS(0).
+func NewRegistry(concurrency int) *Registry {
+	if concurrency <= 0 {
+		concurrency = runtime.GOMAXPROCS(0)
+	}
+
+	return &Registry{
+		concurrency: concurrency,
+		debouncers:  map[*Debouncer]struct{}{},
+	}
+}
+
+// FlushAll calls DefaultRegistry.FlushAll.
+func FlushAll(ctx context.Context) error {
+	return DefaultRegistry.FlushAll(ctx)
+}
+
+// Shutdown calls DefaultRegistry.Shutdown.
+func Shutdown(ctx context.Context) error {
+	return DefaultRegistry.Shutdown(ctx)
+}
+
+// Len returns the number of Debouncers in the Registry.
+func (r *Registry) Len() int {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	return len(r.debouncers)
+}
+
+// FlushAll invokes any pending invocation of the Debouncers in the Registry
+// right away, like Flush, and waits for the invocations to complete. If ctx is
+// done before then, FlushAll stops flushing and waiting, and returns the
+// context's error.
+func (r *Registry) FlushAll(ctx context.Context) error {
+	return r.each(ctx, r.debouncerList(), func(d *Debouncer) {
+		p := d.flushDone()
+		if p == nil {
+			return
+		}
+
+		select {
+		case <-p.Done():
+		case <-ctx.Done():
+		}
+	})
+}
+
+// Shutdown flushes the Debouncers in the Registry like FlushAll, and then
+// closes them. If ctx is done before all flushed invocations have completed,
+// the remaining Debouncers are closed without being flushed, and the context's
+// error is returned. Otherwise the first error returned by closing a Debouncer
+// is, like ErrTimeout due to WithCloseTimeout.
+//
+// Debouncers created with the Registry after Shutdown has been called are
+// closed right away, so a shut down Registry stays empty. Calling Shutdown
+// more than once has no further effect.
+func (r *Registry) Shutdown(ctx context.Context) error {
+	r.mux.Lock()
+	r.shutdown = true
+	r.mux.Unlock()
+
+	err := r.FlushAll(ctx)
+	for _, d := range r.debouncerList() {
+		if closeErr := d.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+
+	return err
+}
+
+// each calls f for each of debouncers, with at most r.concurrency calls
+// running at a time, and waits for them to return. It stops starting calls
+// once ctx is done, and returns the context's error.
+func (r *Registry) each(
+	ctx context.Context,
+	debouncers []*Debouncer,
+	f func(d *Debouncer),
+) error {
+	sem := make(chan struct{}, r.concurrency)
+	wg := sync.WaitGroup{}
+
+	for _, d := range debouncers {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(d *Debouncer) {
+			defer wg.Done()
+			f(d)
+			<-sem
+		}(d)
+	}
+	wg.Wait()
+
+	return ctx.Err()
+}
+
+// debouncerList returns the Debouncers in the Registry.
+func (r *Registry) debouncerList() []*Debouncer {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	list := make([]*Debouncer, 0, len(r.debouncers))
+	for d := range r.debouncers {
+		list = append(list, d)
+	}
+
+	return list
+}
+
+// add adds d to the Registry, and reports false if the Registry has been shut
+// down, in which case d is not added.
+func (r *Registry) add(d *Debouncer) bool {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	if r.shutdown {
+		return false
+	}
+	r.debouncers[d] = struct{}{}
+
+	return true
+}
+
+// remove removes d from the Registry. It has no effect if d is not in it.
+func (r *Registry) remove(d *Debouncer) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	delete(r.debouncers, d)
+}
@@ -0,0 +1,187 @@
+package debounce
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	t.Parallel()
+
+	t.Run("flush all and shutdown", func(t *testing.T) {
+		t.Parallel()
+
+		r := NewRegistry(0)
+		counts := make([]int32, 5)
+		debouncers := make([]*Debouncer, 0, len(counts))
+		for i := range counts {
+			i := i
+			d := NewDebouncer(time.Minute, func() {
+				time.Sleep(10 * time.Millisecond)
+				atomic.AddInt32(&counts[i], 1)
+			}, WithRegistry(r))
+			d.Debounce()
+			d.Debounce()
+			debouncers = append(debouncers, d)
+		}
+		require.Equal(t, 5, r.Len())
+
+		// FlushAll waits for the flushed invocations to complete.
+		require.NoError(t, r.FlushAll(context.Background()))
+		for i := range counts {
+			assert.Equal(t, int32(1), atomic.LoadInt32(&counts[i]))
+		}
+
+		require.NoError(t, r.FlushAll(context.Background()))
+		debouncers[0].Debounce()
+		require.NoError(t, r.Shutdown(context.Background()))
+		assert.Equal(t, int32(2), atomic.LoadInt32(&counts[0]))
+		for i := 1; i < len(counts); i++ {
+			assert.Equal(t, int32(1), atomic.LoadInt32(&counts[i]))
+		}
+		assert.Equal(t, 0, r.Len())
+
+		// Calls after Shutdown have no effect.
+		for _, d := range debouncers {
+			d.Debounce()
+			d.Flush()
+		}
+		require.NoError(t, r.FlushAll(context.Background()))
+		require.NoError(t, r.Shutdown(context.Background()))
+		time.Sleep(20 * time.Millisecond)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&counts[0]))
+
+		// Debouncers created after Shutdown are closed right away.
+		var late int32
+		d := NewDebouncer(0, func() { atomic.AddInt32(&late, 1) },
+			WithRegistry(r),
+		)
+		d.Debounce()
+		assert.Equal(t, int32(0), atomic.LoadInt32(&late))
+		assert.Equal(t, 0, r.Len())
+	})
+
+	t.Run("closed debouncers unregister", func(t *testing.T) {
+		t.Parallel()
+
+		r := NewRegistry(0)
+		d := NewDebouncer(time.Minute, func() {}, WithRegistry(r))
+		NewDebouncer(time.Minute, func() {}, WithRegistry(r))
+		require.Equal(t, 2, r.Len())
+
+		d.Close()
+		d.Close()
+		assert.Equal(t, 1, r.Len())
+
+		var calls int32
+		g := NewGroup(10*time.Millisecond, func(string) {
+			atomic.AddInt32(&calls, 1)
+		}, WithRegistry(r), WithGroupMaxIdle(10*time.Millisecond))
+		g.Debounce("a")
+		g.Debounce("b")
+		assert.Equal(t, 3, r.Len())
+
+		time.Sleep(60 * time.Millisecond)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+		assert.Equal(t, 0, g.Len())
+		assert.Equal(t, 1, r.Len())
+	})
+
+	t.Run("canceled functions unregister", func(t *testing.T) {
+		t.Parallel()
+
+		r := NewRegistry(0)
+		_, cancel := New(time.Minute, func() {}, WithRegistry(r))
+		_, cancelMutable := NewMutable(time.Minute, WithRegistry(r))
+		_, cancelTwoStage := NewTwoStage(time.Second, time.Minute,
+			func() {}, func() {}, WithRegistry(r))
+		require.Equal(t, 4, r.Len())
+
+		cancel()
+		cancel()
+		assert.Equal(t, 3, r.Len())
+		cancelMutable()
+		assert.Equal(t, 2, r.Len())
+		cancelTwoStage()
+		assert.Equal(t, 0, r.Len())
+	})
+
+	t.Run("context done", func(t *testing.T) {
+		t.Parallel()
+
+		r := NewRegistry(0)
+		release := make(chan struct{})
+		defer close(release)
+
+		var calls int32
+		hung := NewDebouncer(time.Minute, func() {
+			atomic.AddInt32(&calls, 1)
+			<-release
+		}, WithRegistry(r))
+		hung.Debounce()
+
+		ctx, cancel := context.WithTimeout(
+			context.Background(), 20*time.Millisecond,
+		)
+		defer cancel()
+
+		start := time.Now()
+		err := r.Shutdown(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+		assert.Equal(t, 0, r.Len())
+	})
+
+	t.Run("bounded concurrency", func(t *testing.T) {
+		t.Parallel()
+
+		r := NewRegistry(2)
+		mux := sync.Mutex{}
+		running, maxRunning := 0, 0
+		for i := 0; i < 6; i++ {
+			d := NewDebouncer(time.Minute, func() {
+				mux.Lock()
+				running++
+				if running > maxRunning {
+					maxRunning = running
+				}
+				mux.Unlock()
+
+				time.Sleep(10 * time.Millisecond)
+
+				mux.Lock()
+				running--
+				mux.Unlock()
+			}, WithRegistry(r))
+			d.Debounce()
+		}
+
+		require.NoError(t, r.FlushAll(context.Background()))
+
+		mux.Lock()
+		defer mux.Unlock()
+		assert.Equal(t, 2, maxRunning)
+		assert.Equal(t, 0, running)
+	})
+
+	t.Run("default registry", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int32
+		d := NewDebouncer(time.Minute, func() {
+			atomic.AddInt32(&calls, 1)
+		}, WithRegistry(DefaultRegistry))
+		defer d.Close()
+
+		d.Debounce()
+		require.NoError(t, FlushAll(context.Background()))
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+}
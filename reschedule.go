@@ -0,0 +1,38 @@
+package debounce
+
+import "time"
+
+// Rescheduler allows a callback function set with WithReschedule to request
+// another invocation of itself.
+type Rescheduler interface {
+	// After schedules another invocation of the callback function after
+	// delay. It does not count as a call to the debounced function, but starts
+	// a burst like one, so calls made in the meantime are merged into it, and
+	// the maximum wait time, if any, bounds how far they can postpone it. The
+	// maximum wait time is measured from the call to After, and never expires
+	// before delay has elapsed.
+	//
+	// If calls to the debounced function made while the callback function was
+	// running have already scheduled another invocation, After has no effect,
+	// as that invocation covers the request.
+	After(delay time.Duration)
+}
+
+type rescheduler struct {
+	d *Debouncer
+}
+
+func (r rescheduler) After(delay time.Duration) {
+	d := r.d
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	if d.closed || d.dirty {
+		return
+	}
+
+	d.dirty = true
+	d.burstWait = d.nextWait()
+	d.armMaxTimer(delay)
+	d.resetTimer(delay, true)
+}
@@ -0,0 +1,155 @@
+package debounce
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithReschedule(t *testing.T) {
+	t.Parallel()
+
+	t.Run("chained reschedules", func(t *testing.T) {
+		t.Parallel()
+
+		fired := make(chan time.Time, 10)
+		n := 0
+		d := NewDebouncer(10*time.Millisecond, nil, WithReschedule(
+			func(r Rescheduler) {
+				fired <- time.Now()
+				n++
+				if n <= 3 {
+					r.After(20 * time.Millisecond)
+				}
+			},
+		))
+
+		start := time.Now()
+		d.Debounce()
+
+		want := []time.Duration{
+			10 * time.Millisecond,
+			30 * time.Millisecond,
+			50 * time.Millisecond,
+			70 * time.Millisecond,
+		}
+		for i, w := range want {
+			select {
+			case at := <-fired:
+				assert.InDelta(t, w, at.Sub(start), float64(8*time.Millisecond),
+					"invocation %d", i)
+			case <-time.After(time.Second):
+				t.Fatalf("invocation %d did not happen", i)
+			}
+		}
+
+		time.Sleep(40 * time.Millisecond)
+		assert.Len(t, fired, 0)
+		assert.Equal(t, 1, d.Stats().Calls)
+	})
+
+	t.Run("merges with external calls", func(t *testing.T) {
+		t.Parallel()
+
+		mux := sync.Mutex{}
+		n := 0
+		started := make(chan struct{}, 10)
+		release := make(chan struct{})
+		d := NewDebouncer(5*time.Millisecond, nil, WithReschedule(
+			func(r Rescheduler) {
+				mux.Lock()
+				n++
+				first := n == 1
+				mux.Unlock()
+
+				started <- struct{}{}
+				if first {
+					<-release
+					r.After(30 * time.Millisecond)
+				}
+			},
+		))
+
+		d.Debounce()
+		<-started
+
+		// An external call while the callback runs schedules the next
+		// invocation, which covers the reschedule request.
+		d.Debounce()
+		close(release)
+		<-started
+
+		time.Sleep(50 * time.Millisecond)
+		mux.Lock()
+		defer mux.Unlock()
+		assert.Equal(t, 2, n)
+	})
+	t.Run("maxWait bounds merged calls", func(t *testing.T) {
+		t.Parallel()
+
+		fired := make(chan struct{}, 10)
+		rescheduled := false
+		d := NewDebouncer(20*time.Millisecond, nil, WithReschedule(
+			func(r Rescheduler) {
+				fired <- struct{}{}
+				if !rescheduled {
+					rescheduled = true
+					r.After(10 * time.Millisecond)
+				}
+			},
+		), WithMaxWait(50*time.Millisecond))
+		defer d.Close()
+
+		d.Debounce()
+		assert.NoError(t, d.Flush())
+		<-fired
+
+		// Calls merged into the rescheduled burst keep postponing the wait
+		// timer, but not beyond the maximum wait time.
+		start := time.Now()
+		stop := time.After(200 * time.Millisecond)
+		tick := time.NewTicker(5 * time.Millisecond)
+		defer tick.Stop()
+		for {
+			select {
+			case <-fired:
+				assert.Less(t, time.Since(start), 150*time.Millisecond)
+
+				return
+			case <-tick.C:
+				d.Debounce()
+			case <-stop:
+				t.Fatal("maxWait did not bound the rescheduled burst")
+			}
+		}
+	})
+
+	t.Run("maxWait does not cut delay short", func(t *testing.T) {
+		t.Parallel()
+
+		fired := make(chan time.Time, 10)
+		rescheduled := false
+		d := NewDebouncer(time.Hour, nil, WithReschedule(
+			func(r Rescheduler) {
+				fired <- time.Now()
+				if !rescheduled {
+					rescheduled = true
+					r.After(60 * time.Millisecond)
+				}
+			},
+		), WithMaxWait(10*time.Millisecond))
+		defer d.Close()
+
+		d.Debounce()
+		start := <-fired
+
+		select {
+		case at := <-fired:
+			assert.GreaterOrEqual(t, at.Sub(start), 60*time.Millisecond)
+		case <-time.After(time.Second):
+			t.Fatal("rescheduled invocation did not happen")
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package debounce
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ResultDebouncer is a Debouncer for a callback function which returns a
+// result, making the result of each invocation available to callers.
+//
+// All methods are safe for concurrent use in goroutines.
+type ResultDebouncer[T any] struct {
+	*Debouncer
+
+	mux    sync.Mutex
+	latest T
+	ok     bool
+	next   *result[T]
+}
+
+// result is the result of an invocation, which is available once done is
+// closed.
+type result[T any] struct {
+	done  chan struct{}
+	value T
+}
+
+// NewWithResult returns a new ResultDebouncer, which debounces calls to its
+// Debounce method like NewDebouncer, and keeps the value returned by each
+// invocation of f.
+//
+// Optional behavior can be configured by passing one or more Option values.
+func NewWithResult[T any](
+	wait time.Duration,
+	f func() T,
+	opts ...Option,
+) *ResultDebouncer[T] {
+	r := &ResultDebouncer[T]{next: newResult[T]()}
+	r.Debouncer = newDebouncer(wait, func(context.Context, InvokeInfo) {
+		r.publish(f())
+	}, opts)
+
+	return r
+}
+
+func newResult[T any]() *result[T] {
+	return &result[T]{done: make(chan struct{})}
+}
+
+// Latest returns the value returned by the most recently completed invocation
+// of the callback function, and reports if there has been one.
+func (r *ResultDebouncer[T]) Latest() (T, bool) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	return r.latest, r.ok
+}
+
+// Next blocks until the next invocation of the callback function completes,
+// and returns its value. An invocation which is already running when Next is
+// called counts as the next one. If ctx is done first, Next returns the
+// context's error.
+//
+// Next keeps blocking until ctx is done when no further invocation happens, for
+// example as the ResultDebouncer has been closed.
+func (r *ResultDebouncer[T]) Next(ctx context.Context) (T, error) {
+	r.mux.Lock()
+	next := r.next
+	r.mux.Unlock()
+
+	select {
+	case <-next.done:
+		return next.value, nil
+	case <-ctx.Done():
+		var zero T
+
+		return zero, ctx.Err()
+	}
+}
+
+// publish makes value the latest result, and hands it to callers waiting in
+// Next.
+func (r *ResultDebouncer[T]) publish(value T) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	r.latest = value
+	r.ok = true
+
+	r.next.value = value
+	close(r.next.done)
+	r.next = newResult[T]()
+}
@@ -0,0 +1,79 @@
+package debounce
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWithResult(t *testing.T) {
+	t.Parallel()
+
+	var n int64
+	r := NewWithResult(20*time.Millisecond, func() int64 {
+		return atomic.AddInt64(&n, 1)
+	})
+
+	_, ok := r.Latest()
+	assert.False(t, ok)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	got := make([]int64, 5)
+	wg := sync.WaitGroup{}
+	for i := range got {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			v, err := r.Next(ctx)
+			assert.NoError(t, err)
+			got[i] = v
+		}(i)
+	}
+
+	// Give the waiters time to start waiting.
+	time.Sleep(10 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		r.Debounce()
+		time.Sleep(5 * time.Millisecond)
+	}
+	wg.Wait()
+
+	assert.Equal(t, []int64{1, 1, 1, 1, 1}, got)
+
+	v, ok := r.Latest()
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), v)
+
+	r.Debounce()
+	v, err := r.Next(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), v)
+
+	v, ok = r.Latest()
+	assert.True(t, ok)
+	assert.Equal(t, int64(2), v)
+}
+
+func TestResultDebouncer_Next_contextDone(t *testing.T) {
+	t.Parallel()
+
+	r := NewWithResult(10*time.Millisecond, func() int { return 1 })
+	r.Debounce()
+	r.Cancel()
+
+	ctx, cancel := context.WithTimeout(
+		context.Background(), 30*time.Millisecond,
+	)
+	defer cancel()
+
+	v, err := r.Next(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 0, v)
+}
@@ -0,0 +1,136 @@
+package debounce
+
+import (
+	"sync"
+	"time"
+)
+
+// BackoffFunc returns the delay before retrying a failed invocation, given the
+// number of attempts which have failed so far, starting at 1.
+type BackoffFunc func(attempt int) time.Duration
+
+// NewRetry returns a debounced function like NewWithError, but when f returns
+// an error, the invocation is retried after the delay returned by backoff,
+// until f succeeds, or maxAttempts attempts have failed. The error of the last
+// attempt is then passed to the hook set with WithOnError, or discarded if no
+// hook is set. A maxAttempts of 1 or less disables retries.
+//
+// Calls made while a retry is pending are coalesced into it, like calls made
+// while any other invocation is pending. With WithRetryResetOnCall, they also
+// reset the number of failed attempts, so the retry gets the full number of
+// attempts again.
+//
+// The returned cancel function can be used to cancel any pending invocation or
+// retry of f, but is not required to be called, so can be ignored if not
+// needed.
+//
+// Both debounced and cancel functions are safe for concurrent use in
+// goroutines, and can both be called multiple times.
+//
+// Optional behavior can be configured by passing one or more RetryOption
+// values, which include all Option values.
+func NewRetry(
+	wait time.Duration,
+	f func() error,
+	backoff BackoffFunc,
+	maxAttempts int,
+	opts ...RetryOption,
+) (debounced func(), cancel func()) {
+	o := retryOptions{}
+	for _, opt := range opts {
+		opt.applyRetry(&o)
+	}
+
+	rt := &retry{f: f, backoff: backoff, maxAttempts: maxAttempts}
+	rt.d = newDebouncer(wait, nil, append(o.opts, WithReschedule(rt.invoke)))
+
+	debounced = rt.d.Debounce
+	if o.resetOnCall {
+		debounced = func() {
+			rt.reset()
+			rt.d.Debounce()
+		}
+	}
+
+	cancelDebouncer := rt.d.cancelFunc()
+
+	return debounced, func() {
+		cancelDebouncer()
+		rt.reset()
+	}
+}
+
+// RetryOption configures optional behavior of a debounced function returned by
+// NewRetry. Any Option is a RetryOption, and so is WithRetryResetOnCall.
+type RetryOption interface {
+	applyRetry(o *retryOptions)
+}
+
+type retryOptions struct {
+	opts        []Option
+	resetOnCall bool
+}
+
+type retryOption func(o *retryOptions)
+
+func (f retryOption) applyRetry(o *retryOptions) {
+	f(o)
+}
+
+func (f Option) applyRetry(o *retryOptions) {
+	o.opts = append(o.opts, f)
+}
+
+// WithRetryResetOnCall makes calls to a function returned by NewRetry reset
+// the number of failed attempts, so a retry which calls have been coalesced
+// into gets the full number of attempts again.
+func WithRetryResetOnCall() RetryOption {
+	return retryOption(func(o *retryOptions) {
+		o.resetOnCall = true
+	})
+}
+
+// retry keeps track of the failed attempts of a function returned by NewRetry.
+type retry struct {
+	d           *Debouncer
+	f           func() error
+	backoff     BackoffFunc
+	maxAttempts int
+
+	mux    sync.Mutex
+	failed int
+}
+
+func (rt *retry) invoke(r Rescheduler) {
+	err := rt.f()
+
+	rt.mux.Lock()
+	if err == nil {
+		rt.failed = 0
+		rt.mux.Unlock()
+
+		return
+	}
+
+	rt.failed++
+	if attempt := rt.failed; attempt < rt.maxAttempts {
+		rt.mux.Unlock()
+		r.After(rt.backoff(attempt))
+
+		return
+	}
+
+	rt.failed = 0
+	rt.mux.Unlock()
+
+	if rt.d.opts.onError != nil {
+		rt.d.opts.onError(err)
+	}
+}
+
+func (rt *retry) reset() {
+	rt.mux.Lock()
+	defer rt.mux.Unlock()
+
+	rt.failed = 0
+}
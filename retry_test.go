@@ -0,0 +1,153 @@
+package debounce
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRetry(t *testing.T) {
+	t.Parallel()
+
+	errFailed := errors.New("failed")
+
+	tests := []struct {
+		name        string
+		maxAttempts int
+		opts        []RetryOption
+		failures    int
+		calls       []time.Duration
+		wantAt      []time.Duration
+		wantErrors  int
+	}{
+		{
+			name:        "succeeds first time",
+			maxAttempts: 3,
+			calls:       []time.Duration{0},
+			wantAt:      []time.Duration{10 * time.Millisecond},
+		},
+		{
+			name:        "succeeds after retries",
+			maxAttempts: 3,
+			failures:    2,
+			calls:       []time.Duration{0},
+			// backoff of 10ms per failed attempt
+			wantAt: []time.Duration{
+				10 * time.Millisecond,
+				20 * time.Millisecond,
+				40 * time.Millisecond,
+			},
+		},
+		{
+			name:        "gives up after maxAttempts",
+			maxAttempts: 3,
+			failures:    5,
+			calls:       []time.Duration{0},
+			wantAt: []time.Duration{
+				10 * time.Millisecond,
+				20 * time.Millisecond,
+				40 * time.Millisecond,
+			},
+			wantErrors: 1,
+		},
+		{
+			name:        "call during retry is coalesced",
+			maxAttempts: 2,
+			failures:    5,
+			calls:       []time.Duration{0, 15 * time.Millisecond},
+			wantAt: []time.Duration{
+				10 * time.Millisecond,
+				// wait from call at 15ms
+				25 * time.Millisecond,
+			},
+			wantErrors: 1,
+		},
+		{
+			name:        "call during retry resets attempts",
+			maxAttempts: 2,
+			opts:        []RetryOption{WithRetryResetOnCall()},
+			failures:    5,
+			calls:       []time.Duration{0, 15 * time.Millisecond},
+			wantAt: []time.Duration{
+				10 * time.Millisecond,
+				25 * time.Millisecond,
+				35 * time.Millisecond,
+			},
+			wantErrors: 1,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var mux sync.Mutex
+			var at []time.Duration
+			var errs []error
+			start := time.Now()
+
+			opts := append([]RetryOption{WithOnError(func(err error) {
+				mux.Lock()
+				defer mux.Unlock()
+				errs = append(errs, err)
+			})}, tt.opts...)
+			d, _ := NewRetry(10*time.Millisecond, func() error {
+				mux.Lock()
+				defer mux.Unlock()
+				at = append(at, time.Since(start))
+				if len(at) <= tt.failures {
+					return errFailed
+				}
+
+				return nil
+			}, func(attempt int) time.Duration {
+				return time.Duration(attempt) * 10 * time.Millisecond
+			}, tt.maxAttempts, opts...)
+
+			for _, delay := range tt.calls {
+				time.Sleep(time.Until(start.Add(delay)))
+				d()
+			}
+			time.Sleep(100 * time.Millisecond)
+
+			mux.Lock()
+			defer mux.Unlock()
+
+			if assert.Len(t, at, len(tt.wantAt)) {
+				for i, want := range tt.wantAt {
+					assert.InDelta(t, want, at[i], float64(4*time.Millisecond))
+				}
+			}
+			assert.Len(t, errs, tt.wantErrors)
+			for _, err := range errs {
+				assert.ErrorIs(t, err, errFailed)
+			}
+		})
+	}
+}
+
+func TestNewRetry_cancel(t *testing.T) {
+	t.Parallel()
+
+	var mux sync.Mutex
+	attempts := 0
+	d, cancel := NewRetry(10*time.Millisecond, func() error {
+		mux.Lock()
+		defer mux.Unlock()
+		attempts++
+
+		return errors.New("failed")
+	}, func(int) time.Duration { return 20 * time.Millisecond }, 5)
+
+	d()
+	time.Sleep(15 * time.Millisecond)
+	cancel()
+	time.Sleep(50 * time.Millisecond)
+
+	mux.Lock()
+	defer mux.Unlock()
+	assert.Equal(t, 1, attempts)
+}
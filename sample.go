@@ -0,0 +1,132 @@
+package debounce
+
+import (
+	"sync"
+	"time"
+)
+
+// NewSample returns a function which samples the values pushed to it, passing
+// the latest value to f once per interval for as long as values keep being
+// pushed. Once pushes stop, the last value is passed to f at the end of the
+// interval in which it was pushed, after which nothing happens until the next
+// push, so no timer keeps running while idle.
+//
+// Like a ticker, intervals are aligned to a fixed grid of points in time, which
+// starts when NewSample is called, so the times values are passed to f at do
+// not drift with the times they are pushed at. An interval of zero or less
+// passes values to f as soon as possible instead, with values pushed in the
+// meantime replacing the one not yet passed to f.
+//
+// Values are passed to f one at a time, in order. The returned stop function
+// passes any value not yet passed to f to it right away, and makes further
+// pushes have no effect. It is not required to be called, so can be ignored if
+// not needed.
+//
+// Of the Option values, only WithScheduler has an effect.
+//
+// Both push and stop functions are safe for concurrent use in goroutines, and
+// can both be called multiple times.
+func NewSample[T any](
+	interval time.Duration,
+	f func(value T),
+	opts ...Option,
+) (push func(value T), stop func()) {
+	s := &sampler[T]{f: f, interval: interval, start: time.Now()}
+	if sched := newOptions(opts).scheduler; sched != nil {
+		s.timer = sched.newTimer(s.tick)
+	} else {
+		s.timer = stoppedTimer(s.tick)
+	}
+
+	return s.push, s.stop
+}
+
+// sampler holds the latest value pushed to a function returned by NewSample,
+// along with its grid of intervals.
+type sampler[T any] struct {
+	f        func(value T)
+	interval time.Duration
+	start    time.Time
+	timer    timer
+
+	mux     sync.Mutex
+	value   T
+	pending bool
+	stopped bool
+
+	// callMux makes calls to f one at a time, in order.
+	callMux sync.Mutex
+}
+
+func (s *sampler[T]) push(value T) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if s.stopped {
+		return
+	}
+
+	s.value = value
+	if !s.pending {
+		s.pending = true
+		s.timer.Reset(s.nextTick())
+	}
+}
+
+// nextTick returns the time until the next point of the grid, rather than a
+// whole interval.
+func (s *sampler[T]) nextTick() time.Duration {
+	if s.interval <= 0 {
+		return 0
+	}
+	since := elapsed(s.start, time.Now())
+
+	return s.interval - since%s.interval
+}
+
+func (s *sampler[T]) stop() {
+	s.mux.Lock()
+	if s.stopped {
+		s.mux.Unlock()
+
+		return
+	}
+	s.stopped = true
+	s.timer.Stop()
+
+	s.emit()
+}
+
+// tick is called by the timer at the end of each interval in which values were
+// pushed, and passes the latest one to f.
+func (s *sampler[T]) tick() {
+	s.mux.Lock()
+	if s.stopped {
+		s.mux.Unlock()
+
+		return
+	}
+
+	s.emit()
+}
+
+// emit passes the pending value to f, if there is one. It must be called while
+// holding the lock, which it releases.
+func (s *sampler[T]) emit() {
+	value, pending := s.value, s.pending
+	var zero T
+	s.value = zero
+	s.pending = false
+
+	if !pending {
+		s.mux.Unlock()
+
+		return
+	}
+
+	s.callMux.Lock()
+	defer s.callMux.Unlock()
+	s.mux.Unlock()
+
+	s.f(value)
+}
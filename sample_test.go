@@ -0,0 +1,155 @@
+package debounce
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSample(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		interval   time.Duration
+		calls      []typedOp
+		wantValues map[time.Duration][]int
+	}{
+		{
+			name:     "continuous stream",
+			interval: 30 * time.Millisecond,
+			calls: []typedOp{
+				{delay: 5 * time.Millisecond, value: 1},
+				{delay: 15 * time.Millisecond, value: 2},
+				{delay: 25 * time.Millisecond, value: 3}, // sample 1
+				{delay: 35 * time.Millisecond, value: 4},
+				{delay: 45 * time.Millisecond, value: 5},
+				{delay: 55 * time.Millisecond, value: 6}, // sample 2
+				{delay: 65 * time.Millisecond, value: 7},
+				{delay: 75 * time.Millisecond, value: 8}, // final
+			},
+			wantValues: map[time.Duration][]int{
+				20 * time.Millisecond:  nil,
+				40 * time.Millisecond:  {3},
+				50 * time.Millisecond:  {3},
+				70 * time.Millisecond:  {3, 6},
+				80 * time.Millisecond:  {3, 6},
+				100 * time.Millisecond: {3, 6, 8},
+				150 * time.Millisecond: {3, 6, 8},
+			},
+		},
+		{
+			name:     "pause and resume",
+			interval: 30 * time.Millisecond,
+			calls: []typedOp{
+				{delay: 5 * time.Millisecond, value: 1},
+				{delay: 17 * time.Millisecond, value: 2}, // sample 1
+				{delay: 100 * time.Millisecond, value: 3},
+				{delay: 110 * time.Millisecond, value: 4}, // sample 2
+			},
+			wantValues: map[time.Duration][]int{
+				25 * time.Millisecond:  nil,
+				40 * time.Millisecond:  {2},
+				95 * time.Millisecond:  {2},
+				115 * time.Millisecond: {2},
+				// The grid started at 0ms, so the sample is at 120ms, not
+				// 130ms.
+				125 * time.Millisecond: {2, 4},
+				200 * time.Millisecond: {2, 4},
+			},
+		},
+		{
+			name:     "final trailing value",
+			interval: 30 * time.Millisecond,
+			calls: []typedOp{
+				{delay: 10 * time.Millisecond, value: 1},
+			},
+			wantValues: map[time.Duration][]int{
+				25 * time.Millisecond:  nil,
+				40 * time.Millisecond:  {1},
+				150 * time.Millisecond: {1},
+			},
+		},
+		{
+			name:     "stop flushes pending value",
+			interval: 30 * time.Millisecond,
+			calls: []typedOp{
+				{delay: 10 * time.Millisecond, value: 1},
+				{delay: 20 * time.Millisecond, cancel: true},
+				{delay: 25 * time.Millisecond, value: 2},
+			},
+			wantValues: map[time.Duration][]int{
+				15 * time.Millisecond:  nil,
+				30 * time.Millisecond:  {1},
+				150 * time.Millisecond: {1},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mux := sync.RWMutex{}
+
+			var got []int
+			push, stop := NewSample(tt.interval, func(value int) {
+				mux.Lock()
+				defer mux.Unlock()
+				got = append(got, value)
+			})
+
+			wg := sync.WaitGroup{}
+			for _, op := range tt.calls {
+				wg.Add(1)
+				go func(op typedOp) {
+					defer wg.Done()
+					time.Sleep(op.delay)
+					if op.cancel {
+						stop()
+					} else {
+						push(op.value)
+					}
+				}(op)
+			}
+
+			for delay, values := range tt.wantValues {
+				wg.Add(1)
+				go func(interval time.Duration, values []int) {
+					defer wg.Done()
+					time.Sleep(interval)
+
+					mux.RLock()
+					defer mux.RUnlock()
+					assert.Equal(t, values, got, "at %s", interval)
+				}(delay, values)
+			}
+
+			wg.Wait()
+		})
+	}
+}
+
+func TestNewSample_zeroInterval(t *testing.T) {
+	t.Parallel()
+
+	for _, interval := range []time.Duration{0, -time.Second} {
+		values := make(chan int, 10)
+		push, stop := NewSample(interval, func(v int) { values <- v })
+
+		push(1)
+		select {
+		case v := <-values:
+			assert.Equal(t, 1, v)
+		case <-time.After(time.Second):
+			t.Fatalf("value not passed with interval %s", interval)
+		}
+
+		push(2)
+		stop()
+		assert.Equal(t, 2, <-values)
+		assert.Len(t, values, 0)
+	}
+}
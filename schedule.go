@@ -0,0 +1,99 @@
+package debounce
+
+import "time"
+
+// Schedule restricts invocations of a callback function to allowed periods of
+// time, like outside business hours, as set with WithSchedule.
+type Schedule interface {
+	// Next returns the earliest time at or after t at which an invocation is
+	// allowed, which is t itself if an invocation is allowed at t. It is
+	// called while holding the debouncer's internal lock, and must not block.
+	Next(t time.Time) time.Time
+}
+
+// ScheduleFunc is an adapter to allow the use of ordinary functions as
+// Schedules.
+type ScheduleFunc func(t time.Time) time.Time
+
+// Next calls sf(t).
+func (sf ScheduleFunc) Next(t time.Time) time.Time {
+	return sf(t)
+}
+
+// TimeRange is a period of time within a day, on some days of the week.
+type TimeRange struct {
+	// Weekdays are the days the range starts on. All days are included if
+	// empty.
+	Weekdays []time.Weekday
+	// Start and End are the start and end of the range, as offsets from
+	// midnight. An End at or before Start ends the range on the next day.
+	Start, End time.Duration
+}
+
+// WeeklySchedule is a Schedule which allows invocations within any of its
+// time ranges, in its location, or in the local time zone if Location is nil.
+// A WeeklySchedule without any time ranges allows invocations at any time.
+type WeeklySchedule struct {
+	Ranges   []TimeRange
+	Location *time.Location
+}
+
+// Next returns t if t is within any of the time ranges, or otherwise the start
+// of the next time range.
+func (s WeeklySchedule) Next(t time.Time) time.Time {
+	if len(s.Ranges) == 0 {
+		return t
+	}
+
+	loc := s.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	year, month, date := t.In(loc).Date()
+
+	var next time.Time
+	// Start a day early, for ranges which started yesterday and end today.
+	for day := -1; day <= 7; day++ {
+		midnight := time.Date(year, month, date+day, 0, 0, 0, 0, loc)
+		for _, r := range s.Ranges {
+			if !r.onDay(midnight.Weekday()) {
+				continue
+			}
+
+			start := midnight.Add(r.Start)
+			end := midnight.Add(r.End)
+			if r.End <= r.Start {
+				end = end.Add(24 * time.Hour)
+			}
+
+			switch {
+			case !t.Before(start) && t.Before(end):
+				return t
+			case start.After(t) && (next.IsZero() || start.Before(next)):
+				next = start
+			}
+		}
+	}
+
+	if next.IsZero() {
+		// No range is on any day of the week.
+		return t
+	}
+
+	return next.In(t.Location())
+}
+
+// onDay reports if the range starts on the given day of the week.
+func (r TimeRange) onDay(day time.Weekday) bool {
+	if len(r.Weekdays) == 0 {
+		return true
+	}
+
+	for _, d := range r.Weekdays {
+		if d == day {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,154 @@
+package debounce
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWeeklySchedule_Next(t *testing.T) {
+	t.Parallel()
+
+	// Weekday evenings and nights, from 17:00 to 09:00 the next day, and all
+	// of Saturday.
+	s := WeeklySchedule{
+		Ranges: []TimeRange{
+			{
+				Weekdays: []time.Weekday{
+					time.Monday, time.Tuesday, time.Wednesday,
+					time.Thursday, time.Friday,
+				},
+				Start: 17 * time.Hour,
+				End:   9 * time.Hour,
+			},
+			{Weekdays: []time.Weekday{time.Saturday}},
+		},
+		Location: time.UTC,
+	}
+
+	// 2024-01-01 is a Monday.
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, 1, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name string
+		t    time.Time
+		want time.Time
+	}{
+		{name: "business hours", t: at(1, 12, 0), want: at(1, 17, 0)},
+		{name: "evening", t: at(1, 18, 30), want: at(1, 18, 30)},
+		{name: "window start", t: at(1, 17, 0), want: at(1, 17, 0)},
+		{name: "after midnight", t: at(2, 3, 0), want: at(2, 3, 0)},
+		{name: "window end", t: at(2, 9, 0), want: at(2, 17, 0)},
+		{name: "friday night", t: at(6, 8, 0), want: at(6, 8, 0)},
+		{name: "saturday", t: at(6, 12, 0), want: at(6, 12, 0)},
+		{name: "sunday", t: at(7, 12, 0), want: at(8, 17, 0)},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, s.Next(tt.t))
+		})
+	}
+
+	t.Run("no ranges", func(t *testing.T) {
+		t.Parallel()
+
+		now := at(1, 12, 0)
+		assert.Equal(t, now, WeeklySchedule{}.Next(now))
+	})
+
+	t.Run("location", func(t *testing.T) {
+		t.Parallel()
+
+		loc := time.FixedZone("UTC+2", 2*60*60)
+		s := WeeklySchedule{
+			Ranges:   []TimeRange{{Start: 17 * time.Hour, End: 18 * time.Hour}},
+			Location: loc,
+		}
+
+		// 15:00 UTC is 17:00 in loc.
+		assert.Equal(t, at(1, 15, 0), s.Next(at(1, 12, 0)))
+	})
+}
+
+func TestWithSchedule(t *testing.T) {
+	t.Parallel()
+
+	// The allowed window opens 30ms into the test, at 17:00 on a Monday.
+	windowStart := time.Date(2024, 1, 1, 17, 0, 0, 0, time.UTC)
+	schedule := WeeklySchedule{
+		Ranges:   []TimeRange{{Start: 17 * time.Hour, End: 18 * time.Hour}},
+		Location: time.UTC,
+	}
+	newClock := func() func() time.Time {
+		start := time.Now()
+		base := windowStart.Add(-30 * time.Millisecond)
+
+		return func() time.Time { return base.Add(time.Since(start)) }
+	}
+
+	t.Run("deferred to window start", func(t *testing.T) {
+		t.Parallel()
+
+		mux := sync.Mutex{}
+		var calls []int
+		var at []time.Time
+		clock := newClock()
+		d := newDebouncer(10*time.Millisecond, func(
+			_ context.Context, info InvokeInfo,
+		) {
+			mux.Lock()
+			defer mux.Unlock()
+			calls = append(calls, info.Calls)
+			at = append(at, clock())
+		}, []Option{WithSchedule(schedule)})
+		d.now = clock
+
+		// Due at 10ms, but deferred to 30ms, with calls coalesced meanwhile.
+		d.Debounce()
+		time.Sleep(15 * time.Millisecond)
+		d.Debounce()
+		time.Sleep(40 * time.Millisecond)
+
+		// Within the window, calls are debounced as usual.
+		d.Debounce()
+		time.Sleep(20 * time.Millisecond)
+
+		mux.Lock()
+		defer mux.Unlock()
+		require.Equal(t, []int{2, 1}, calls)
+		assert.False(t, at[0].Before(windowStart))
+		assert.Less(t, at[0].Sub(windowStart), 10*time.Millisecond)
+	})
+
+	t.Run("cancel discards deferred work", func(t *testing.T) {
+		t.Parallel()
+
+		mux := sync.Mutex{}
+		n := 0
+		d := NewDebouncer(5*time.Millisecond, func() {
+			mux.Lock()
+			defer mux.Unlock()
+			n++
+		}, WithSchedule(schedule))
+		d.now = newClock()
+
+		d.Debounce()
+		time.Sleep(15 * time.Millisecond)
+		assert.True(t, d.Pending())
+		d.Cancel()
+		time.Sleep(30 * time.Millisecond)
+
+		mux.Lock()
+		defer mux.Unlock()
+		assert.Equal(t, 0, n)
+	})
+}
@@ -0,0 +1,147 @@
+package debounce
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// Scheduler runs the timers of many Debouncers on a single runtime timer,
+// rather than each Debouncer owning several timers of its own. This reduces
+// memory use and timer churn when there are many Debouncers, like the
+// Debouncers of a Group with many keys.
+//
+// Debouncers use a Scheduler when created with WithScheduler. Their behavior is
+// the same as without one, and functions due at the same time still run on
+// goroutines of their own.
+//
+// A Scheduler is safe for concurrent use in goroutines, and does not need to be
+// stopped, as it holds no resources while none of its timers are running.
+type Scheduler struct {
+	mux    sync.Mutex
+	timers timerHeap
+	timer  *time.Timer
+	// expiry is the time the runtime timer is set to expire at, or the zero
+	// time if it is stopped.
+	expiry time.Time
+}
+
+// NewScheduler returns a new Scheduler.
+func NewScheduler() *Scheduler {
+	s := &Scheduler{}
+	s.timer = stoppedTimer(s.run)
+
+	return s
+}
+
+// newTimer returns a stopped timer which calls f once it expires.
+func (s *Scheduler) newTimer(f func()) *scheduledTimer {
+	return &scheduledTimer{s: s, f: f, index: -1}
+}
+
+// run calls the functions of all timers which have expired, and restarts the
+// runtime timer for the remaining ones.
+func (s *Scheduler) run() {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	now := time.Now()
+	for len(s.timers) > 0 && !s.timers[0].expiry.After(now) {
+		t := heap.Pop(&s.timers).(*scheduledTimer)
+		go t.f()
+	}
+
+	s.expiry = time.Time{}
+	s.arm()
+}
+
+// arm makes sure the runtime timer expires no later than the earliest timer.
+// Must be called while holding the lock.
+func (s *Scheduler) arm() {
+	if len(s.timers) == 0 {
+		return
+	}
+
+	// Expiring early is harmless, as run restarts the runtime timer for
+	// timers which are not due yet.
+	next := s.timers[0].expiry
+	if s.expiry.IsZero() || next.Before(s.expiry) {
+		s.expiry = next
+		s.timer.Reset(elapsed(time.Now(), next))
+	}
+}
+
+// scheduledTimer is a timer run by a Scheduler.
+type scheduledTimer struct {
+	s      *Scheduler
+	f      func()
+	expiry time.Time
+	// index is the position of the timer in the Scheduler's heap, or -1 if the
+	// timer is stopped.
+	index int
+}
+
+// Reset changes the timer to expire after duration d, and reports if the timer
+// had been active.
+func (t *scheduledTimer) Reset(d time.Duration) bool {
+	t.s.mux.Lock()
+	defer t.s.mux.Unlock()
+
+	t.expiry = time.Now().Add(d)
+	active := t.index >= 0
+	if active {
+		heap.Fix(&t.s.timers, t.index)
+	} else {
+		heap.Push(&t.s.timers, t)
+	}
+	t.s.arm()
+
+	return active
+}
+
+// Stop prevents the timer from firing, and reports if the timer had been
+// active.
+func (t *scheduledTimer) Stop() bool {
+	t.s.mux.Lock()
+	defer t.s.mux.Unlock()
+
+	if t.index < 0 {
+		return false
+	}
+	heap.Remove(&t.s.timers, t.index)
+
+	return true
+}
+
+// timerHeap is a min-heap of timers ordered by expiry time, implementing
+// heap.Interface.
+type timerHeap []*scheduledTimer
+
+func (h timerHeap) Len() int { return len(h) }
+
+func (h timerHeap) Less(i, j int) bool {
+	return h[i].expiry.Before(h[j].expiry)
+}
+
+func (h timerHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *timerHeap) Push(x interface{}) {
+	t := x.(*scheduledTimer)
+	t.index = len(*h)
+	*h = append(*h, t)
+}
+
+func (h *timerHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	t := old[n-1]
+	old[n-1] = nil
+	t.index = -1
+	*h = old[:n-1]
+
+	return t
+}
@@ -0,0 +1,140 @@
+package debounce
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// timerBackends are the ways a Debouncer can run its timers, which scenario
+// tests are run against.
+var timerBackends = []struct {
+	name string
+	opts []Option
+}{
+	{name: "timers"},
+	{name: "scheduler", opts: []Option{WithScheduler(NewScheduler())}},
+}
+
+func TestScheduler(t *testing.T) {
+	t.Parallel()
+
+	s := NewScheduler()
+
+	mux := sync.Mutex{}
+	var got []string
+	timer := func(name string) *scheduledTimer {
+		return s.newTimer(func() {
+			mux.Lock()
+			defer mux.Unlock()
+			got = append(got, name)
+		})
+	}
+
+	a := timer("a")
+	b := timer("b")
+	c := timer("c")
+
+	assert.False(t, a.Reset(30*time.Millisecond))
+	assert.False(t, b.Reset(10*time.Millisecond))
+	assert.False(t, c.Reset(20*time.Millisecond))
+	// Postpone b past a, and stop c.
+	assert.True(t, b.Reset(40*time.Millisecond))
+	assert.True(t, c.Stop())
+	assert.False(t, c.Stop())
+
+	time.Sleep(35 * time.Millisecond)
+	mux.Lock()
+	assert.Equal(t, []string{"a"}, got)
+	mux.Unlock()
+	assert.False(t, a.Stop())
+
+	time.Sleep(20 * time.Millisecond)
+	mux.Lock()
+	assert.Equal(t, []string{"a", "b"}, got)
+	mux.Unlock()
+
+	// A stopped timer can be restarted.
+	assert.False(t, c.Reset(5*time.Millisecond))
+	time.Sleep(20 * time.Millisecond)
+	mux.Lock()
+	assert.Equal(t, []string{"a", "b", "c"}, got)
+	mux.Unlock()
+}
+
+func TestWithScheduler(t *testing.T) {
+	t.Parallel()
+
+	s := NewScheduler()
+
+	var n int64
+	g := NewGroup(10*time.Millisecond, func(string) {
+		atomic.AddInt64(&n, 1)
+	}, WithScheduler(s), WithGroupMaxIdle(20*time.Millisecond))
+
+	for i := 0; i < 100; i++ {
+		g.Debounce(fmt.Sprint(i))
+		g.Debounce(fmt.Sprint(i))
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, int64(100), atomic.LoadInt64(&n))
+
+	time.Sleep(40 * time.Millisecond)
+	assert.Equal(t, 0, g.Len())
+}
+
+// runtimeTimers returns the number of runtime timers owned by d.
+func runtimeTimers(d *Debouncer) int {
+	n := 0
+	for _, t := range []timer{
+		d.timer, d.maxTimer, d.deadlineTimer, d.deferTimer, d.idleTimer,
+		d.evictTimer,
+	} {
+		if g, ok := t.(*guardedTimer); ok && g != nil {
+			t = g.timer
+		}
+		if _, ok := t.(*time.Timer); ok {
+			n++
+		}
+	}
+
+	return n
+}
+
+func BenchmarkWithScheduler(b *testing.B) {
+	for _, n := range []int{10000, 100000} {
+		for _, backend := range timerBackends {
+			b.Run(fmt.Sprintf("%d/%s", n, backend.name), func(b *testing.B) {
+				b.ReportAllocs()
+
+				timers := 0
+				for i := 0; i < b.N; i++ {
+					ds := make([]*Debouncer, n)
+					for j := range ds {
+						ds[j] = NewDebouncer(time.Minute, func() {},
+							backend.opts...,
+						)
+						ds[j].Debounce()
+					}
+
+					timers = 0
+					for _, d := range ds {
+						timers += runtimeTimers(d)
+						d.Close()
+					}
+				}
+				if backend.opts != nil {
+					// All Debouncers share the Scheduler's timer.
+					timers = 1
+				}
+
+				b.ReportMetric(float64(timers), "timers")
+			})
+		}
+	}
+}
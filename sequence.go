@@ -0,0 +1,159 @@
+package debounce
+
+import (
+	"sync"
+	"time"
+)
+
+// Sequence debounces calls to its Debounce method like a Debouncer, but rather
+// than invoking its callback function right away, each invocation is queued,
+// and queued invocations are executed strictly one at a time, in the order
+// they were queued.
+//
+// With WithSequenceCollapse, an invocation which is queued while another one
+// is still waiting to be started is collapsed into it.
+//
+// All methods are safe for concurrent use in goroutines.
+type Sequence struct {
+	d *Debouncer
+	f func()
+
+	// collapse is true if WithSequenceCollapse is used.
+	collapse bool
+
+	mux     sync.Mutex
+	queued  int
+	running bool
+	closed  bool
+	wg      sync.WaitGroup
+}
+
+// NewSequence returns a new Sequence which queues an invocation of f once wait
+// time has elapsed since the last call to its Debounce method.
+//
+// Optional behavior can be configured by passing one or more SequenceOption
+// values, which include all Option values.
+func NewSequence(
+	wait time.Duration,
+	f func(),
+	opts ...SequenceOption,
+) *Sequence {
+	o := sequenceOptions{}
+	for _, opt := range opts {
+		opt.applySequence(&o)
+	}
+
+	s := &Sequence{f: f, collapse: o.collapse}
+	s.d = NewDebouncer(wait, s.enqueue, o.opts...)
+
+	return s
+}
+
+// SequenceOption configures optional behavior of a Sequence. Any Option is a
+// SequenceOption, and so is WithSequenceCollapse.
+type SequenceOption interface {
+	applySequence(o *sequenceOptions)
+}
+
+type sequenceOptions struct {
+	opts     []Option
+	collapse bool
+}
+
+type sequenceOption func(o *sequenceOptions)
+
+func (f sequenceOption) applySequence(o *sequenceOptions) {
+	f(o)
+}
+
+func (f Option) applySequence(o *sequenceOptions) {
+	o.opts = append(o.opts, f)
+}
+
+// WithSequenceCollapse makes a Sequence collapse an invocation which is queued
+// while another one is still waiting to be started into the waiting one, so
+// at most one invocation is ever waiting.
+func WithSequenceCollapse() SequenceOption {
+	return sequenceOption(func(o *sequenceOptions) {
+		o.collapse = true
+	})
+}
+
+// Debounce schedules an invocation of the callback function, postponing any
+// already pending invocation until wait time has elapsed since this call.
+//
+// Calling Debounce after Close has no effect.
+func (s *Sequence) Debounce() {
+	s.d.add(nil)
+}
+
+// Cancel cancels any pending invocation which has not been queued yet.
+// Invocations which have already been queued are left alone.
+func (s *Sequence) Cancel() {
+	s.d.Cancel()
+}
+
+// Len returns the number of queued invocations which have not been started
+// yet.
+func (s *Sequence) Len() int {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	return s.queued
+}
+
+// Close cancels any pending invocation which has not been queued yet, and
+// blocks until all queued invocations have been executed. It returns
+// ErrTimeout if queueing an invocation did not complete within the grace period
+// set with WithCloseTimeout. Further calls to Debounce have no effect. Calling
+// Close more than once has no further effect.
+func (s *Sequence) Close() error {
+	err := s.d.Close()
+
+	s.mux.Lock()
+	s.closed = true
+	s.mux.Unlock()
+
+	s.wg.Wait()
+
+	return err
+}
+
+// enqueue queues an invocation, and starts executing queued invocations if
+// that is not already happening.
+func (s *Sequence) enqueue() {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	if s.queued == 0 || !s.collapse {
+		s.queued++
+	}
+	if !s.running {
+		s.running = true
+		s.wg.Add(1)
+		go s.run()
+	}
+}
+
+// run executes queued invocations until there are none left.
+func (s *Sequence) run() {
+	defer s.wg.Done()
+
+	for {
+		s.mux.Lock()
+		if s.queued == 0 {
+			s.running = false
+			s.mux.Unlock()
+
+			return
+		}
+		s.queued--
+		s.mux.Unlock()
+
+		s.f()
+	}
+}
@@ -0,0 +1,74 @@
+package debounce
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSequence(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		opts      []SequenceOption
+		wantLen   int
+		wantCalls int
+	}{
+		{
+			name:      "queue",
+			wantLen:   3,
+			wantCalls: 4,
+		},
+		{
+			name:      "collapse",
+			opts:      []SequenceOption{WithSequenceCollapse()},
+			wantLen:   1,
+			wantCalls: 2,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mux := sync.Mutex{}
+			var events []string
+			s := NewSequence(5*time.Millisecond, func() {
+				mux.Lock()
+				events = append(events, "start")
+				mux.Unlock()
+
+				time.Sleep(40 * time.Millisecond)
+
+				mux.Lock()
+				events = append(events, "end")
+				mux.Unlock()
+			}, tt.opts...)
+
+			// Four invocations, 10ms apart, while each takes 40ms.
+			for i := 0; i < 4; i++ {
+				s.Debounce()
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			// The first invocation is running, the next ones are queued.
+			assert.Equal(t, tt.wantLen, s.Len())
+
+			// Close waits for queued invocations to complete.
+			assert.NoError(t, s.Close())
+			assert.Equal(t, 0, s.Len())
+
+			want := make([]string, 0, tt.wantCalls*2)
+			for i := 0; i < tt.wantCalls; i++ {
+				want = append(want, "start", "end")
+			}
+
+			mux.Lock()
+			defer mux.Unlock()
+			assert.Equal(t, want, events)
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package debounce
+
+import (
+	"context"
+	"time"
+)
+
+// NewSet returns a debounced function like NewBatch, but which passes f the
+// distinct values of all calls, in the order they were first added. Each
+// invocation of f receives a new slice, which f is free to keep or modify,
+// while calls made afterwards start a new set.
+//
+// The maximum number of values per set can be limited with WithMaxBatchSize.
+//
+// The returned cancel function can be used to cancel any pending invocation of
+// f, discarding the values collected for it, but is not required to be called,
+// so can be ignored if not needed.
+//
+// Both add and cancel functions are safe for concurrent use in goroutines, and
+// can both be called multiple times.
+func NewSet[T comparable](
+	wait time.Duration,
+	f func(values []T),
+	opts ...Option,
+) (add func(value T), cancel func()) {
+	d := newSet(wait, f, opts)
+
+	return func(value T) { d.add(setEntry[T]{value: value}) }, d.cancelFunc()
+}
+
+// SetDebouncer collects the distinct values passed to its Add method, like the
+// debounced function returned by NewSet, and gives access to the pending set,
+// for example to report on it, or to flush it on demand.
+//
+// All methods are safe for concurrent use in goroutines. Each distinct value
+// of a set is passed to the callback function exactly once, unless it is
+// discarded by Cancel or Close, whatever triggers the invocation.
+type SetDebouncer[T comparable] struct {
+	d *Debouncer
+}
+
+// NewSetDebouncer returns a new SetDebouncer, which passes the distinct values
+// added to it to f like NewSet.
+//
+// Optional behavior can be configured by passing one or more Option values.
+func NewSetDebouncer[T comparable](
+	wait time.Duration,
+	f func(values []T),
+	opts ...Option,
+) *SetDebouncer[T] {
+	return &SetDebouncer[T]{d: newSet(wait, f, opts)}
+}
+
+// Add adds value to the pending set, unless it is already in it.
+func (s *SetDebouncer[T]) Add(value T) {
+	s.d.add(setEntry[T]{value: value})
+}
+
+// Len returns the number of distinct values in the pending set.
+func (s *SetDebouncer[T]) Len() int {
+	n := 0
+	s.d.peek(func(value interface{}) {
+		n = len(toSet[T](value).values)
+	})
+
+	return n
+}
+
+// Peek returns a copy of the values in the pending set, in the order they were
+// first added, or nil if there are none.
+func (s *SetDebouncer[T]) Peek() []T {
+	var values []T
+	s.d.peek(func(value interface{}) {
+		values = append(values, toSet[T](value).values...)
+	})
+
+	return values
+}
+
+// Flush passes the pending set to the callback function right away, like
+// Debouncer.Flush. It has no effect if no values are pending, in which case it
+// returns ErrNothingPending.
+func (s *SetDebouncer[T]) Flush() error {
+	return s.d.Flush()
+}
+
+// Cancel discards the pending set.
+func (s *SetDebouncer[T]) Cancel() {
+	s.d.Cancel()
+}
+
+// Close stops the SetDebouncer like Debouncer.Close, discarding the pending
+// set unless WithCloseTimeout is used. Further calls to Add have no effect.
+func (s *SetDebouncer[T]) Close() error {
+	return s.d.Close()
+}
+
+func newSet[T comparable](
+	wait time.Duration,
+	f func(values []T),
+	opts []Option,
+) *Debouncer {
+	d := newDebouncer(wait, func(_ context.Context, info InvokeInfo) {
+		f(toSet[T](info.value).values)
+	}, opts)
+	d.combine = func(acc, next interface{}) interface{} {
+		s := toSet[T](acc)
+		for _, v := range toSet[T](next).values {
+			s.add(v)
+		}
+
+		return s
+	}
+	if n := d.opts.maxBatchSize; n > 0 {
+		d.full = func(value interface{}) bool {
+			return len(toSet[T](value).values) >= n
+		}
+	}
+
+	return d
+}
+
+// valueSet is the set of values collected by a function returned by NewSet.
+type valueSet[T comparable] struct {
+	seen   map[T]struct{}
+	values []T
+}
+
+func (s *valueSet[T]) add(value T) {
+	if _, ok := s.seen[value]; ok {
+		return
+	}
+	s.seen[value] = struct{}{}
+	s.values = append(s.values, value)
+}
+
+// setEntry is the value of a single call to a function returned by NewSet,
+// which saves allocating a set for calls which are not coalesced.
+type setEntry[T comparable] struct {
+	value T
+}
+
+// toSet returns value as a set, which is either value itself, or a new set
+// holding value if it is a single setEntry.
+func toSet[T comparable](value interface{}) *valueSet[T] {
+	switch value := value.(type) {
+	case *valueSet[T]:
+		return value
+	case setEntry[T]:
+		return &valueSet[T]{
+			seen:   map[T]struct{}{value.value: {}},
+			values: []T{value.value},
+		}
+	default:
+		return &valueSet[T]{seen: map[T]struct{}{}}
+	}
+}
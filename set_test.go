@@ -0,0 +1,165 @@
+package debounce
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSet(t *testing.T) {
+	t.Parallel()
+
+	t.Run("distinct values in insertion order", func(t *testing.T) {
+		t.Parallel()
+
+		got := make(chan []string, 2)
+		add, _ := NewSet(20*time.Millisecond, func(values []string) {
+			got <- values
+		})
+
+		add("b")
+		add("a")
+		add("b")
+		add("c")
+		add("a")
+		assert.Equal(t, []string{"b", "a", "c"}, <-got)
+
+		add("a")
+		assert.Equal(t, []string{"a"}, <-got)
+	})
+
+	t.Run("concurrent duplicates", func(t *testing.T) {
+		t.Parallel()
+
+		mux := sync.Mutex{}
+		var got [][]int
+		add, _ := NewSet(10*time.Millisecond, func(values []int) {
+			mux.Lock()
+			defer mux.Unlock()
+			got = append(got, values)
+		}, WithMaxWait(15*time.Millisecond))
+
+		wg := sync.WaitGroup{}
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 200; j++ {
+					add(j % 10)
+					if j%20 == 0 {
+						time.Sleep(time.Millisecond)
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		time.Sleep(30 * time.Millisecond)
+
+		mux.Lock()
+		defer mux.Unlock()
+		require.NotEmpty(t, got)
+		seen := map[int]bool{}
+		for _, values := range got {
+			assert.LessOrEqual(t, len(values), 10)
+			batch := map[int]bool{}
+			for _, v := range values {
+				assert.False(t, batch[v], "duplicate value %d", v)
+				batch[v] = true
+				seen[v] = true
+			}
+		}
+		assert.Len(t, seen, 10)
+	})
+
+	t.Run("max wait", func(t *testing.T) {
+		t.Parallel()
+
+		mux := sync.Mutex{}
+		var got [][]int
+		add, _ := NewSet(20*time.Millisecond, func(values []int) {
+			mux.Lock()
+			defer mux.Unlock()
+			got = append(got, values)
+		}, WithMaxWait(45*time.Millisecond))
+
+		for i := 0; i < 6; i++ {
+			add(i % 2)
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		mux.Lock()
+		defer mux.Unlock()
+		require.Len(t, got, 1)
+		assert.Equal(t, []int{0, 1}, got[0])
+	})
+
+	t.Run("max size", func(t *testing.T) {
+		t.Parallel()
+
+		got := make(chan []int, 3)
+		add, _ := NewSet(time.Minute, func(values []int) {
+			got <- values
+		}, WithMaxBatchSize(2))
+
+		add(1)
+		add(1)
+		add(2)
+		add(3)
+		add(3)
+		add(4)
+
+		assert.ElementsMatch(t, [][]int{{1, 2}, {3, 4}}, [][]int{<-got, <-got})
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		t.Parallel()
+
+		got := make(chan []int, 2)
+		add, cancel := NewSet(10*time.Millisecond, func(values []int) {
+			got <- values
+		})
+
+		add(1)
+		cancel()
+		add(2)
+		add(2)
+		assert.Equal(t, []int{2}, <-got)
+	})
+}
+
+func TestSetDebouncer(t *testing.T) {
+	t.Parallel()
+
+	sets := make(chan []string, 4)
+	s := NewSetDebouncer(time.Minute, func(values []string) {
+		sets <- values
+	})
+
+	assert.Equal(t, 0, s.Len())
+	assert.Nil(t, s.Peek())
+	assert.ErrorIs(t, s.Flush(), ErrNothingPending)
+
+	s.Add("a")
+	s.Add("b")
+	s.Add("a")
+	assert.Equal(t, []string{"a", "b"}, s.Peek())
+	assert.Equal(t, 2, s.Len())
+
+	require.NoError(t, s.Flush())
+	assert.Equal(t, []string{"a", "b"}, <-sets)
+	assert.Equal(t, 0, s.Len())
+
+	// A value flushed before can be added to the next set.
+	s.Add("a")
+	assert.Equal(t, []string{"a"}, s.Peek())
+	s.Cancel()
+	assert.Nil(t, s.Peek())
+
+	require.NoError(t, s.Close())
+	s.Add("c")
+	assert.ErrorIs(t, s.Flush(), ErrClosed)
+	assert.Len(t, sets, 0)
+}
@@ -0,0 +1,31 @@
+package debounce
+
+import "time"
+
+// NewSignal returns a debounced function like New, but rather than invoking a
+// callback function, it sends on the returned signal channel, which suits code
+// driven by a select loop.
+//
+// The signal channel has a buffer of one. A signal which is due while the
+// previous one has not been received yet is coalesced into it, so at most one
+// signal is ever pending. The channel is never closed.
+//
+// The returned cancel function can be used to cancel any pending signal, but
+// is not required to be called, so can be ignored if not needed.
+//
+// Both debounced and cancel functions are safe for concurrent use in
+// goroutines, and can both be called multiple times.
+func NewSignal(
+	wait time.Duration,
+	opts ...Option,
+) (debounced func(), signal <-chan struct{}, cancel func()) {
+	ch := make(chan struct{}, 1)
+	d := NewDebouncer(wait, func() {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}, opts...)
+
+	return d.Debounce, ch, d.cancelFunc()
+}
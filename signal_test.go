@@ -0,0 +1,157 @@
+package debounce
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSignal(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		wait        time.Duration
+		opts        []Option
+		calls       []testOp
+		wantSignals map[time.Duration]int
+	}{
+		{
+			name: "many calls, one cancel, two signals",
+			wait: 20 * time.Millisecond,
+			calls: []testOp{
+				{delay: 5 * time.Millisecond},
+				{delay: 5 * time.Millisecond},
+				{delay: 10 * time.Millisecond}, // signal 1
+				{delay: 35 * time.Millisecond},
+				{delay: 40 * time.Millisecond},
+				{delay: 50 * time.Millisecond, cancel: true},
+				{delay: 80 * time.Millisecond},
+				{delay: 90 * time.Millisecond},
+				{delay: 100 * time.Millisecond}, // signal 2
+			},
+			wantSignals: map[time.Duration]int{
+				25 * time.Millisecond: 0,
+				// from call at 10ms (+20ms wait = 30ms)
+				35 * time.Millisecond:  1,
+				115 * time.Millisecond: 1,
+				// call at 100ms (+20ms wait = 120ms)
+				125 * time.Millisecond: 2,
+				150 * time.Millisecond: 2,
+			},
+		},
+		{
+			name: "leading and trailing",
+			wait: 20 * time.Millisecond,
+			opts: []Option{WithBurstPassThrough(1)},
+			calls: []testOp{
+				{delay: 10 * time.Millisecond}, // leading
+				{delay: 15 * time.Millisecond},
+				{delay: 20 * time.Millisecond}, // trailing
+			},
+			wantSignals: map[time.Duration]int{
+				5 * time.Millisecond:  0,
+				15 * time.Millisecond: 1,
+				35 * time.Millisecond: 1,
+				// from call at 20ms (+20ms wait = 40ms)
+				45 * time.Millisecond:  2,
+				150 * time.Millisecond: 2,
+			},
+		},
+		{
+			name: "until right before maxWait",
+			wait: 20 * time.Millisecond,
+			opts: []Option{WithMaxWait(50 * time.Millisecond)},
+			calls: []testOp{
+				{delay: 0 * time.Millisecond},
+				{delay: 10 * time.Millisecond},
+				{delay: 20 * time.Millisecond},
+				{delay: 30 * time.Millisecond},
+				{delay: 40 * time.Millisecond},
+				{delay: 60 * time.Millisecond},
+			},
+			wantSignals: map[time.Duration]int{
+				45 * time.Millisecond: 0,
+				// tick over at 50ms via maxWait
+				55 * time.Millisecond: 1,
+				// from call at 60ms (+20ms wait = 80ms)
+				85 * time.Millisecond:  2,
+				150 * time.Millisecond: 2,
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mux := sync.RWMutex{}
+
+			n := 0
+			d, signal, c := NewSignal(tt.wait, tt.opts...)
+
+			done := make(chan struct{})
+			defer close(done)
+			go func() {
+				for {
+					select {
+					case <-done:
+						return
+					case <-signal:
+						mux.Lock()
+						n++
+						mux.Unlock()
+					}
+				}
+			}()
+
+			wg := sync.WaitGroup{}
+			for _, op := range tt.calls {
+				wg.Add(1)
+				go func(delay time.Duration, cancel bool) {
+					defer wg.Done()
+					time.Sleep(delay)
+					if cancel {
+						c()
+					} else {
+						d()
+					}
+				}(op.delay, op.cancel)
+			}
+
+			for delay, count := range tt.wantSignals {
+				wg.Add(1)
+				go func(interval time.Duration, count int) {
+					defer wg.Done()
+					time.Sleep(interval)
+
+					mux.RLock()
+					defer mux.RUnlock()
+					assert.Equal(t, count, n, "at %s", interval)
+				}(delay, count)
+			}
+
+			wg.Wait()
+		})
+	}
+}
+
+func TestNewSignal_coalesced(t *testing.T) {
+	t.Parallel()
+
+	d, signal, _ := NewSignal(0, WithZeroWaitSync())
+
+	// Nothing receives, so signals are coalesced.
+	d()
+	d()
+	d()
+
+	<-signal
+	select {
+	case <-signal:
+		t.Fatal("signal not coalesced")
+	case <-time.After(10 * time.Millisecond):
+	}
+}
@@ -0,0 +1,126 @@
+package debounce
+
+import (
+	"sync"
+	"time"
+)
+
+// NewState returns a function to set a boolean state which is prone to flap,
+// like a contact or a health check, and which calls f with the new state once
+// it has held for riseWait after changing to true, or for fallWait after
+// changing to false. Setting the state back before then cancels the pending
+// change, without calling f.
+//
+// The state starts out as false, so f is not called until the state has been
+// set to true for riseWait. Calls to f are made in the order the changes took
+// effect, one at a time.
+//
+// The returned cancel function can be used to cancel any pending change, but is
+// not required to be called, so can be ignored if not needed.
+//
+// Both set and cancel functions are safe for concurrent use in goroutines, and
+// can both be called multiple times.
+func NewState(
+	riseWait, fallWait time.Duration,
+	f func(state bool),
+	opts ...Option,
+) (set func(state bool), cancel func()) {
+	s := &state{f: f}
+	s.rise = newTyped(riseWait, func(gen uint64) { s.settle(true, gen) }, opts)
+	s.fall = newTyped(fallWait, func(gen uint64) { s.settle(false, gen) }, opts)
+
+	return s.set, s.cancel
+}
+
+// state keeps track of the stable and pending state of a function returned by
+// NewState.
+type state struct {
+	f    func(state bool)
+	rise *Debouncer
+	fall *Debouncer
+
+	// setMux makes changes one at a time, so they reach rise and fall in the
+	// order they were made.
+	setMux sync.Mutex
+
+	// mux guards stable, pending and gen.
+	mux     sync.Mutex
+	stable  bool
+	pending bool
+	// gen identifies the pending change, so a change which has been canceled
+	// while already being invoked is ignored.
+	gen uint64
+
+	// callMux makes calls to f one at a time, in order.
+	callMux sync.Mutex
+}
+
+func (s *state) set(value bool) {
+	s.setMux.Lock()
+	defer s.setMux.Unlock()
+
+	s.mux.Lock()
+	switch {
+	case value == s.stable:
+		if s.pending {
+			s.reset()
+		}
+		s.mux.Unlock()
+
+		return
+	case s.pending:
+		// The change is already pending, and is not postponed.
+		s.mux.Unlock()
+
+		return
+	}
+	s.pending = true
+	s.gen++
+	gen := s.gen
+	s.mux.Unlock()
+
+	// The change is made without holding the lock, as f may be called right
+	// away without a wait time.
+	if value {
+		s.rise.add(gen)
+	} else {
+		s.fall.add(gen)
+	}
+}
+
+func (s *state) cancel() {
+	s.setMux.Lock()
+	defer s.setMux.Unlock()
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.reset()
+}
+
+// reset cancels any pending change. Must be called while holding the lock.
+func (s *state) reset() {
+	s.pending = false
+	s.gen++
+	s.rise.Cancel()
+	s.fall.Cancel()
+}
+
+// settle makes value the stable state and calls f, unless the change of gen
+// has been canceled.
+func (s *state) settle(value bool, gen uint64) {
+	s.mux.Lock()
+	if !s.pending || gen != s.gen {
+		s.mux.Unlock()
+
+		return
+	}
+	s.stable = value
+	s.pending = false
+
+	s.callMux.Lock()
+	defer s.callMux.Unlock()
+	s.mux.Unlock()
+
+	s.f(value)
+}
@@ -0,0 +1,150 @@
+package debounce
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stateOp struct {
+	delay time.Duration
+	state bool
+}
+
+func TestNewState(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		riseWait time.Duration
+		fallWait time.Duration
+		sets     []stateOp
+		want     map[time.Duration][]bool
+	}{
+		{
+			name:     "initial state is false",
+			riseWait: 20 * time.Millisecond,
+			fallWait: 20 * time.Millisecond,
+			sets: []stateOp{
+				{delay: 0, state: false},
+				{delay: 10 * time.Millisecond, state: false},
+			},
+			want: map[time.Duration][]bool{
+				60 * time.Millisecond: nil,
+			},
+		},
+		{
+			name:     "rise after riseWait",
+			riseWait: 20 * time.Millisecond,
+			fallWait: 40 * time.Millisecond,
+			sets: []stateOp{
+				{delay: 0, state: true},
+				// repeated state does not postpone
+				{delay: 10 * time.Millisecond, state: true},
+			},
+			want: map[time.Duration][]bool{
+				15 * time.Millisecond: nil,
+				25 * time.Millisecond: {true},
+			},
+		},
+		{
+			name:     "flapping cancels pending change",
+			riseWait: 20 * time.Millisecond,
+			fallWait: 20 * time.Millisecond,
+			sets: []stateOp{
+				{delay: 0, state: true},
+				{delay: 10 * time.Millisecond, state: false},
+				{delay: 15 * time.Millisecond, state: true},
+				{delay: 25 * time.Millisecond, state: false},
+				{delay: 30 * time.Millisecond, state: true},
+			},
+			want: map[time.Duration][]bool{
+				45 * time.Millisecond: nil,
+				// from set at 30ms (+20ms wait = 50ms)
+				55 * time.Millisecond: {true},
+			},
+		},
+		{
+			name:     "asymmetric waits",
+			riseWait: 40 * time.Millisecond,
+			fallWait: 0,
+			sets: []stateOp{
+				{delay: 0, state: true},
+				{delay: 50 * time.Millisecond, state: false},
+				{delay: 55 * time.Millisecond, state: true},
+				{delay: 75 * time.Millisecond, state: false},
+			},
+			want: map[time.Duration][]bool{
+				35 * time.Millisecond: nil,
+				45 * time.Millisecond: {true},
+				// falls right away, while rising at 55ms is canceled at 75ms
+				// before it has held for 40ms
+				52 * time.Millisecond:  {true, false},
+				120 * time.Millisecond: {true, false},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mux := sync.RWMutex{}
+
+			var got []bool
+			set, _ := NewState(tt.riseWait, tt.fallWait, func(state bool) {
+				mux.Lock()
+				defer mux.Unlock()
+				got = append(got, state)
+			})
+
+			// Changes are made in order from a single goroutine, as the
+			// outcome depends on it.
+			wg := sync.WaitGroup{}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				start := time.Now()
+				for _, op := range tt.sets {
+					time.Sleep(time.Until(start.Add(op.delay)))
+					set(op.state)
+				}
+			}()
+
+			for delay, values := range tt.want {
+				wg.Add(1)
+				go func(interval time.Duration, values []bool) {
+					defer wg.Done()
+					time.Sleep(interval)
+
+					mux.RLock()
+					defer mux.RUnlock()
+					assert.Equal(t, values, got, "at %s", interval)
+				}(delay, values)
+			}
+
+			wg.Wait()
+		})
+	}
+}
+
+func TestNewState_cancel(t *testing.T) {
+	t.Parallel()
+
+	got := make(chan bool, 2)
+	set, cancel := NewState(10*time.Millisecond, 10*time.Millisecond,
+		func(state bool) { got <- state },
+	)
+
+	set(true)
+	cancel()
+	time.Sleep(20 * time.Millisecond)
+	assert.Empty(t, got)
+
+	set(true)
+	assert.True(t, <-got)
+	set(false)
+	assert.False(t, <-got)
+}
@@ -0,0 +1,48 @@
+package debounce
+
+// Stats holds counters describing the activity of a Debouncer.
+type Stats struct {
+	// Calls is the number of calls made to Debounce, including suppressed
+	// calls.
+	Calls int
+	// Suppressed is the number of calls which were ignored, for example due to
+	// WithPredicate.
+	Suppressed int
+	// Invocations is the number of times the callback function was invoked.
+	Invocations int
+	// Skipped is the number of invocations which were skipped due to
+	// WithInvokeCondition or WithDropCanceledCalls.
+	Skipped int
+	// Dropped is the number of values which were discarded to stay within the
+	// limit set with WithMaxPending.
+	Dropped int
+}
+
+// SuppressReason describes why a call or invocation was suppressed.
+type SuppressReason int
+
+const (
+	// SuppressPredicate indicates a call was ignored as the predicate set with
+	// WithPredicate returned false.
+	SuppressPredicate SuppressReason = iota + 1
+	// SuppressInvokeCondition indicates an invocation was skipped as the
+	// condition set with WithInvokeCondition returned false.
+	SuppressInvokeCondition
+	// SuppressCanceled indicates an invocation was skipped as the contexts of
+	// all its calls were done, due to WithDropCanceledCalls.
+	SuppressCanceled
+)
+
+// String returns a human readable name of the reason.
+func (r SuppressReason) String() string {
+	switch r {
+	case SuppressPredicate:
+		return "predicate"
+	case SuppressInvokeCondition:
+		return "invoke condition"
+	case SuppressCanceled:
+		return "canceled"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,71 @@
+package debounce
+
+import "time"
+
+// Suppressor decides if a periodic job should run, skipping runs when a
+// Debouncer covering the same work has invoked its callback function recently,
+// like a periodic full sync skipped after a debounced incremental sync.
+//
+// All methods are safe for concurrent use in goroutines.
+type Suppressor struct {
+	d         *Debouncer
+	freshness time.Duration
+	inFlight  bool
+}
+
+// NewSuppressor returns a new Suppressor, which considers the work of a
+// periodic job covered for the freshness time after an invocation of d's
+// callback function completed.
+//
+// Optional behavior can be configured by passing one or more SuppressorOption
+// values.
+func NewSuppressor(
+	d *Debouncer,
+	freshness time.Duration,
+	opts ...SuppressorOption,
+) *Suppressor {
+	o := suppressorOptions{}
+	for _, opt := range opts {
+		opt.applySuppressor(&o)
+	}
+
+	return &Suppressor{d: d, freshness: freshness, inFlight: o.countInFlight}
+}
+
+// SuppressorOption configures optional behavior of a Suppressor, like
+// WithCountInFlight. Unlike for other types, Option values are not
+// SuppressorOption values, as a Suppressor has no Debouncer of its own.
+type SuppressorOption interface {
+	applySuppressor(o *suppressorOptions)
+}
+
+type suppressorOptions struct {
+	countInFlight bool
+}
+
+type suppressorOption func(o *suppressorOptions)
+
+func (f suppressorOption) applySuppressor(o *suppressorOptions) {
+	f(o)
+}
+
+// WithCountInFlight makes a Suppressor consider the work of a periodic job
+// covered while an invocation of the Debouncer's callback function is running,
+// rather than only once it has completed.
+func WithCountInFlight() SuppressorOption {
+	return suppressorOption(func(o *suppressorOptions) {
+		o.countInFlight = true
+	})
+}
+
+// ShouldRun reports if the periodic job should run at now, which it should
+// unless an invocation of the Debouncer's callback function completed within
+// the freshness time before now, including exactly the freshness time before.
+// With WithCountInFlight, it also should not run while an invocation is
+// running.
+func (s *Suppressor) ShouldRun(now time.Time) bool {
+	s.d.mux.Lock()
+	defer s.d.mux.Unlock()
+
+	return !s.d.ranWithin(now, s.freshness, s.inFlight)
+}
@@ -0,0 +1,96 @@
+package debounce
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuppressor(t *testing.T) {
+	t.Parallel()
+
+	t0 := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	freshness := time.Minute
+
+	t.Run("boundaries", func(t *testing.T) {
+		t.Parallel()
+
+		mux := sync.Mutex{}
+		now := t0
+		d := NewDebouncer(time.Hour, func() {}, WithCloseTimeout(time.Second))
+		d.now = func() time.Time {
+			mux.Lock()
+			defer mux.Unlock()
+
+			return now
+		}
+		setNow := func(t time.Time) {
+			mux.Lock()
+			defer mux.Unlock()
+			now = t
+		}
+		s := NewSuppressor(d, freshness)
+
+		// Nothing has run yet.
+		assert.True(t, s.ShouldRun(t0))
+		assert.False(t, d.RanWithin(freshness))
+
+		// Close invokes the pending invocation, and waits for it to complete.
+		d.Debounce()
+		require.NoError(t, d.Close())
+
+		tests := []struct {
+			name string
+			at   time.Time
+			want bool
+		}{
+			{name: "right away", at: t0, want: false},
+			{name: "just before", at: t0.Add(freshness - 1), want: false},
+			{name: "exactly", at: t0.Add(freshness), want: false},
+			{name: "just after", at: t0.Add(freshness + 1), want: true},
+		}
+		for _, tt := range tests {
+			assert.Equal(t, tt.want, s.ShouldRun(tt.at), tt.name)
+
+			setNow(tt.at)
+			assert.Equal(t, !tt.want, d.RanWithin(freshness), tt.name)
+		}
+	})
+
+	t.Run("in flight", func(t *testing.T) {
+		t.Parallel()
+
+		started := make(chan struct{})
+		release := make(chan struct{})
+		d := NewDebouncer(time.Hour, func() {
+			close(started)
+			<-release
+		}, WithCloseTimeout(time.Second))
+		d.now = func() time.Time { return t0 }
+
+		s := NewSuppressor(d, freshness)
+		sInFlight := NewSuppressor(d, freshness, WithCountInFlight())
+
+		d.Debounce()
+		require.NoError(t, d.Flush())
+		<-started
+
+		// The invocation is running, but has not completed yet.
+		assert.True(t, s.ShouldRun(t0))
+		assert.False(t, sInFlight.ShouldRun(t0))
+		assert.False(t, d.RanWithin(freshness))
+
+		close(release)
+		require.NoError(t, d.Close())
+
+		// Once completed, the invocation counts either way.
+		assert.False(t, s.ShouldRun(t0.Add(freshness)))
+		assert.False(t, sInFlight.ShouldRun(t0.Add(freshness)))
+		assert.True(t, s.ShouldRun(t0.Add(freshness+1)))
+		assert.True(t, sInFlight.ShouldRun(t0.Add(freshness+1)))
+		assert.True(t, d.RanWithin(0))
+	})
+}
@@ -0,0 +1,137 @@
+package debounce
+
+import (
+	"sync"
+	"time"
+)
+
+// Syncer debounces syncs of a file, like an *os.File, to stable storage, so
+// that a stream of writes is followed by a single sync once the stream goes
+// quiet, rather than a sync after every write. A maximum wait time set with
+// WithMaxWait bounds how long writes go unsynced while the stream keeps going.
+//
+// Each call to Wrote returns a Promise which completes once a sync started
+// after the call has finished, with the error returned by the sync, so writers
+// can know once their data is durable. Errors of syncs started by the Syncer
+// itself are also passed to the hook set with WithOnError.
+//
+// All methods are safe for concurrent use in goroutines. Syncs never run
+// concurrently.
+type Syncer struct {
+	f interface{ Sync() error }
+	d *Debouncer
+
+	// mux guards pending and closed.
+	mux     sync.Mutex
+	pending *Promise
+	closed  bool
+
+	closeOnce sync.Once
+	closeErr  error
+
+	// syncMux serializes syncs of f.
+	syncMux sync.Mutex
+}
+
+// NewSyncer returns a new Syncer which syncs f once Wrote has not been called
+// for the wait time.
+//
+// Optional behavior can be configured by passing one or more Option values.
+func NewSyncer(
+	f interface{ Sync() error },
+	wait time.Duration,
+	opts ...Option,
+) *Syncer {
+	s := &Syncer{f: f}
+	s.d = NewDebouncer(wait, s.invoke, opts...)
+
+	return s
+}
+
+// Wrote schedules a sync of the file, and should be called after each write
+// to it. The returned Promise completes once the data written before the call
+// has been synced, with the error of the sync. If the Syncer has been closed,
+// the returned Promise is already completed with ErrClosed.
+func (s *Syncer) Wrote() *Promise {
+	s.mux.Lock()
+	if s.closed {
+		s.mux.Unlock()
+		p := newPromise()
+		p.complete(ErrClosed)
+
+		return p
+	}
+	if s.pending == nil {
+		s.pending = newPromise()
+	}
+	p := s.pending
+	s.mux.Unlock()
+
+	s.d.Debounce()
+
+	return p
+}
+
+// Sync syncs the file right away, and returns the error of the sync. Any
+// pending sync is canceled, as it is covered by this one. Sync returns
+// ErrClosed if the Syncer has been closed.
+func (s *Syncer) Sync() error {
+	s.mux.Lock()
+	closed := s.closed
+	s.mux.Unlock()
+
+	if closed {
+		return ErrClosed
+	}
+
+	s.d.Cancel()
+
+	return s.sync(true)
+}
+
+// Close syncs the file a final time, stops the Syncer, and returns the error
+// of the sync, or if it succeeded, ErrTimeout if running syncs did not complete
+// within the grace period set with WithCloseTimeout. Further calls to Wrote
+// return a Promise completed with ErrClosed. Close does not close the file, and
+// only syncs it on the first call, with later calls returning the same error.
+func (s *Syncer) Close() error {
+	s.closeOnce.Do(func() {
+		s.mux.Lock()
+		s.closed = true
+		s.mux.Unlock()
+
+		closeErr := s.d.Close()
+		if s.closeErr = s.sync(true); s.closeErr == nil {
+			s.closeErr = closeErr
+		}
+	})
+
+	return s.closeErr
+}
+
+func (s *Syncer) invoke() {
+	if err := s.sync(false); err != nil && s.d.opts.onError != nil {
+		s.d.opts.onError(err)
+	}
+}
+
+// sync syncs f and completes the promise of the writes it covers. Unless force
+// is true, f is only synced if there are writes which have not been synced.
+func (s *Syncer) sync(force bool) error {
+	s.syncMux.Lock()
+	defer s.syncMux.Unlock()
+
+	s.mux.Lock()
+	p := s.pending
+	s.pending = nil
+	s.mux.Unlock()
+
+	if p == nil && !force {
+		return nil
+	}
+
+	err := s.f.Sync()
+	p.complete(err)
+
+	return err
+}
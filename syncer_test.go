@@ -0,0 +1,171 @@
+package debounce
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordFile records the time of each sync made to it.
+type recordFile struct {
+	mux   sync.Mutex
+	syncs []time.Time
+	err   error
+}
+
+func (f *recordFile) Sync() error {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
+	f.syncs = append(f.syncs, time.Now())
+
+	return f.err
+}
+
+func (f *recordFile) Syncs() []time.Time {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
+	return append([]time.Time(nil), f.syncs...)
+}
+
+func TestSyncer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("burst of writes", func(t *testing.T) {
+		t.Parallel()
+
+		rf := &recordFile{}
+		s := NewSyncer(rf, 20*time.Millisecond)
+
+		start := time.Now()
+		promises := make([]*Promise, 0, 5)
+		for i := 0; i < 5; i++ {
+			promises = append(promises, s.Wrote())
+			time.Sleep(5 * time.Millisecond)
+		}
+		assert.Empty(t, rf.Syncs())
+
+		for _, p := range promises {
+			select {
+			case <-p.Done():
+				assert.NoError(t, p.Err())
+			case <-time.After(time.Second):
+				require.FailNow(t, "promise did not complete")
+			}
+		}
+
+		syncs := rf.Syncs()
+		require.Len(t, syncs, 1)
+		assert.GreaterOrEqual(t, syncs[0].Sub(start), 40*time.Millisecond)
+		assert.Same(t, promises[0], promises[4])
+
+		time.Sleep(40 * time.Millisecond)
+		assert.Len(t, rf.Syncs(), 1)
+	})
+
+	t.Run("max wait", func(t *testing.T) {
+		t.Parallel()
+
+		rf := &recordFile{}
+		s := NewSyncer(rf, 20*time.Millisecond,
+			WithMaxWait(50*time.Millisecond),
+		)
+
+		for i := 0; i < 7; i++ {
+			s.Wrote()
+			time.Sleep(12 * time.Millisecond)
+		}
+
+		// Writes kept coming, so only the maximum wait time has passed.
+		assert.Len(t, rf.Syncs(), 1)
+
+		require.NoError(t, s.Sync())
+		assert.Len(t, rf.Syncs(), 2)
+
+		time.Sleep(40 * time.Millisecond)
+		assert.Len(t, rf.Syncs(), 2)
+	})
+
+	t.Run("forced sync", func(t *testing.T) {
+		t.Parallel()
+
+		rf := &recordFile{}
+		s := NewSyncer(rf, time.Minute)
+
+		p := s.Wrote()
+		require.NoError(t, s.Sync())
+		assert.Len(t, rf.Syncs(), 1)
+
+		select {
+		case <-p.Done():
+			assert.NoError(t, p.Err())
+		default:
+			assert.Fail(t, "promise not completed by Sync")
+		}
+
+		require.NoError(t, s.Sync())
+		assert.Len(t, rf.Syncs(), 2)
+	})
+
+	t.Run("close", func(t *testing.T) {
+		t.Parallel()
+
+		rf := &recordFile{}
+		s := NewSyncer(rf, time.Minute)
+
+		p := s.Wrote()
+		require.NoError(t, s.Close())
+		assert.Len(t, rf.Syncs(), 1)
+		<-p.Done()
+		assert.NoError(t, p.Err())
+
+		p = s.Wrote()
+		<-p.Done()
+		assert.ErrorIs(t, p.Err(), ErrClosed)
+		assert.ErrorIs(t, s.Sync(), ErrClosed)
+		assert.Len(t, rf.Syncs(), 1)
+
+		require.NoError(t, s.Close())
+		assert.Len(t, rf.Syncs(), 1)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+
+		errSync := errors.New("sync failed")
+		rf := &recordFile{err: errSync}
+
+		mux := sync.Mutex{}
+		var hookErrs []error
+		s := NewSyncer(rf, 10*time.Millisecond,
+			WithOnError(func(err error) {
+				mux.Lock()
+				defer mux.Unlock()
+				hookErrs = append(hookErrs, err)
+			}),
+		)
+
+		p := s.Wrote()
+		select {
+		case <-p.Done():
+			assert.ErrorIs(t, p.Err(), errSync)
+		case <-time.After(time.Second):
+			require.FailNow(t, "promise did not complete")
+		}
+
+		// The hook is called once the promise has been completed.
+		time.Sleep(10 * time.Millisecond)
+		mux.Lock()
+		assert.Equal(t, []error{errSync}, hookErrs)
+		mux.Unlock()
+
+		assert.ErrorIs(t, s.Sync(), errSync)
+		assert.ErrorIs(t, s.Close(), errSync)
+		assert.ErrorIs(t, s.Close(), errSync)
+	})
+}
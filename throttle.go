@@ -0,0 +1,102 @@
+package debounce
+
+import "time"
+
+// NewThrottle returns a throttled function that invokes f at most once per
+// interval. The first call invokes f right away, and starts an interval during
+// which further calls are ignored, unless WithThrottleTrailing is used, in
+// which case they are coalesced into a single invocation at the end of the
+// interval, which starts a new interval.
+//
+// This differs from NewWithMaxWait, which delays each invocation until calls
+// have stopped for the wait time, and only uses the maximum wait time to
+// bound that delay. A throttled function does not wait for calls to stop, so
+// invocations are spaced interval apart for as long as calls keep coming.
+//
+// The returned cancel function cancels any pending trailing invocation, and
+// ends the current interval, so the next call invokes f right away. It is not
+// required to be called, so can be ignored if not needed.
+//
+// Both throttled and cancel functions are safe for concurrent use in
+// goroutines, and can both be called multiple times.
+//
+// Optional behavior can be configured by passing one or more ThrottleOption
+// values, which include all Option values, though options which only concern
+// the wait time of debounced functions, like WithMaxWait and
+// WithBurstPassThrough, have no effect.
+func NewThrottle(
+	interval time.Duration,
+	f func(),
+	opts ...ThrottleOption,
+) (throttled func(), cancel func()) {
+	o := throttleOptions{}
+	for _, opt := range opts {
+		opt.applyThrottle(&o)
+	}
+
+	d := NewDebouncer(interval, f, o.opts...)
+	d.throttle = true
+	d.throttleTrailing = o.trailing
+
+	return d.Debounce, d.cancelFunc()
+}
+
+// ThrottleOption configures optional behavior of a throttled function returned
+// by NewThrottle. Any Option is a ThrottleOption, and so are the options which
+// only concern throttled functions, like WithThrottleTrailing.
+type ThrottleOption interface {
+	applyThrottle(o *throttleOptions)
+}
+
+type throttleOptions struct {
+	opts     []Option
+	trailing bool
+}
+
+type throttleOption func(o *throttleOptions)
+
+func (f throttleOption) applyThrottle(o *throttleOptions) {
+	f(o)
+}
+
+func (f Option) applyThrottle(o *throttleOptions) {
+	o.opts = append(o.opts, f)
+}
+
+// WithThrottleTrailing makes a throttled function invoke its callback function
+// once more at the end of an interval, if it was called during the interval.
+// Without it, such calls are ignored.
+func WithThrottleTrailing() ThrottleOption {
+	return throttleOption(func(o *throttleOptions) {
+		o.trailing = true
+	})
+}
+
+// throttleCall records a call to a throttled function, and reports if the
+// callback function should be executed right away. Must be called while
+// holding the lock.
+func (d *Debouncer) throttleCall(
+	now time.Time,
+	call InvokeInfo,
+) (InvokeInfo, bool) {
+	if !d.dirty && !now.Before(d.throttledUntil) {
+		if d.deferral(now) == 0 {
+			return d.invoke(call)
+		}
+	} else if !d.throttleTrailing {
+		d.stats.Suppressed++
+		call.complete(ErrCanceled)
+
+		return InvokeInfo{}, false
+	}
+
+	// Hold on to the call until the interval ends, or the quota and rate
+	// limiter allow the leading invocation. Later calls do not postpone it.
+	d.burst = d.burst.merge(call, d.combine)
+	if !d.dirty {
+		d.dirty = true
+		d.resetTimer(elapsed(now, d.throttledUntil), true)
+	}
+
+	return InvokeInfo{}, false
+}
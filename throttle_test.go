@@ -0,0 +1,192 @@
+package debounce
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewThrottle(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		interval     time.Duration
+		opts         []ThrottleOption
+		calls        []testOp
+		wantTriggers map[time.Duration]int
+	}{
+		{
+			name:     "one call one trigger",
+			interval: 20 * time.Millisecond,
+			calls: []testOp{
+				{delay: 10 * time.Millisecond},
+			},
+			wantTriggers: map[time.Duration]int{
+				5 * time.Millisecond:   0,
+				15 * time.Millisecond:  1,
+				150 * time.Millisecond: 1,
+			},
+		},
+		{
+			name:     "many calls two triggers",
+			interval: 30 * time.Millisecond,
+			calls: []testOp{
+				{delay: 10 * time.Millisecond}, // trigger 1
+				{delay: 15 * time.Millisecond},
+				{delay: 20 * time.Millisecond},
+				{delay: 35 * time.Millisecond},
+				{delay: 50 * time.Millisecond}, // trigger 2
+				{delay: 60 * time.Millisecond},
+			},
+			wantTriggers: map[time.Duration]int{
+				5 * time.Millisecond:  0,
+				15 * time.Millisecond: 1,
+				// calls until 40ms (10ms + 30ms interval) are ignored
+				45 * time.Millisecond:  1,
+				55 * time.Millisecond:  2,
+				150 * time.Millisecond: 2,
+			},
+		},
+		{
+			name:     "steady calls trigger once per interval",
+			interval: 25 * time.Millisecond,
+			calls: []testOp{
+				{delay: 5 * time.Millisecond}, // trigger 1
+				{delay: 15 * time.Millisecond},
+				{delay: 25 * time.Millisecond},
+				{delay: 35 * time.Millisecond}, // trigger 2
+				{delay: 45 * time.Millisecond},
+				{delay: 55 * time.Millisecond},
+				{delay: 65 * time.Millisecond}, // trigger 3
+			},
+			wantTriggers: map[time.Duration]int{
+				10 * time.Millisecond:  1,
+				30 * time.Millisecond:  1,
+				40 * time.Millisecond:  2,
+				60 * time.Millisecond:  2,
+				70 * time.Millisecond:  3,
+				150 * time.Millisecond: 3,
+			},
+		},
+		{
+			name:     "trailing",
+			interval: 30 * time.Millisecond,
+			opts:     []ThrottleOption{WithThrottleTrailing()},
+			calls: []testOp{
+				{delay: 10 * time.Millisecond}, // leading
+				{delay: 15 * time.Millisecond},
+				{delay: 20 * time.Millisecond},  // trailing at 40ms
+				{delay: 50 * time.Millisecond},  // trailing at 70ms
+				{delay: 110 * time.Millisecond}, // leading
+			},
+			wantTriggers: map[time.Duration]int{
+				15 * time.Millisecond: 1,
+				35 * time.Millisecond: 1,
+				// end of the interval started at 10ms (+30ms = 40ms)
+				45 * time.Millisecond: 2,
+				65 * time.Millisecond: 2,
+				// end of the interval started at 40ms (+30ms = 70ms)
+				75 * time.Millisecond:  3,
+				105 * time.Millisecond: 3,
+				// interval started at 70ms ended at 100ms
+				115 * time.Millisecond: 4,
+				150 * time.Millisecond: 4,
+			},
+		},
+		{
+			name:     "cancel ends interval",
+			interval: 50 * time.Millisecond,
+			calls: []testOp{
+				{delay: 10 * time.Millisecond}, // trigger 1
+				{delay: 20 * time.Millisecond},
+				{delay: 30 * time.Millisecond, cancel: true},
+				{delay: 40 * time.Millisecond}, // trigger 2
+			},
+			wantTriggers: map[time.Duration]int{
+				15 * time.Millisecond:  1,
+				35 * time.Millisecond:  1,
+				45 * time.Millisecond:  2,
+				150 * time.Millisecond: 2,
+			},
+		},
+		{
+			name:     "cancel discards trailing",
+			interval: 30 * time.Millisecond,
+			opts:     []ThrottleOption{WithThrottleTrailing()},
+			calls: []testOp{
+				{delay: 10 * time.Millisecond}, // trigger 1
+				{delay: 20 * time.Millisecond},
+				{delay: 30 * time.Millisecond, cancel: true},
+				{delay: 50 * time.Millisecond}, // trigger 2
+			},
+			wantTriggers: map[time.Duration]int{
+				15 * time.Millisecond:  1,
+				45 * time.Millisecond:  1,
+				55 * time.Millisecond:  2,
+				150 * time.Millisecond: 2,
+			},
+		},
+		{
+			name:     "general options apply",
+			interval: 20 * time.Millisecond,
+			opts: []ThrottleOption{
+				WithThrottleTrailing(), WithAutoStop(1, nil),
+			},
+			calls: []testOp{
+				{delay: 10 * time.Millisecond}, // trigger 1
+				{delay: 15 * time.Millisecond}, // stopped
+				{delay: 40 * time.Millisecond}, // stopped
+			},
+			wantTriggers: map[time.Duration]int{
+				15 * time.Millisecond:  1,
+				150 * time.Millisecond: 1,
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mux := sync.RWMutex{}
+
+			n := 0
+			d, c := NewThrottle(tt.interval, func() {
+				mux.Lock()
+				defer mux.Unlock()
+				n++
+			}, tt.opts...)
+
+			wg := sync.WaitGroup{}
+			for _, op := range tt.calls {
+				wg.Add(1)
+				go func(delay time.Duration, cancel bool) {
+					defer wg.Done()
+					time.Sleep(delay)
+					if cancel {
+						c()
+					} else {
+						d()
+					}
+				}(op.delay, op.cancel)
+			}
+
+			for delay, count := range tt.wantTriggers {
+				wg.Add(1)
+				go func(interval time.Duration, count int) {
+					defer wg.Done()
+					time.Sleep(interval)
+
+					mux.RLock()
+					defer mux.RUnlock()
+					assert.Equal(t, count, n, "at %s", interval)
+				}(delay, count)
+			}
+
+			wg.Wait()
+		})
+	}
+}
@@ -6,6 +6,13 @@ import (
 
 const longDelay = 24 * time.Hour
 
+// timer is a timer which calls a function once it expires, like a *time.Timer
+// created with time.AfterFunc, or one of a Scheduler.
+type timer interface {
+	Reset(d time.Duration) bool
+	Stop() bool
+}
+
 // stoppedTimer returns a stopped *time.Timer created with time.AfterFunc. The
 // given function is not called until the timer is restarted with Reset.
 func stoppedTimer(f func()) *time.Timer {
@@ -14,3 +21,63 @@ func stoppedTimer(f func()) *time.Timer {
 
 	return t
 }
+
+// guardedTimer wraps a timer whose function takes a lock, which is also held
+// when calling Reset and Stop. A call of the function which was already
+// dispatched when the timer was reset or stopped, but which only gets the lock
+// afterwards, is stale, and fired makes sure it has no effect.
+//
+// Like a generation counter, it keeps count of the stale calls in flight, so
+// they are told apart from calls for the current expiry whatever order they get
+// the lock in. All methods must be called while holding the lock.
+type guardedTimer struct {
+	timer
+	// armed is true from Reset until a call for the expiry gets the lock, or
+	// the timer is stopped.
+	armed bool
+	// stale is the number of calls in flight for expiries which were reset or
+	// stopped since.
+	stale int
+}
+
+// Reset changes the timer to expire after duration d, and reports if the timer
+// had been active.
+func (t *guardedTimer) Reset(d time.Duration) bool {
+	active := t.timer.Reset(d)
+	t.invalidate(active)
+	t.armed = true
+
+	return active
+}
+
+// Stop prevents the timer from firing, and reports if the timer had been
+// active.
+func (t *guardedTimer) Stop() bool {
+	active := t.timer.Stop()
+	t.invalidate(active)
+	t.armed = false
+
+	return active
+}
+
+// invalidate records a call in flight as stale, if the timer was armed, but
+// no longer active, as it has expired without its call getting the lock yet.
+func (t *guardedTimer) invalidate(active bool) {
+	if t.armed && !active {
+		t.stale++
+	}
+}
+
+// fired reports if a call of the timer's function is for the current expiry,
+// rather than a stale one. It must be called once by each call of the timer's
+// function.
+func (t *guardedTimer) fired() bool {
+	if t.stale > 0 {
+		t.stale--
+
+		return false
+	}
+	t.armed = false
+
+	return true
+}
@@ -0,0 +1,120 @@
+package debounce
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTimer is a timer which only records if it is active, and expires when
+// told to.
+type fakeTimer struct {
+	active bool
+}
+
+func (t *fakeTimer) Reset(time.Duration) bool {
+	active := t.active
+	t.active = true
+
+	return active
+}
+
+func (t *fakeTimer) Stop() bool {
+	active := t.active
+	t.active = false
+
+	return active
+}
+
+// expire marks the timer as expired, as if its function had been dispatched.
+func (t *fakeTimer) expire() {
+	t.active = false
+}
+
+func TestGuardedTimer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("current expiry", func(t *testing.T) {
+		t.Parallel()
+
+		ft := &fakeTimer{}
+		gt := &guardedTimer{timer: ft}
+
+		gt.Reset(time.Second)
+		ft.expire()
+		assert.True(t, gt.fired())
+	})
+
+	t.Run("reset before expiry", func(t *testing.T) {
+		t.Parallel()
+
+		ft := &fakeTimer{}
+		gt := &guardedTimer{timer: ft}
+
+		gt.Reset(time.Second)
+		gt.Reset(time.Second)
+		ft.expire()
+		assert.True(t, gt.fired())
+	})
+
+	t.Run("stopped after expiry", func(t *testing.T) {
+		t.Parallel()
+
+		ft := &fakeTimer{}
+		gt := &guardedTimer{timer: ft}
+
+		gt.Reset(time.Second)
+		ft.expire()
+		gt.Stop()
+		assert.False(t, gt.fired())
+	})
+
+	t.Run("reset after expiry", func(t *testing.T) {
+		t.Parallel()
+
+		ft := &fakeTimer{}
+		gt := &guardedTimer{timer: ft}
+
+		gt.Reset(time.Second)
+		ft.expire()
+		gt.Reset(time.Second)
+		assert.False(t, gt.fired(), "stale expiry")
+
+		ft.expire()
+		assert.True(t, gt.fired(), "current expiry")
+	})
+
+	t.Run("several stale expiries", func(t *testing.T) {
+		t.Parallel()
+
+		ft := &fakeTimer{}
+		gt := &guardedTimer{timer: ft}
+
+		for i := 0; i < 3; i++ {
+			gt.Reset(time.Second)
+			ft.expire()
+		}
+		gt.Reset(time.Second)
+		ft.expire()
+
+		for i := 0; i < 3; i++ {
+			assert.False(t, gt.fired())
+		}
+		assert.True(t, gt.fired())
+	})
+
+	t.Run("stopped twice", func(t *testing.T) {
+		t.Parallel()
+
+		ft := &fakeTimer{}
+		gt := &guardedTimer{timer: ft}
+
+		gt.Reset(time.Second)
+		ft.expire()
+		gt.Stop()
+		gt.Stop()
+		assert.False(t, gt.fired())
+		assert.Equal(t, 0, gt.stale)
+	})
+}
@@ -0,0 +1,82 @@
+package debounce
+
+import (
+	"context"
+	"time"
+)
+
+// NewTyped returns a debounced function like New, but which takes a value. The
+// value passed to the last call before f is invoked is passed to f, with
+// earlier values being discarded.
+//
+// When the first calls of a burst are let through with WithBurstPassThrough,
+// each of their invocations is passed the value of the call which triggered
+// it.
+//
+// The returned cancel function can be used to cancel any pending invocation of
+// f, discarding its value, but is not required to be called, so can be ignored
+// if not needed.
+//
+// Both debounced and cancel functions are safe for concurrent use in
+// goroutines, and can both be called multiple times.
+func NewTyped[T any](
+	wait time.Duration,
+	f func(value T),
+	opts ...Option,
+) (debounced func(value T), cancel func()) {
+	d := newTyped(wait, f, opts)
+
+	return func(value T) { d.add(value) }, d.cancelFunc()
+}
+
+// NewTypedWithMaxWait returns a debounced function like NewTyped, but with a
+// maximum wait time of maxWait, which is the maximum time f is allowed to be
+// delayed before it is invoked.
+func NewTypedWithMaxWait[T any](
+	wait, maxWait time.Duration,
+	f func(value T),
+	opts ...Option,
+) (debounced func(value T), cancel func()) {
+	d := newTyped(wait, f, opts).withMaxWait(maxWait)
+
+	return func(value T) { d.add(value) }, d.cancelFunc()
+}
+
+func newTyped[T any](
+	wait time.Duration,
+	f func(value T),
+	opts []Option,
+) *Debouncer {
+	d := newDebouncer(wait, func(_ context.Context, info InvokeInfo) {
+		// The value is only ever a T, or nil when T is an interface type.
+		v, _ := info.value.(T)
+		f(v)
+	}, opts)
+	d.combine = func(_, next interface{}) interface{} { return next }
+
+	return d
+}
+
+// NewLatest returns a debounced function like NewTyped, along with a latest
+// function which returns the value the pending invocation of f will be passed,
+// unless the debounced function is called again first. It reports false if no
+// invocation is pending.
+//
+// The debounced, latest and cancel functions are all safe for concurrent use in
+// goroutines, and can all be called multiple times.
+func NewLatest[T any](
+	wait time.Duration,
+	f func(value T),
+	opts ...Option,
+) (debounced func(value T), latest func() (T, bool), cancel func()) {
+	d := newTyped(wait, f, opts)
+
+	latest = func() (T, bool) {
+		value, ok := d.pendingValue()
+		v, _ := value.(T)
+
+		return v, ok
+	}
+
+	return func(value T) { d.add(value) }, latest, d.cancelFunc()
+}
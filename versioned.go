@@ -0,0 +1,47 @@
+package debounce
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// VersionedDebouncer is a Debouncer which numbers the invocations of its
+// callback function, so work they hand off elsewhere can be put in order, and
+// duplicates detected.
+//
+// All methods are safe for concurrent use in goroutines.
+type VersionedDebouncer struct {
+	*Debouncer
+
+	seq uint64
+}
+
+// NewVersioned returns a new VersionedDebouncer, which debounces calls to its
+// Debounce method like NewDebouncer, and passes f the sequence number of each
+// invocation. Sequence numbers start at 1, and increase by one for every
+// invocation, whatever triggered it, including Flush.
+//
+// Sequence numbers are assigned in the order invocations start. Invocations
+// run in their own goroutines unless WithWorker is used, so f may see them out
+// of order.
+//
+// Optional behavior can be configured by passing one or more Option values.
+func NewVersioned(
+	wait time.Duration,
+	f func(seq uint64),
+	opts ...Option,
+) *VersionedDebouncer {
+	v := &VersionedDebouncer{}
+	v.Debouncer = newDebouncer(wait, func(context.Context, InvokeInfo) {
+		f(atomic.AddUint64(&v.seq, 1))
+	}, opts)
+
+	return v
+}
+
+// Seq returns the sequence number of the most recently started invocation of
+// the callback function, or 0 if there has been none.
+func (v *VersionedDebouncer) Seq() uint64 {
+	return atomic.LoadUint64(&v.seq)
+}
@@ -0,0 +1,52 @@
+package debounce
+
+import (
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewVersioned(t *testing.T) {
+	t.Parallel()
+
+	var mux sync.Mutex
+	var got []uint64
+	v := NewVersioned(5*time.Millisecond, func(seq uint64) {
+		mux.Lock()
+		defer mux.Unlock()
+		got = append(got, seq)
+	}, WithBurstPassThrough(1), WithMaxWait(15*time.Millisecond))
+
+	assert.Equal(t, uint64(0), v.Seq())
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				v.Debounce()
+				if j%7 == 0 {
+					v.Flush()
+				}
+				time.Sleep(time.Millisecond)
+			}
+		}()
+	}
+	wg.Wait()
+	time.Sleep(30 * time.Millisecond)
+
+	mux.Lock()
+	defer mux.Unlock()
+
+	require.NotEmpty(t, got)
+	sort.Slice(got, func(i, j int) bool { return got[i] < got[j] })
+	for i, seq := range got {
+		assert.Equal(t, uint64(i+1), seq)
+	}
+	assert.Equal(t, uint64(len(got)), v.Seq())
+}
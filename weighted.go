@@ -0,0 +1,74 @@
+package debounce
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// NewWeighted returns a debounced function like NewBatch, but which also
+// invokes f right away once the summed weight of the values collected reaches
+// maxWeight, as given by the weight function, for example to bound batches by
+// their size in bytes. Values passed afterwards start a new batch, with a wait
+// time of its own.
+//
+// A value weighing maxWeight or more on its own is passed to f in a batch of
+// its own right away, after any values collected before it have been passed to
+// f in a batch of their own.
+//
+// The returned cancel function can be used to cancel any pending invocation of
+// f, discarding the values collected for it, but is not required to be called,
+// so can be ignored if not needed.
+//
+// Both add and cancel functions are safe for concurrent use in goroutines, and
+// can both be called multiple times.
+func NewWeighted[T any](
+	wait time.Duration,
+	weight func(value T) int,
+	maxWeight int,
+	f func(batch []T),
+	opts ...Option,
+) (add func(value T), cancel func()) {
+	d := newDebouncer(wait, func(_ context.Context, info InvokeInfo) {
+		batch, _ := info.value.(weightedBatch[T])
+		f(batch.values)
+	}, opts)
+	d.combine = func(acc, next interface{}) interface{} {
+		a, n := acc.(weightedBatch[T]), next.(weightedBatch[T])
+
+		return weightedBatch[T]{
+			values: append(a.values, n.values...),
+			weight: a.weight + n.weight,
+		}
+	}
+	d.full = func(value interface{}) bool {
+		return value.(weightedBatch[T]).weight >= maxWeight
+	}
+
+	// Values which are too heavy hold the lock exclusively, so no other value
+	// can join the batch they are passed in.
+	var mux sync.RWMutex
+
+	return func(value T) {
+		batch := weightedBatch[T]{values: []T{value}, weight: weight(value)}
+		if batch.weight >= maxWeight {
+			mux.Lock()
+			defer mux.Unlock()
+			// Nothing may be pending, in which case there is nothing to
+			// keep apart from the heavy value.
+			_ = d.Flush()
+		} else {
+			mux.RLock()
+			defer mux.RUnlock()
+		}
+
+		d.add(batch)
+	}, d.cancelFunc()
+}
+
+// weightedBatch is the batch of values collected by a function returned by
+// NewWeighted, along with their summed weight.
+type weightedBatch[T any] struct {
+	values []T
+	weight int
+}
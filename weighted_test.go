@@ -0,0 +1,148 @@
+package debounce
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWeighted(t *testing.T) {
+	t.Parallel()
+
+	length := func(s string) int { return len(s) }
+
+	t.Run("flushes once max weight is reached", func(t *testing.T) {
+		t.Parallel()
+
+		got := make(chan []string, 3)
+		add, _ := NewWeighted(20*time.Millisecond, length, 10,
+			func(batch []string) { got <- batch },
+			WithWorker(),
+		)
+
+		add("aaaa")
+		add("bbb")
+		add("cc")
+		add("ddd") // 12 >= 10
+		add("e")
+		add("ffffffff") // 9
+		add("g")        // 10 >= 10
+		add("hh")
+
+		assert.Equal(t, []string{"aaaa", "bbb", "cc", "ddd"}, <-got)
+		assert.Equal(t, []string{"e", "ffffffff", "g"}, <-got)
+		// from the wait time
+		assert.Equal(t, []string{"hh"}, <-got)
+	})
+
+	t.Run("oversized value flushes alone", func(t *testing.T) {
+		t.Parallel()
+
+		got := make(chan []string, 3)
+		add, _ := NewWeighted(time.Minute, length, 10,
+			func(batch []string) { got <- batch },
+			WithWorker(),
+		)
+
+		start := time.Now()
+		add("aa")
+		add("bb")
+		add("cccccccccccc")
+		add("dddddddddd")
+
+		assert.Equal(t, []string{"aa", "bb"}, <-got)
+		assert.Equal(t, []string{"cccccccccccc"}, <-got)
+		assert.Equal(t, []string{"dddddddddd"}, <-got)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("max wait", func(t *testing.T) {
+		t.Parallel()
+
+		mux := sync.Mutex{}
+		var got [][]string
+		add, _ := NewWeighted(20*time.Millisecond, length, 100,
+			func(batch []string) {
+				mux.Lock()
+				defer mux.Unlock()
+				got = append(got, batch)
+			},
+			WithMaxWait(45*time.Millisecond),
+		)
+
+		for i := 0; i < 6; i++ {
+			add("a")
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		mux.Lock()
+		defer mux.Unlock()
+		require.Len(t, got, 1)
+		assert.Len(t, got[0], 5)
+	})
+
+	t.Run("concurrent adds", func(t *testing.T) {
+		t.Parallel()
+
+		mux := sync.Mutex{}
+		var got [][]int
+		weight := func(v int) int { return v }
+		add, _ := NewWeighted(50*time.Millisecond, weight, 10,
+			func(batch []int) {
+				mux.Lock()
+				defer mux.Unlock()
+				got = append(got, batch)
+			},
+		)
+
+		// Values of 1 and 2 only, so every full batch weighs 10 or 11, while
+		// values of 12 are always passed alone. Batches flushed ahead of a
+		// value of 12, and the last one, weigh less.
+		wg := sync.WaitGroup{}
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 50; j++ {
+					switch {
+					case j == i:
+						add(12)
+					case j%2 == 0:
+						add(1)
+					default:
+						add(2)
+					}
+				}
+			}(i)
+		}
+		wg.Wait()
+		time.Sleep(100 * time.Millisecond)
+
+		mux.Lock()
+		defer mux.Unlock()
+		heavy, light, total := 0, 0, 0
+		for _, batch := range got {
+			sum := 0
+			for _, v := range batch {
+				sum += v
+			}
+			total += sum
+			switch {
+			case sum == 12 && len(batch) == 1:
+				heavy++
+			case sum < 10:
+				light++
+			default:
+				assert.LessOrEqual(t, sum, 11, "batch %v", batch)
+			}
+		}
+		assert.Equal(t, 8, heavy)
+		assert.LessOrEqual(t, light, 9)
+		// 8 goroutines passing 25 values of 1 and 25 values of 2 each, with
+		// one of them replaced by 12.
+		assert.Equal(t, 8*75-4*1-4*2+8*12, total)
+	})
+}
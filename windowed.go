@@ -0,0 +1,63 @@
+package debounce
+
+import (
+	"context"
+	"time"
+)
+
+// Window describes the calls coalesced into an invocation of a callback
+// function created with NewWindowed.
+type Window struct {
+	// First is the time of the first call coalesced into the invocation.
+	First time.Time
+	// Last is the time of the last call coalesced into the invocation.
+	Last time.Time
+	// Count is the number of calls coalesced into the invocation.
+	Count int
+	// Reason describes what triggered the invocation.
+	Reason InvokeReason
+}
+
+// NewWindowed returns a debounced function like New, but f receives a Window
+// describing the calls coalesced into each invocation, for example to measure
+// how long calls were delayed for. Calls let through right away with
+// WithBurstPassThrough are each passed a Window of their own.
+//
+// The returned cancel function can be used to cancel any pending invocation of
+// f, discarding the calls recorded for it, but is not required to be called, so
+// can be ignored if not needed.
+//
+// Both debounced and cancel functions are safe for concurrent use in
+// goroutines, and can both be called multiple times.
+func NewWindowed(
+	wait time.Duration,
+	f func(w Window),
+	opts ...Option,
+) (debounced func(), cancel func()) {
+	d := newDebouncer(wait, func(_ context.Context, info InvokeInfo) {
+		w, _ := info.value.(Window)
+		w.Count = info.Calls
+		w.Reason = info.Reason
+		f(w)
+	}, opts)
+	d.combine = func(acc, next interface{}) interface{} {
+		a, _ := acc.(Window)
+		n, _ := next.(Window)
+
+		// Calls are timed before taking the lock, so they may arrive out of
+		// order.
+		if n.First.Before(a.First) {
+			a.First = n.First
+		}
+		if n.Last.After(a.Last) {
+			a.Last = n.Last
+		}
+
+		return a
+	}
+
+	return func() {
+		now := d.now()
+		d.add(Window{First: now, Last: now})
+	}, d.cancelFunc()
+}
@@ -0,0 +1,127 @@
+package debounce
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWindowed(t *testing.T) {
+	t.Parallel()
+
+	type wantWindow struct {
+		first  time.Duration
+		last   time.Duration
+		count  int
+		reason InvokeReason
+	}
+
+	tests := []struct {
+		name        string
+		wait        time.Duration
+		opts        []Option
+		calls       []testOp
+		wantWindows []wantWindow
+	}{
+		{
+			name: "many calls, one cancel, two triggers",
+			wait: 20 * time.Millisecond,
+			calls: []testOp{
+				{delay: 5 * time.Millisecond},
+				{delay: 10 * time.Millisecond}, // trigger 1
+				{delay: 35 * time.Millisecond},
+				{delay: 50 * time.Millisecond, cancel: true},
+				{delay: 80 * time.Millisecond},
+				{delay: 90 * time.Millisecond},
+				{delay: 100 * time.Millisecond}, // trigger 2
+			},
+			wantWindows: []wantWindow{
+				{5 * time.Millisecond, 10 * time.Millisecond, 2, InvokeWait},
+				{80 * time.Millisecond, 100 * time.Millisecond, 3, InvokeWait},
+			},
+		},
+		{
+			name: "leading and trailing",
+			wait: 20 * time.Millisecond,
+			opts: []Option{WithBurstPassThrough(1)},
+			calls: []testOp{
+				{delay: 10 * time.Millisecond}, // leading
+				{delay: 15 * time.Millisecond},
+				{delay: 20 * time.Millisecond}, // trailing
+			},
+			wantWindows: []wantWindow{
+				{
+					10 * time.Millisecond, 10 * time.Millisecond, 1,
+					InvokeImmediate,
+				},
+				{15 * time.Millisecond, 20 * time.Millisecond, 2, InvokeWait},
+			},
+		},
+		{
+			name: "until right before maxWait",
+			wait: 20 * time.Millisecond,
+			opts: []Option{WithMaxWait(50 * time.Millisecond)},
+			calls: []testOp{
+				{delay: 0 * time.Millisecond},
+				{delay: 10 * time.Millisecond},
+				{delay: 20 * time.Millisecond},
+				{delay: 30 * time.Millisecond},
+				{delay: 40 * time.Millisecond},
+				{delay: 60 * time.Millisecond},
+			},
+			wantWindows: []wantWindow{
+				{0, 40 * time.Millisecond, 5, InvokeMaxWait},
+				{60 * time.Millisecond, 60 * time.Millisecond, 1, InvokeWait},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mux := sync.Mutex{}
+
+			var got []Window
+			d, c := NewWindowed(tt.wait, func(w Window) {
+				mux.Lock()
+				defer mux.Unlock()
+				got = append(got, w)
+			}, tt.opts...)
+
+			start := time.Now()
+			wg := sync.WaitGroup{}
+			for _, op := range tt.calls {
+				wg.Add(1)
+				go func(delay time.Duration, cancel bool) {
+					defer wg.Done()
+					time.Sleep(delay)
+					if cancel {
+						c()
+					} else {
+						d()
+					}
+				}(op.delay, op.cancel)
+			}
+			wg.Wait()
+
+			time.Sleep(tt.wait + 30*time.Millisecond)
+
+			mux.Lock()
+			defer mux.Unlock()
+			require.Len(t, got, len(tt.wantWindows))
+			for i, want := range tt.wantWindows {
+				w := got[i]
+				assert.InDelta(t, want.first, w.First.Sub(start),
+					float64(4*time.Millisecond), "window %d first", i)
+				assert.InDelta(t, want.last, w.Last.Sub(start),
+					float64(4*time.Millisecond), "window %d last", i)
+				assert.Equal(t, want.count, w.Count, "window %d count", i)
+				assert.Equal(t, want.reason, w.Reason, "window %d reason", i)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package debounce
+
+import "time"
+
+// Wrap1 returns a debounced function with the same signature as f, which calls
+// f with the arguments of the last call before f is invoked. It is the same as
+// NewTyped, and is provided alongside Wrap2 and Wrap3 for symmetry.
+//
+// When the first calls of a burst are let through with WithBurstPassThrough,
+// each of their invocations is passed the arguments of the call which
+// triggered it.
+//
+// The returned cancel function can be used to cancel any pending invocation of
+// f, discarding its arguments, but is not required to be called, so can be
+// ignored if not needed.
+//
+// Both debounced and cancel functions are safe for concurrent use in
+// goroutines, and can both be called multiple times.
+func Wrap1[A any](
+	wait time.Duration,
+	f func(a A),
+	opts ...Option,
+) (debounced func(a A), cancel func()) {
+	return NewTyped(wait, f, opts...)
+}
+
+// Wrap2 returns a debounced function like Wrap1, but for a function taking two
+// arguments.
+func Wrap2[A, B any](
+	wait time.Duration,
+	f func(a A, b B),
+	opts ...Option,
+) (debounced func(a A, b B), cancel func()) {
+	d := newTyped(wait, func(args args2[A, B]) { f(args.a, args.b) }, opts)
+
+	return func(a A, b B) { d.add(args2[A, B]{a, b}) }, d.cancelFunc()
+}
+
+// Wrap3 returns a debounced function like Wrap1, but for a function taking
+// three arguments.
+func Wrap3[A, B, C any](
+	wait time.Duration,
+	f func(a A, b B, c C),
+	opts ...Option,
+) (debounced func(a A, b B, c C), cancel func()) {
+	d := newTyped(wait, func(args args3[A, B, C]) {
+		f(args.a, args.b, args.c)
+	}, opts)
+
+	return func(a A, b B, c C) {
+		d.add(args3[A, B, C]{a, b, c})
+	}, d.cancelFunc()
+}
+
+// args2 holds the arguments of a call to a function returned by Wrap2.
+type args2[A, B any] struct {
+	a A
+	b B
+}
+
+// args3 holds the arguments of a call to a function returned by Wrap3.
+type args3[A, B, C any] struct {
+	a A
+	b B
+	c C
+}
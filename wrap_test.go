@@ -0,0 +1,90 @@
+package debounce
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrap1(t *testing.T) {
+	t.Parallel()
+
+	got := make(chan string, 2)
+	d, _ := Wrap1(10*time.Millisecond, func(id string) { got <- id })
+
+	d("a")
+	d("b")
+	d("c")
+	assert.Equal(t, "c", <-got)
+}
+
+func TestWrap2(t *testing.T) {
+	t.Parallel()
+
+	t.Run("burst", func(t *testing.T) {
+		t.Parallel()
+
+		type key struct{}
+		got := make(chan string, 2)
+		d, _ := Wrap2(10*time.Millisecond,
+			func(ctx context.Context, id string) {
+				got <- fmt.Sprintf("%v:%s", ctx.Value(key{}), id)
+			},
+		)
+
+		for i := 1; i <= 3; i++ {
+			ctx := context.WithValue(context.Background(), key{}, i)
+			d(ctx, fmt.Sprint("id", i))
+		}
+		assert.Equal(t, "3:id3", <-got)
+	})
+
+	t.Run("leading", func(t *testing.T) {
+		t.Parallel()
+
+		got := make(chan string, 3)
+		d, _ := Wrap2(10*time.Millisecond,
+			func(a string, b int) { got <- fmt.Sprint(a, b) },
+			WithBurstPassThrough(1),
+		)
+
+		d("a", 1)
+		assert.Equal(t, "a1", <-got)
+		d("b", 2)
+		d("c", 3)
+		assert.Equal(t, "c3", <-got)
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		t.Parallel()
+
+		got := make(chan string, 2)
+		d, cancel := Wrap2(10*time.Millisecond,
+			func(a string, b int) { got <- fmt.Sprint(a, b) },
+		)
+
+		d("a", 1)
+		cancel()
+		d("b", 2)
+		assert.Equal(t, "b2", <-got)
+	})
+}
+
+func TestWrap3(t *testing.T) {
+	t.Parallel()
+
+	got := make(chan string, 3)
+	d, _ := Wrap3(10*time.Millisecond,
+		func(a string, b int, c bool) { got <- fmt.Sprint(a, b, c) },
+		WithBurstPassThrough(1),
+	)
+
+	d("a", 1, true)
+	assert.Equal(t, "a1 true", <-got)
+	d("b", 2, false)
+	d("c", 3, true)
+	assert.Equal(t, "c3 true", <-got)
+}
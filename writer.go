@@ -0,0 +1,188 @@
+package debounce
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// Writer is an io.Writer which buffers writes, and writes them to an
+// underlying io.Writer once no writes have been made for the wait time, for
+// example to turn a stream of small log or telemetry writes into a single
+// write once the stream goes quiet. A maximum wait time set with WithMaxWait
+// bounds how long writes are buffered for while the stream keeps going.
+//
+// Like with a bufio.Writer, once the underlying io.Writer returns an error, no
+// more data is accepted, and all further calls to Write, Flush, Sync and Close
+// return the error. The data which could not be written stays buffered, and
+// its size is reported by Buffered.
+//
+// All methods are safe for concurrent use in goroutines. Buffered data is
+// written in the order it was written to the Writer.
+type Writer struct {
+	w io.Writer
+	d *Debouncer
+
+	// mux guards buf, err and closed.
+	mux    sync.Mutex
+	buf    []byte
+	err    error
+	closed bool
+
+	closeOnce sync.Once
+	closeErr  error
+
+	// flushMux serializes writes and syncs of w.
+	flushMux sync.Mutex
+}
+
+// NewWriter returns a new Writer which writes to w once no writes have been
+// made for the wait time.
+//
+// Optional behavior can be configured by passing one or more Option values.
+func NewWriter(w io.Writer, wait time.Duration, opts ...Option) *Writer {
+	wr := &Writer{w: w}
+	wr.d = NewDebouncer(wait, func() { _ = wr.flush() }, opts...)
+
+	return wr
+}
+
+// Write appends p to the buffer, and schedules a write of the buffer to the
+// underlying io.Writer.
+func (wr *Writer) Write(p []byte) (int, error) {
+	wr.mux.Lock()
+	if err := wr.stateErr(); err != nil {
+		wr.mux.Unlock()
+
+		return 0, err
+	}
+	wr.buf = append(wr.buf, p...)
+	wr.mux.Unlock()
+
+	wr.d.Debounce()
+
+	return len(p), nil
+}
+
+// Flush writes any buffered data to the underlying io.Writer right away. It
+// returns ErrClosed if the Writer has been closed.
+func (wr *Writer) Flush() error {
+	wr.mux.Lock()
+	err := wr.stateErr()
+	wr.mux.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	wr.d.Cancel()
+
+	return wr.flush()
+}
+
+// Sync writes any buffered data to the underlying io.Writer like Flush, and
+// then syncs the underlying io.Writer if it has a Sync method, like *os.File,
+// returning once both have completed. It returns ErrClosed if the Writer has
+// been closed.
+func (wr *Writer) Sync() error {
+	if err := wr.Flush(); err != nil {
+		return err
+	}
+
+	if s, ok := wr.w.(interface{ Sync() error }); ok {
+		wr.flushMux.Lock()
+		defer wr.flushMux.Unlock()
+
+		return s.Sync()
+	}
+
+	return nil
+}
+
+// Buffered returns the number of bytes which have been written to the Writer,
+// but not yet to the underlying io.Writer.
+func (wr *Writer) Buffered() int {
+	wr.mux.Lock()
+	defer wr.mux.Unlock()
+
+	return len(wr.buf)
+}
+
+// Close writes any buffered data to the underlying io.Writer, waiting for any
+// write already in progress to complete first, and stops the Writer. It
+// returns the first error returned by the underlying io.Writer, if any, or
+// otherwise ErrTimeout if running writes did not complete within the grace
+// period set with WithCloseTimeout.
+//
+// Writes which complete before Close is called are always included in the
+// final write, while writes made once Close has been called return ErrClosed.
+// Further flushes return ErrClosed too. Calling Close more than once is safe,
+// and returns the same error as the first call. Close does not close the
+// underlying io.Writer.
+func (wr *Writer) Close() error {
+	wr.closeOnce.Do(func() {
+		wr.mux.Lock()
+		wr.closed = true
+		wr.mux.Unlock()
+
+		closeErr := wr.d.Close()
+		if wr.closeErr = wr.flush(); wr.closeErr == nil {
+			wr.closeErr = closeErr
+		}
+	})
+
+	return wr.closeErr
+}
+
+// flush writes any buffered data to w, and returns the first error returned by
+// w so far. Once w has returned an error, flush leaves the buffer alone.
+func (wr *Writer) flush() error {
+	wr.flushMux.Lock()
+	defer wr.flushMux.Unlock()
+
+	wr.mux.Lock()
+	if wr.err != nil {
+		defer wr.mux.Unlock()
+
+		return wr.err
+	}
+	buf := wr.buf
+	wr.buf = nil
+	wr.mux.Unlock()
+
+	if len(buf) == 0 {
+		return nil
+	}
+
+	n, err := wr.w.Write(buf)
+	if err == nil && n < len(buf) {
+		err = io.ErrShortWrite
+	}
+	if err != nil {
+		if n < 0 || n > len(buf) {
+			n = 0
+		}
+
+		wr.mux.Lock()
+		defer wr.mux.Unlock()
+
+		// Keep the data which was not written ahead of any data written to
+		// the Writer in the meantime.
+		wr.buf = append(buf[n:len(buf):len(buf)], wr.buf...)
+		wr.err = err
+
+		return err
+	}
+
+	return nil
+}
+
+// stateErr returns the first error returned by w so far, or ErrClosed if the
+// Writer has been closed. Must be called while holding the lock.
+func (wr *Writer) stateErr() error {
+	if wr.err == nil && wr.closed {
+		return ErrClosed
+	}
+
+	return wr.err
+}
@@ -0,0 +1,346 @@
+package debounce
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordWriter records each write made to it, after an optional delay.
+type recordWriter struct {
+	mux    sync.Mutex
+	writes []string
+	err    error
+	delay  time.Duration
+}
+
+func (w *recordWriter) Write(p []byte) (int, error) {
+	time.Sleep(w.delay)
+
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	if w.err != nil {
+		return 0, w.err
+	}
+	w.writes = append(w.writes, string(p))
+
+	return len(p), nil
+}
+
+func (w *recordWriter) Writes() []string {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	return append([]string(nil), w.writes...)
+}
+
+func TestWriter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("interleaved writes", func(t *testing.T) {
+		t.Parallel()
+
+		rw := &recordWriter{}
+		wr := NewWriter(rw, 20*time.Millisecond)
+
+		wg := sync.WaitGroup{}
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				_, err := fmt.Fprintf(wr, "%d\n", i)
+				assert.NoError(t, err)
+			}(i)
+		}
+		wg.Wait()
+
+		assert.Empty(t, rw.Writes())
+		time.Sleep(40 * time.Millisecond)
+
+		writes := rw.Writes()
+		require.Len(t, writes, 1)
+		assert.Len(t, bytes.Split([]byte(writes[0]), []byte("\n")), 11)
+
+		_, err := wr.Write([]byte("more"))
+		require.NoError(t, err)
+		time.Sleep(40 * time.Millisecond)
+		assert.Equal(t, "more", rw.Writes()[1])
+	})
+
+	t.Run("max wait", func(t *testing.T) {
+		t.Parallel()
+
+		rw := &recordWriter{}
+		wr := NewWriter(rw, 20*time.Millisecond,
+			WithMaxWait(50*time.Millisecond),
+		)
+
+		for i := 0; i < 7; i++ {
+			_, err := wr.Write([]byte("a"))
+			require.NoError(t, err)
+			time.Sleep(12 * time.Millisecond)
+		}
+
+		// Writes kept coming, so only the maximum wait time has passed.
+		assert.Equal(t, []string{"aaaaa"}, rw.Writes())
+
+		require.NoError(t, wr.Flush())
+		assert.Equal(t, []string{"aaaaa", "aa"}, rw.Writes())
+	})
+
+	t.Run("close with pending data", func(t *testing.T) {
+		t.Parallel()
+
+		rw := &recordWriter{}
+		wr := NewWriter(rw, time.Minute)
+
+		_, err := wr.Write([]byte("pending"))
+		require.NoError(t, err)
+		require.NoError(t, wr.Close())
+		assert.Equal(t, []string{"pending"}, rw.Writes())
+
+		_, err = wr.Write([]byte("late"))
+		assert.ErrorIs(t, err, ErrClosed)
+		assert.ErrorIs(t, wr.Flush(), ErrClosed)
+		assert.NoError(t, wr.Close())
+		assert.Equal(t, []string{"pending"}, rw.Writes())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+
+		errWrite := errors.New("write failed")
+		rw := &recordWriter{err: errWrite}
+		wr := NewWriter(rw, 10*time.Millisecond)
+
+		_, err := wr.Write([]byte("a"))
+		require.NoError(t, err)
+		time.Sleep(30 * time.Millisecond)
+
+		_, err = wr.Write([]byte("b"))
+		assert.ErrorIs(t, err, errWrite)
+		assert.ErrorIs(t, wr.Flush(), errWrite)
+		assert.ErrorIs(t, wr.Close(), errWrite)
+		assert.Equal(t, 1, wr.Buffered())
+	})
+
+	t.Run("error keeps unwritten data", func(t *testing.T) {
+		t.Parallel()
+
+		errWrite := errors.New("write failed")
+		pw := &partialWriter{n: 2, err: errWrite}
+		wr := NewWriter(pw, time.Minute)
+
+		_, err := wr.Write([]byte("abcd"))
+		require.NoError(t, err)
+		assert.ErrorIs(t, wr.Flush(), errWrite)
+		assert.Equal(t, 2, wr.Buffered())
+
+		_, err = wr.Write([]byte("e"))
+		assert.ErrorIs(t, err, errWrite)
+		assert.ErrorIs(t, wr.Flush(), errWrite)
+		assert.ErrorIs(t, wr.Close(), errWrite)
+		assert.Equal(t, 2, wr.Buffered())
+		assert.Equal(t, 1, pw.calls)
+	})
+}
+
+// partialWriter reports writing n bytes of each write made to it, and returns
+// err.
+type partialWriter struct {
+	n     int
+	err   error
+	calls int
+}
+
+func (w *partialWriter) Write(p []byte) (int, error) {
+	w.calls++
+
+	return w.n, w.err
+}
+
+// syncWriter is a recordWriter which records syncs as writes of "sync".
+type syncWriter struct {
+	recordWriter
+	syncErr error
+}
+
+func (w *syncWriter) Sync() error {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	w.writes = append(w.writes, "sync")
+
+	return w.syncErr
+}
+
+func TestWriter_Sync(t *testing.T) {
+	t.Parallel()
+
+	t.Run("syncs after flushing", func(t *testing.T) {
+		t.Parallel()
+
+		sw := &syncWriter{}
+		wr := NewWriter(sw, time.Minute)
+
+		_, err := wr.Write([]byte("a"))
+		require.NoError(t, err)
+		require.NoError(t, wr.Sync())
+		assert.Equal(t, []string{"a", "sync"}, sw.Writes())
+
+		require.NoError(t, wr.Sync())
+		assert.Equal(t, []string{"a", "sync", "sync"}, sw.Writes())
+	})
+
+	t.Run("waits for in-flight write", func(t *testing.T) {
+		t.Parallel()
+
+		rw := &recordWriter{delay: 30 * time.Millisecond}
+		wr := NewWriter(rw, 5*time.Millisecond)
+
+		_, err := wr.Write([]byte("a"))
+		require.NoError(t, err)
+		time.Sleep(15 * time.Millisecond)
+
+		// The write of "a" is in progress.
+		_, err = wr.Write([]byte("b"))
+		require.NoError(t, err)
+		require.NoError(t, wr.Sync())
+		assert.Equal(t, []string{"a", "b"}, rw.Writes())
+	})
+
+	t.Run("without Sync method", func(t *testing.T) {
+		t.Parallel()
+
+		rw := &recordWriter{}
+		wr := NewWriter(rw, time.Minute)
+
+		_, err := wr.Write([]byte("a"))
+		require.NoError(t, err)
+		require.NoError(t, wr.Sync())
+		assert.Equal(t, []string{"a"}, rw.Writes())
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+
+		errSync := errors.New("sync failed")
+		sw := &syncWriter{syncErr: errSync}
+		wr := NewWriter(sw, time.Minute)
+
+		assert.ErrorIs(t, wr.Sync(), errSync)
+
+		errWrite := errors.New("write failed")
+		sw.mux.Lock()
+		sw.err = errWrite
+		sw.mux.Unlock()
+
+		_, err := wr.Write([]byte("a"))
+		require.NoError(t, err)
+		assert.ErrorIs(t, wr.Sync(), errWrite)
+		assert.Equal(t, []string{"sync"}, sw.Writes())
+
+		require.ErrorIs(t, wr.Close(), errWrite)
+		assert.ErrorIs(t, wr.Sync(), errWrite)
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		t.Parallel()
+
+		sw := &syncWriter{}
+		wr := NewWriter(sw, time.Minute)
+
+		require.NoError(t, wr.Close())
+		assert.ErrorIs(t, wr.Sync(), ErrClosed)
+		assert.Empty(t, sw.Writes())
+	})
+}
+
+func TestWriter_Close(t *testing.T) {
+	t.Parallel()
+
+	t.Run("waits for in-flight write", func(t *testing.T) {
+		t.Parallel()
+
+		rw := &recordWriter{delay: 30 * time.Millisecond}
+		wr := NewWriter(rw, 5*time.Millisecond)
+
+		_, err := wr.Write([]byte("a"))
+		require.NoError(t, err)
+		time.Sleep(15 * time.Millisecond)
+
+		// The write of "a" is in progress.
+		_, err = wr.Write([]byte("b"))
+		require.NoError(t, err)
+		require.NoError(t, wr.Close())
+		assert.Equal(t, []string{"a", "b"}, rw.Writes())
+
+		// Timers are stopped.
+		time.Sleep(20 * time.Millisecond)
+		assert.Equal(t, []string{"a", "b"}, rw.Writes())
+	})
+
+	t.Run("concurrent writes", func(t *testing.T) {
+		t.Parallel()
+
+		rw := &recordWriter{}
+		wr := NewWriter(rw, time.Millisecond)
+
+		mux := sync.Mutex{}
+		written := 0
+		wg := sync.WaitGroup{}
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					_, err := wr.Write([]byte("x"))
+					if err != nil {
+						assert.ErrorIs(t, err, ErrClosed)
+
+						return
+					}
+					mux.Lock()
+					written++
+					mux.Unlock()
+				}
+			}()
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		require.NoError(t, wr.Close())
+		wg.Wait()
+
+		// Every write which succeeded has been written.
+		total := 0
+		for _, w := range rw.Writes() {
+			total += len(w)
+		}
+		assert.Equal(t, written, total)
+		assert.Positive(t, total)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+
+		errWrite := errors.New("write failed")
+		rw := &recordWriter{err: errWrite}
+		wr := NewWriter(rw, time.Minute)
+
+		_, err := wr.Write([]byte("a"))
+		require.NoError(t, err)
+		assert.ErrorIs(t, wr.Close(), errWrite)
+		assert.ErrorIs(t, wr.Close(), errWrite)
+		assert.Equal(t, 1, wr.Buffered())
+
+		_, err = wr.Write([]byte("b"))
+		assert.ErrorIs(t, err, errWrite)
+	})
+}